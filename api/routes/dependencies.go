@@ -1,34 +1,191 @@
 package router
 
 import (
+	"context"
+
+	"github.com/aragornz325/piloto-api/internal/address/handler"
+	"github.com/aragornz325/piloto-api/internal/address/service"
+	"github.com/aragornz325/piloto-api/internal/availability/handler"
+	"github.com/aragornz325/piloto-api/internal/availability/service"
+	"github.com/aragornz325/piloto-api/internal/compliance/handler"
+	"github.com/aragornz325/piloto-api/internal/compliance/service"
+	"github.com/aragornz325/piloto-api/internal/driver/handler"
+	"github.com/aragornz325/piloto-api/internal/driver/service"
 	"github.com/aragornz325/piloto-api/internal/profile/handler"
+	"github.com/aragornz325/piloto-api/internal/profile/model"
 	"github.com/aragornz325/piloto-api/internal/profile/service"
+	"github.com/aragornz325/piloto-api/internal/rating/handler"
+	"github.com/aragornz325/piloto-api/internal/rating/service"
 	"github.com/aragornz325/piloto-api/internal/user/handler"
 	"github.com/aragornz325/piloto-api/internal/user/service"
+	"github.com/aragornz325/piloto-api/internal/vehicle/handler"
+	"github.com/aragornz325/piloto-api/internal/vehicle/service"
+	"github.com/aragornz325/piloto-api/pkg/config"
+	db "github.com/aragornz325/piloto-api/pkg/database"
 	"github.com/aragornz325/piloto-api/internal/auth/handler"
+	"github.com/aragornz325/piloto-api/internal/auth/middleware"
 	"github.com/aragornz325/piloto-api/internal/auth/service"
+	"github.com/aragornz325/piloto-api/internal/health/handler"
+	"github.com/aragornz325/piloto-api/internal/history/handler"
+	"github.com/aragornz325/piloto-api/internal/history/service"
+	"github.com/aragornz325/piloto-api/internal/invitation/handler"
+	"github.com/aragornz325/piloto-api/internal/invitation/service"
+	"github.com/aragornz325/piloto-api/internal/location/handler"
+	"github.com/aragornz325/piloto-api/internal/location/service"
+	"github.com/aragornz325/piloto-api/internal/trip/handler"
+	"github.com/aragornz325/piloto-api/internal/trip/service"
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/health"
+	"github.com/aragornz325/piloto-api/pkg/lifecycle"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/mailer"
+	"github.com/aragornz325/piloto-api/pkg/notifier"
+	"github.com/aragornz325/piloto-api/pkg/sms"
+	"github.com/aragornz325/piloto-api/pkg/storage"
+	"go.uber.org/zap"
 )
 
 type AppDependencies struct {
 	UserHandler *userHandler.UserHandler
 	ProfileHandler *profileHandler.ProfileHandler
 	AuthHandler *authHandler.AuthHandler
+	AuthMiddleware *authMiddleware.AuthMiddleware
+	HistoryHandler *historyHandler.HistoryHandler
+	InvitationHandler *invitationHandler.InvitationHandler
+	AddressHandler *addressHandler.AddressHandler
+	DriverHandler *driverHandler.DriverHandler
+	VehicleHandler *vehicleHandler.VehicleHandler
+	AvailabilityHandler *availabilityHandler.AvailabilityHandler
+	LocationHandler *locationHandler.LocationHandler
+	TripHandler *tripHandler.TripHandler
+	RatingHandler *ratingHandler.RatingHandler
+	ComplianceHandler *complianceHandler.ComplianceHandler
+	HealthHandler *healthHandler.HealthHandler
+	Health *health.Registry
+	Storage storage.BlobStorage
 }
 
-func BuildDependencies() *AppDependencies {
+// BuildDependencies arma los servicios y handlers. Los procesos de fondo se inician con lc
+// para detenerlos al apagar el servidor.
+func BuildDependencies(cfg *config.Config, lc *lifecycle.Lifecycle) *AppDependencies {
+	mailer := mailer.NewLogMailer()
+	smsSender := sms.NewLogSender()
+	notifier := notifier.NewMailNotifier(mailer)
+	blobStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		logger.Log.Fatal("💥 Error al configurar el storage", zap.Error(err))
+	}
+	// Los documentos de los conductores van a un storage que no se sirve públicamente
+	documentStorage, err := storage.NewPrivate(cfg.Storage)
+	if err != nil {
+		logger.Log.Fatal("💥 Error al configurar el storage privado", zap.Error(err))
+	}
+	geocoder, err := geo.NewLocalGeocoder()
+	if err != nil {
+		logger.Log.Fatal("💥 Error al cargar el geocoder", zap.Error(err))
+	}
+	completenessWeights, err := profileModel.ParseCompletenessWeights(cfg.ProfileCompletenessWeights)
+	if err != nil {
+		logger.Log.Fatal("💥 Error en los pesos de completitud del perfil", zap.Error(err))
+	}
 	// User
-	userService := service.NewUserService()
-	userHandler := userHandler.NewUserHandler(userService)
+	userService := service.NewUserService(completenessWeights)
+	emailChangeService := service.NewEmailChangeService(userService, mailer, cfg.BaseURL)
+	userHandler := userHandler.NewUserHandler(userService, emailChangeService)
 	// Profile
-	profileService := profileService.NewProfileService()
+	profileService := profileService.NewProfileService(blobStorage, smsSender, completenessWeights)
 	profileHandler := profileHandler.NewProfileHandler(profileService)
 	//auth
-	authService := authService.NewAuthService(userService)
+	jwtService := authService.NewJwtService(userService, []byte(cfg.JWT.Secret.Reveal()))
+	authService := authService.NewAuthService(userService, jwtService)
 	authHandler := authHandler.NewAuthHandler(authService)
+	authMiddleware := authMiddleware.NewAuthMiddleware(jwtService)
+	// History
+	historyService := historyService.NewHistoryService()
+	historyHandler := historyHandler.NewHistoryHandler(historyService, profileService)
+	// Invitations
+	invitationService := invitationService.NewInvitationService(userService, mailer, cfg.BaseURL)
+	invitationHandler := invitationHandler.NewInvitationHandler(invitationService)
+	// Addresses
+	addressService := addressService.NewAddressService(geocoder)
+	addressHandler := addressHandler.NewAddressHandler(addressService)
+	// Drivers
+	driverService := driverService.NewDriverService(documentStorage)
+	driverHandler := driverHandler.NewDriverHandler(driverService)
+	lc.Go("driver documents", func(ctx context.Context) {
+		moveLegacyDocuments(ctx, driverService, blobStorage)
+	})
+	// Vehicles
+	vehicleService := vehicleService.NewVehicleService()
+	vehicleHandler := vehicleHandler.NewVehicleHandler(vehicleService)
+	// Availability
+	availabilityService := availabilityService.NewAvailabilityService()
+	availabilityHandler := availabilityHandler.NewAvailabilityHandler(availabilityService)
+	// Location
+	locationService := locationService.NewLocationService(availabilityService)
+	locationHandler := locationHandler.NewLocationHandler(locationService)
+	lc.Go("location", locationService.Run)
+	// Trips
+	tripService := tripService.NewTripService(locationService)
+	tripHandler := tripHandler.NewTripHandler(tripService)
+	lc.Go("trips", tripService.Run)
+	// Ratings
+	ratingService := ratingService.NewRatingService()
+	ratingHandler := ratingHandler.NewRatingHandler(ratingService)
+	// Compliance
+	complianceService := complianceService.NewComplianceService(notifier)
+	complianceHandler := complianceHandler.NewComplianceHandler(complianceService)
+	lc.Go("compliance", complianceService.Run)
+
+	// Health
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register("database", health.CheckFunc(db.Ping))
+	healthRegistry.Register("migrations", health.CheckFunc(db.CheckMigrations))
+	// El mailer y el storage se chequean solo si saben hacerlo
+	if checker, ok := mailer.(health.Checker); ok {
+		healthRegistry.Register("mailer", checker)
+	}
+	if checker, ok := blobStorage.(health.Checker); ok {
+		healthRegistry.Register("storage", checker)
+	}
+	if checker, ok := documentStorage.(health.Checker); ok {
+		healthRegistry.Register("private_storage", checker)
+	}
+	healthHandler := healthHandler.NewHealthHandler(healthRegistry)
 
 	return &AppDependencies{
 		UserHandler: userHandler,
 		ProfileHandler: profileHandler,
 		AuthHandler: authHandler,
+		AuthMiddleware: authMiddleware,
+		HistoryHandler: historyHandler,
+		InvitationHandler: invitationHandler,
+		AddressHandler: addressHandler,
+		DriverHandler: driverHandler,
+		VehicleHandler: vehicleHandler,
+		AvailabilityHandler: availabilityHandler,
+		LocationHandler: locationHandler,
+		TripHandler: tripHandler,
+		RatingHandler: ratingHandler,
+		ComplianceHandler: complianceHandler,
+		HealthHandler: healthHandler,
+		Health: healthRegistry,
+		Storage: blobStorage,
+	}
+}
+
+// moveLegacyDocuments pasa al storage privado los documentos que se subieron al público
+// antes de que existiera.
+func moveLegacyDocuments(ctx context.Context, service driverService.DriverService, from storage.BlobStorage) {
+	moved, err := service.MoveLegacyDocuments(driverService.MoveLegacyDocumentsFuncParams{
+		Ctx:  ctx,
+		From: from,
+	})
+	if err != nil {
+		logger.Log.Error("💥 Error al mover los documentos de conductores al storage privado", zap.Error(err))
+		return
+	}
+	if moved > 0 {
+		logger.Log.Info("📦 Documentos de conductores movidos al storage privado", zap.Int("documents", moved))
 	}
 }
@@ -1,37 +1,147 @@
 package router
 
 import (
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/pkg/middleware"
+	"github.com/aragornz325/piloto-api/pkg/storage"
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRoutes(deps *AppDependencies) *gin.Engine {
 	r := gin.Default()
+	r.Use(middleware.RequestID(), deps.AuthMiddleware.Authenticate())
+
+	// Archivos subidos (avatares) cuando el storage es el filesystem local
+	if static, ok := deps.Storage.(storage.StaticServer); ok {
+		r.Static(static.RoutePrefix(), static.Dir())
+	}
 
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
 	})
+	r.GET("/healthz", deps.HealthHandler.LivenessHandler)
+	r.GET("/readyz", deps.HealthHandler.ReadinessHandler)
 
 	v1 := r.Group("/api/v1")
 	user := v1.Group("/users")
 	profile := v1.Group("/profile")
 	auth := v1.Group("/auth")
+	invitations := v1.Group("/invitations")
+	me := v1.Group("/me", deps.AuthMiddleware.RequireAuth())
+	drivers := v1.Group("/drivers")
+	vehicles := v1.Group("/vehicles")
+	trips := v1.Group("/trips", deps.AuthMiddleware.RequireAuth())
+	ratings := v1.Group("/ratings", deps.AuthMiddleware.RequireAuth())
+	admin := deps.AuthMiddleware.RequireRole(userModel.RoleAdmin)
 	{
 		user.GET("/", deps.UserHandler.GetAllUsersHandler)
-		user.POST("/", deps.UserHandler.CreateUserHandler)
+		// El alta pública es /auth/register o una invitación; acá solo crea usuarios un admin
+		user.POST("/", admin, deps.UserHandler.CreateUserHandler)
 		user.GET("/:id", deps.UserHandler.GetUserByIdHandler)
-		user.PUT("/:id", deps.UserHandler.UpdateUserHandler)
-		user.DELETE("/:id", deps.UserHandler.SoftDeleteUserHandler)
+		user.PUT("/:id", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.UserHandler.UpdateUserHandler)
+		user.DELETE("/:id", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.UserHandler.SoftDeleteUserHandler)
+		user.GET("/:id/history", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.HistoryHandler.GetUserHistoryHandler)
+		user.POST("/:id/email-change", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.UserHandler.RequestEmailChangeHandler)
+		// El link del email apunta al GET; la app puede mandar el token por POST
+		user.GET("/email-change/confirm", deps.UserHandler.ConfirmEmailChangeLinkHandler)
+		user.POST("/email-change/confirm", deps.UserHandler.ConfirmEmailChangeHandler)
+		user.POST("/:id/suspend", admin, deps.UserHandler.SuspendUserHandler)
+		user.POST("/:id/reinstate", admin, deps.UserHandler.ReinstateUserHandler)
+		user.POST("/:id/ban", admin, deps.UserHandler.BanUserHandler)
+		user.POST("/:id/deactivate", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.UserHandler.DeactivateUserHandler)
+		user.GET("/:id/status-transitions", admin, deps.UserHandler.ListUserStatusTransitionsHandler)
+		selfOrAdmin := deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin)
+		user.GET("/:id/addresses", selfOrAdmin, deps.AddressHandler.ListAddressesHandler)
+		user.POST("/:id/addresses", selfOrAdmin, deps.AddressHandler.CreateAddressHandler)
+		user.GET("/:id/addresses/:addressId", selfOrAdmin, deps.AddressHandler.GetAddressHandler)
+		user.PUT("/:id/addresses/:addressId", selfOrAdmin, deps.AddressHandler.UpdateAddressHandler)
+		user.DELETE("/:id/addresses/:addressId", selfOrAdmin, deps.AddressHandler.DeleteAddressHandler)
+		user.POST("/:id/driver", selfOrAdmin, deps.DriverHandler.RegisterDriverHandler)
+		user.GET("/:id/driver", selfOrAdmin, deps.DriverHandler.GetDriverHandler)
+		user.PUT("/:id/driver/license", selfOrAdmin, deps.DriverHandler.UpdateLicenseHandler)
+		user.POST("/:id/driver/documents", selfOrAdmin, deps.DriverHandler.SubmitDocumentHandler)
+		user.GET("/:id/vehicles", selfOrAdmin, deps.VehicleHandler.ListVehiclesHandler)
+		user.POST("/:id/vehicles", selfOrAdmin, deps.VehicleHandler.CreateVehicleHandler)
+		user.GET("/:id/vehicles/:vehicleId", selfOrAdmin, deps.VehicleHandler.GetVehicleHandler)
+		user.PUT("/:id/vehicles/:vehicleId", selfOrAdmin, deps.VehicleHandler.UpdateVehicleHandler)
+		user.DELETE("/:id/vehicles/:vehicleId", selfOrAdmin, deps.VehicleHandler.DeleteVehicleHandler)
+		user.POST("/:id/vehicles/:vehicleId/select", selfOrAdmin, deps.VehicleHandler.SelectVehicleHandler)
+		user.GET("/:id/availability", selfOrAdmin, deps.AvailabilityHandler.GetAvailabilityHandler)
+		user.PUT("/:id/availability/schedule", selfOrAdmin, deps.AvailabilityHandler.SetScheduleHandler)
+		user.PUT("/:id/availability/exceptions/:date", selfOrAdmin, deps.AvailabilityHandler.SetExceptionHandler)
+		user.DELETE("/:id/availability/exceptions/:date", selfOrAdmin, deps.AvailabilityHandler.DeleteExceptionHandler)
+		user.PUT("/:id/duty", selfOrAdmin, deps.AvailabilityHandler.SetDutyHandler)
+		user.GET("/:id/ratings", deps.AuthMiddleware.RequireAuth(), deps.RatingHandler.ListUserRatingsHandler)
 	}
 	{
-		profile.POST("/", deps.ProfileHandler.CreateProfileHandler)
+		// El dueño del perfil es el user_id del body: el handler verifica que sea el usuario o un admin
+		profile.POST("/", deps.AuthMiddleware.RequireAuth(), deps.ProfileHandler.CreateProfileHandler)
+		profile.GET("/nearby", deps.AuthMiddleware.RequireAuth(), deps.ProfileHandler.NearbyProfilesHandler)
+		profile.GET("/handle/:handle", deps.ProfileHandler.GetProfileByHandleHandler)
 		profile.GET("/:id", deps.ProfileHandler.GetProfileByIdHandler)
-		profile.PUT("/:id", deps.ProfileHandler.UpdateProfileHandler)
-		profile.DELETE("/:id", deps.ProfileHandler.SoftDeleteProfileHandler)
+		profile.PUT("/:id", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.ProfileHandler.UpdateProfileHandler)
+		profile.DELETE("/:id", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.ProfileHandler.SoftDeleteProfileHandler)
+		profile.GET("/:id/public", deps.ProfileHandler.GetPublicProfileHandler)
+		profile.PUT("/:id/privacy", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.ProfileHandler.UpdatePrivacyHandler)
+		profile.PUT("/:id/handle", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.ProfileHandler.ClaimHandleHandler)
+		profile.GET("/:id/history", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.HistoryHandler.GetProfileHistoryHandler)
+		profile.POST("/:id/avatar", deps.AuthMiddleware.RequireSelfOrRole("id", userModel.RoleAdmin), deps.ProfileHandler.UploadAvatarHandler)
+		profile.POST("/:id/phone/verification", deps.AuthMiddleware.RequireSelfOrRole("id"), deps.ProfileHandler.RequestPhoneVerificationHandler)
+		profile.POST("/:id/phone/verification/confirm", deps.AuthMiddleware.RequireSelfOrRole("id"), deps.ProfileHandler.ConfirmPhoneVerificationHandler)
+	}
+	{
+		drivers.GET("/", admin, deps.DriverHandler.ListDriversHandler)
+		drivers.GET("/documents", admin, deps.DriverHandler.ListDocumentsHandler)
+		// El conductor descarga sus documentos y un admin cualquiera; el servicio filtra por dueño
+		drivers.GET("/documents/:documentId/file", deps.AuthMiddleware.RequireAuth(), deps.DriverHandler.DownloadDocumentHandler)
+		drivers.GET("/available", admin, deps.AvailabilityHandler.ListAvailableDriversHandler)
+		drivers.GET("/nearest", deps.AuthMiddleware.RequireAuth(), deps.LocationHandler.NearestDriversHandler)
+		drivers.POST("/compliance/run", admin, deps.ComplianceHandler.RunChecksHandler)
+		drivers.POST("/documents/:documentId/approve", admin, deps.DriverHandler.ApproveDocumentHandler)
+		drivers.POST("/documents/:documentId/reject", admin, deps.DriverHandler.RejectDocumentHandler)
+	}
+	{
+		vehicles.GET("/", admin, deps.VehicleHandler.ListVehiclesForReviewHandler)
+		vehicles.POST("/:vehicleId/verify", admin, deps.VehicleHandler.VerifyVehicleHandler)
+		vehicles.POST("/:vehicleId/reject", admin, deps.VehicleHandler.RejectVehicleHandler)
+	}
+	{
+		me.GET("/profile/completeness", deps.ProfileHandler.GetMyCompletenessHandler)
+		me.GET("/location/stream", deps.LocationHandler.StreamLocationHandler)
+		me.GET("/trip-offers", deps.TripHandler.ListMyOffersHandler)
+	}
+	{
+		trips.POST("/", deps.TripHandler.RequestTripHandler)
+		trips.GET("/", deps.TripHandler.ListTripsHandler)
+		trips.GET("/:tripId", deps.TripHandler.GetTripHandler)
+		trips.GET("/:tripId/transitions", deps.TripHandler.ListTransitionsHandler)
+		trips.POST("/:tripId/dispatch", deps.TripHandler.DispatchTripHandler)
+		trips.POST("/:tripId/accept", deps.TripHandler.AcceptTripHandler)
+		trips.POST("/:tripId/decline", deps.TripHandler.DeclineTripHandler)
+		trips.POST("/:tripId/en-route", deps.TripHandler.EnRouteTripHandler)
+		trips.POST("/:tripId/start", deps.TripHandler.StartTripHandler)
+		trips.POST("/:tripId/complete", deps.TripHandler.CompleteTripHandler)
+		trips.POST("/:tripId/cancel", deps.TripHandler.CancelTripHandler)
+		trips.POST("/:tripId/ratings", deps.RatingHandler.RateTripHandler)
+		trips.GET("/:tripId/ratings", deps.RatingHandler.ListTripRatingsHandler)
+	}
+	{
+		ratings.PUT("/:ratingId", deps.RatingHandler.UpdateRatingHandler)
+		ratings.POST("/:ratingId/hide", admin, deps.RatingHandler.HideRatingHandler)
+		ratings.POST("/:ratingId/unhide", admin, deps.RatingHandler.UnhideRatingHandler)
 	}
 	{
 		auth.POST("/register", deps.AuthHandler.RegisterUser)
 		auth.POST("/login", deps.AuthHandler.LoginUser)
 	}
+	{
+		invitations.POST("/", admin, deps.InvitationHandler.CreateInvitationHandler)
+		invitations.GET("/", admin, deps.InvitationHandler.ListInvitationsHandler)
+		invitations.POST("/:id/resend", admin, deps.InvitationHandler.ResendInvitationHandler)
+		invitations.POST("/:id/revoke", admin, deps.InvitationHandler.RevokeInvitationHandler)
+		invitations.GET("/accept", deps.InvitationHandler.GetInvitationByTokenHandler)
+		invitations.POST("/accept", deps.InvitationHandler.AcceptInvitationHandler)
+	}
 
 	return r
 }
@@ -1,16 +1,29 @@
 package main
 
 import (
+	"context"
+	stderrors "errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// Base de zonas horarias embebida: las imágenes mínimas no traen /usr/share/zoneinfo
+	_ "time/tzdata"
 
 	routes "github.com/aragornz325/piloto-api/api/routes"
 	_ "github.com/aragornz325/piloto-api/docs"
+	"github.com/aragornz325/piloto-api/pkg/config"
 	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/health"
+	"github.com/aragornz325/piloto-api/pkg/lifecycle"
 	"github.com/aragornz325/piloto-api/pkg/logger"
-	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
 )
 
 //	@title			Piloto de Tormenta API
@@ -34,24 +47,92 @@ import (
 //	@externalDocs.url			https://swagger.io/resources/open-api/
 func main() {
 
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "dev"
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		if stderrors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		log.Fatal("💥 Error en la configuración: ", err)
 	}
-	envFile := "." +env + ".env"
-	log.Println("Cargando archivo de entorno: ", envFile)
-	if err := godotenv.Load(envFile); err != nil {
-		log.Fatal("💥 Error cargando archivo .env")
+	logger.Init(cfg.Env)
+	// Los secretos se loguean como [REDACTED]
+	logger.Log.Info("⚙️ Configuración cargada", zap.Any("config", cfg))
+
+	// Los pasos de cierre corren al revés: se cierra la DB y por último se vacía el logger
+	lc := lifecycle.New()
+	lc.OnShutdown("logger", func(context.Context) error { return logger.Sync() })
+	if err := db.Init(cfg.Database); err != nil {
+		logger.Log.Fatal("💥 Error al conectar con la DB", zap.Error(err))
 	}
-	logger.Init()
-	db.Init()
+	lc.OnShutdown("database", func(context.Context) error { return db.Close() })
 	db.ExecuteMigrations()
-	deps := routes.BuildDependencies()
+	deps := routes.BuildDependencies(cfg, lc)
 	r := routes.SetupRoutes(deps)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	if err := r.Run(":3800"); err != nil {
-		log.Fatal("💥 Error al iniciar el servidor: ", err)
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	// Shutdown no cierra las conexiones WebSocket: se cierran aparte
+	server.RegisterOnShutdown(deps.LocationHandler.CloseStreams)
+
+	os.Exit(serve(server, lc, deps.Health, cfg.Server))
+}
+
+// serve atiende requests hasta recibir SIGINT o SIGTERM (o hasta que el servidor falle) y
+// después apaga todo en orden: pone /readyz en 503 y sigue atendiendo durante DrainDelay
+// para que el balanceador saque la instancia, deja de aceptar conexiones y espera a los
+// requests en curso, detiene los procesos de fondo y ejecuta los pasos de cierre. Devuelve
+// el código de salida.
+func serve(server *http.Server, lc *lifecycle.Lifecycle, registry *health.Registry, cfg config.ServerConfig) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	code := 0
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Log.Info("🚀 Servidor escuchando", zap.String("addr", server.Addr))
+		if err := server.ListenAndServe(); err != nil && !stderrors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+	select {
+	case err := <-serverErr:
+		logger.Log.Error("💥 Error al iniciar el servidor", zap.Error(err))
+		code = 1
+	case <-ctx.Done():
+		logger.Log.Info("🛑 Apagando el servidor...")
+	}
+	// Una segunda señal corta el proceso sin esperar
+	stop()
+
+	registry.Drain()
+	if code == 0 && cfg.DrainDelay > 0 {
+		logger.Log.Info("⏳ Esperando a que el balanceador saque la instancia", zap.Duration("drain_delay", cfg.DrainDelay))
+		time.Sleep(cfg.DrainDelay)
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(drainCtx); err != nil {
+		logger.Log.Error("💥 No terminaron todos los requests en curso", zap.Error(err))
+		code = 1
+	}
+
+	// Plazo propio: si drenar los requests agotó el anterior, igual se guardan las posiciones
+	closeCtx, cancelClose := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelClose()
+	logger.Log.Info("✅ Servidor detenido")
+	if err := lc.Shutdown(closeCtx); err != nil {
+		// El logger ya se vació como último paso
+		log.Println("💥 Error al apagar: ", err)
+		code = 1
 	}
+	return code
 }
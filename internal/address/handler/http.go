@@ -0,0 +1,245 @@
+package addressHandler
+
+import (
+	"net/http"
+
+	m "github.com/aragornz325/piloto-api/internal/address/model"
+	addressService "github.com/aragornz325/piloto-api/internal/address/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type AddressHandler struct {
+	AddressService addressService.AddressService
+}
+
+func NewAddressHandler(addressService addressService.AddressService) *AddressHandler {
+	return &AddressHandler{
+		AddressService: addressService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary List addresses
+// @Description List the addresses of a user, the default one first
+// @Tags addresses
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} addressModel.Address
+// @Failure 400 {object} ErrorResponse
+// @Router /users/{id}/addresses [get]
+func (h *AddressHandler) ListAddressesHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	addresses, err := h.AddressService.ListAddresses(addressService.ListAddressesFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// @Summary Get address
+// @Description Get one address of a user
+// @Tags addresses
+// @Produce json
+// @Param id path string true "User ID"
+// @Param addressId path string true "Address ID"
+// @Success 200 {object} addressModel.Address
+// @Header 200 {string} ETag "Current version of the address"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/addresses/{addressId} [get]
+func (h *AddressHandler) GetAddressHandler(c *gin.Context) {
+	userId, addressId, ok := parseAddressPath(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.AddressService.GetAddress(addressService.GetAddressFuncParams{
+		Ctx:       c.Request.Context(),
+		UserId:    userId,
+		AddressId: addressId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, address.Version)
+	c.JSON(http.StatusOK, address)
+}
+
+// @Summary Create address
+// @Description Add a labeled address (home, work, billing or custom) to a user. The address is normalized and geocoded. The first address is the default one; sending is_default moves the default to the new address.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body addressModel.CreateAddressDTO true "Address data"
+// @Success 201 {object} addressModel.Address
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/addresses [post]
+func (h *AddressHandler) CreateAddressHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.CreateAddressDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	address := m.Address{
+		Type:    *payload.Type,
+		City:    *payload.City,
+		Country: *payload.Country,
+	}
+	optional := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	address.Label = optional(payload.Label)
+	address.Street = optional(payload.Street)
+	address.State = optional(payload.State)
+	address.ZipCode = optional(payload.ZipCode)
+	if payload.IsDefault != nil {
+		address.IsDefault = *payload.IsDefault
+	}
+
+	result, err := h.AddressService.CreateAddress(addressService.CreateAddressFuncParams{
+		Ctx:     c.Request.Context(),
+		UserId:  userId,
+		Address: &address,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Summary Update address
+// @Description Update the fields sent of an address. Address changes are normalized and geocoded again. Sending is_default=true makes it the default address.
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param addressId path string true "Address ID"
+// @Param If-Match header string true "ETag of the address being updated"
+// @Param input body addressModel.UpdateAddressDTO true "Fields to update"
+// @Success 200 {object} addressModel.Address
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Router /users/{id}/addresses/{addressId} [put]
+func (h *AddressHandler) UpdateAddressHandler(c *gin.Context) {
+	userId, addressId, ok := parseAddressPath(c)
+	if !ok {
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var payload m.UpdateAddressDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.AddressService.UpdateAddress(addressService.UpdateAddressFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		AddressId:       addressId,
+		Changes:         payload,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Delete address
+// @Description Soft delete an address. If it was the default address, the oldest remaining address becomes the default.
+// @Tags addresses
+// @Produce json
+// @Param id path string true "User ID"
+// @Param addressId path string true "Address ID"
+// @Param If-Match header string true "ETag of the address being deleted"
+// @Success 200 {object} addressModel.Address
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Router /users/{id}/addresses/{addressId} [delete]
+func (h *AddressHandler) DeleteAddressHandler(c *gin.Context) {
+	userId, addressId, ok := parseAddressPath(c)
+	if !ok {
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.AddressService.DeleteAddress(addressService.DeleteAddressFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		AddressId:       addressId,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseAddressPath lee los IDs de usuario y dirección de la ruta; responde 400 si no son válidos.
+func parseAddressPath(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	addressId, err := uuid.Parse(c.Param("addressId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid address ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userId, addressId, true
+}
@@ -0,0 +1,73 @@
+package addressModel
+
+import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+const (
+	TypeHome    = "home"
+	TypeWork    = "work"
+	TypeBilling = "billing"
+	TypeCustom  = "custom"
+)
+
+// MaxAddressesPerUser es la cantidad máxima de direcciones que puede guardar un usuario.
+const MaxAddressesPerUser = 10
+
+// Address es una dirección etiquetada de un usuario. Cada usuario tiene a lo sumo una
+// dirección por defecto (índice único parcial sobre las no borradas).
+type Address struct {
+	baseModel.BaseModel
+	UserId uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_addresses_user_default,where:is_default = true AND deleted_at IS NULL" json:"user_id"`
+	// Type es home, work, billing o custom; Label es el nombre que se muestra y es
+	// obligatorio para las direcciones custom.
+	Type    string `gorm:"not null;default:home" json:"type"`
+	Label   string `json:"label"`
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	ZipCode string `json:"zip_code"`
+	// Country es un código ISO 3166-1 alpha-2.
+	Country string `json:"country"`
+	// Latitude/Longitude se resuelven con el Geocoder; nil si no se pudo ubicar.
+	Latitude   *float64   `json:"latitude"`
+	Longitude  *float64   `json:"longitude"`
+	GeocodedAt *time.Time `json:"geocoded_at"`
+	IsDefault  bool       `gorm:"not null;default:false" json:"is_default"`
+}
+
+// IsType indica si value es un tipo de dirección válido.
+func IsType(value string) bool {
+	switch value {
+	case TypeHome, TypeWork, TypeBilling, TypeCustom:
+		return true
+	}
+	return false
+}
+
+type CreateAddressDTO struct {
+	Type      *string `json:"type" binding:"required,oneof=home work billing custom"`
+	Label     *string `json:"label" binding:"omitempty,max=40"`
+	Street    *string `json:"street" binding:"omitempty"`
+	City      *string `json:"city" binding:"required"`
+	State     *string `json:"state" binding:"omitempty"`
+	ZipCode   *string `json:"zip_code" binding:"omitempty"`
+	Country   *string `json:"country" binding:"required"`
+	IsDefault *bool   `json:"is_default"`
+}
+
+// UpdateAddressDTO actualiza solo los campos enviados. Un string vacío borra los campos
+// opcionales (label, street, state, zip_code).
+type UpdateAddressDTO struct {
+	Type      *string `json:"type" binding:"omitempty,oneof=home work billing custom"`
+	Label     *string `json:"label" binding:"omitempty,max=40"`
+	Street    *string `json:"street"`
+	City      *string `json:"city"`
+	State     *string `json:"state"`
+	ZipCode   *string `json:"zip_code"`
+	Country   *string `json:"country"`
+	IsDefault *bool   `json:"is_default"`
+}
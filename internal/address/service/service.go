@@ -0,0 +1,490 @@
+package addressService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	addressModel "github.com/aragornz325/piloto-api/internal/address/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Package addressService maneja las direcciones etiquetadas de cada usuario.
+
+type AddressService interface {
+	ListAddresses(ListAddressesFuncParams) ([]*addressModel.Address, error)
+	GetAddress(GetAddressFuncParams) (*addressModel.Address, error)
+	CreateAddress(CreateAddressFuncParams) (*addressModel.Address, error)
+	UpdateAddress(UpdateAddressFuncParams) (*addressModel.Address, error)
+	DeleteAddress(DeleteAddressFuncParams) (*addressModel.Address, error)
+}
+
+type addressService struct {
+	Geocoder geo.Geocoder
+}
+
+func NewAddressService(geocoder geo.Geocoder) AddressService {
+	return &addressService{
+		Geocoder: geocoder,
+	}
+}
+
+// ListAddresses returns the addresses of a user, the default one first.
+//
+// Parameters:
+//   - opts: ListAddressesFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - []*addressModel.Address: The user's addresses.
+//   - error: An error if the query fails.
+func (s *addressService) ListAddresses(opts ListAddressesFuncParams) ([]*addressModel.Address, error) {
+	addresses := []*addressModel.Address{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListAddresses",
+		ServiceName: "address",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("user_id = ?", opts.UserId).
+				Order("is_default DESC, created_at ASC").
+				Find(&addresses).Error; err != nil {
+				return fmt.Errorf("error listing addresses: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// GetAddress returns one address of a user.
+//
+// Parameters:
+//   - opts: GetAddressFuncParams containing the context, the user ID and the address ID.
+//
+// Returns:
+//   - *addressModel.Address: The address.
+//   - error: A 404 if the user has no address with that ID.
+func (s *addressService) GetAddress(opts GetAddressFuncParams) (*addressModel.Address, error) {
+	var address addressModel.Address
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetAddress",
+		ServiceName: "address",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("id = ? AND user_id = ?", opts.AddressId, opts.UserId).
+				First(&address).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "address not found",
+					})
+				}
+				return fmt.Errorf("error getting address: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// CreateAddress adds an address to a user. The address is normalized and geocoded before
+// saving. The first address of a user is always the default one; creating an address with
+// IsDefault set moves the default from the previous address.
+//
+// Parameters:
+//   - opts: CreateAddressFuncParams containing the context, the user ID and the address data.
+//
+// Returns:
+//   - *addressModel.Address: The created address.
+//   - error: A 400 with the invalid fields, or 409 if the user reached MaxAddressesPerUser.
+func (s *addressService) CreateAddress(opts CreateAddressFuncParams) (*addressModel.Address, error) {
+	address := opts.Address
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "CreateAddress",
+		ServiceName: "address",
+		Operation: func() error {
+			address.UserId = opts.UserId
+			if err := s.prepareAddress(opts.Ctx, address, nil); err != nil {
+				return err
+			}
+
+			err := db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				var existing []addressModel.Address
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("user_id = ?", opts.UserId).
+					Find(&existing).Error; err != nil {
+					return fmt.Errorf("error getting addresses: %w", err)
+				}
+				if len(existing) >= addressModel.MaxAddressesPerUser {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: fmt.Sprintf("a user can have at most %d addresses", addressModel.MaxAddressesPerUser),
+					})
+				}
+				if len(existing) == 0 {
+					address.IsDefault = true
+				} else if address.IsDefault {
+					if err := clearDefault(tx, opts.UserId); err != nil {
+						return err
+					}
+				}
+				return tx.Create(address).Error
+			})
+			if db.IsUniqueViolation(err) {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "the default address was changed by another request",
+				})
+			}
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// UpdateAddress changes the fields sent in opts.Changes. Address fields are merged with the
+// current ones, normalized and geocoded again when they change. Setting IsDefault moves the
+// default from the previous address; the default address cannot be unset directly, another
+// address has to be made default instead.
+// The write only succeeds if the stored version matches opts.ExpectedVersion.
+//
+// Parameters:
+//   - opts: UpdateAddressFuncParams containing the context, the user and address IDs, the changes and the expected version.
+//
+// Returns:
+//   - *addressModel.Address: The updated address.
+//   - error: A 400 with the invalid fields, 404 if not found or 412 on a version mismatch.
+func (s *addressService) UpdateAddress(opts UpdateAddressFuncParams) (*addressModel.Address, error) {
+	var address *addressModel.Address
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "UpdateAddress",
+		ServiceName: "address",
+		Operation: func() error {
+			current, err := s.GetAddress(GetAddressFuncParams{
+				Ctx:       opts.Ctx,
+				UserId:    opts.UserId,
+				AddressId: opts.AddressId,
+			})
+			if err != nil {
+				return err
+			}
+			if current.Version != opts.ExpectedVersion {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "address was modified by another request",
+				})
+			}
+
+			updated := *current
+			changes := opts.Changes
+			if changes.Type != nil {
+				// La etiqueta por defecto sigue al tipo si no fue personalizada
+				if updated.Label == updated.Type && changes.Label == nil {
+					updated.Label = ""
+				}
+				updated.Type = *changes.Type
+			}
+			assign := func(value *string, field *string) {
+				if value != nil {
+					*field = *value
+				}
+			}
+			assign(changes.Label, &updated.Label)
+			assign(changes.Street, &updated.Street)
+			assign(changes.City, &updated.City)
+			assign(changes.State, &updated.State)
+			assign(changes.ZipCode, &updated.ZipCode)
+			assign(changes.Country, &updated.Country)
+			if changes.IsDefault != nil {
+				if !*changes.IsDefault && current.IsDefault {
+					return errors.NewValidation(errors.ValidationErrorFuncOptions{
+						Message: "invalid address",
+						Fields:  map[string]string{"is_default": "set another address as default instead"},
+					})
+				}
+				updated.IsDefault = *changes.IsDefault
+			}
+
+			if err := s.prepareAddress(opts.Ctx, &updated, current); err != nil {
+				return err
+			}
+
+			now := time.Now().UTC()
+			updated.Version = opts.ExpectedVersion + 1
+			updated.UpdatedAt = now
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if updated.IsDefault && !current.IsDefault {
+					if err := clearDefault(tx, opts.UserId); err != nil {
+						return err
+					}
+				}
+				// Se escribe con un map para poder vaciar campos opcionales
+				result := tx.Model(current).
+					Where("version = ?", opts.ExpectedVersion).
+					Updates(map[string]interface{}{
+						"type":        updated.Type,
+						"label":       updated.Label,
+						"street":      updated.Street,
+						"city":        updated.City,
+						"state":       updated.State,
+						"zip_code":    updated.ZipCode,
+						"country":     updated.Country,
+						"latitude":    updated.Latitude,
+						"longitude":   updated.Longitude,
+						"geocoded_at": updated.GeocodedAt,
+						"is_default":  updated.IsDefault,
+						"version":     updated.Version,
+						"updated_at":  now,
+					})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "address was modified by another request",
+					})
+				}
+				return nil
+			})
+			if db.IsUniqueViolation(err) {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "the default address was changed by another request",
+				})
+			}
+			if err != nil {
+				return err
+			}
+			address = &updated
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress soft deletes an address of a user. If it was the default address, the
+// oldest remaining address becomes the default.
+//
+// Parameters:
+//   - opts: DeleteAddressFuncParams containing the context, the user and address IDs and the expected version.
+//
+// Returns:
+//   - *addressModel.Address: The deleted address.
+//   - error: A 404 if not found or 412 on a version mismatch.
+func (s *addressService) DeleteAddress(opts DeleteAddressFuncParams) (*addressModel.Address, error) {
+	var address *addressModel.Address
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "DeleteAddress",
+		ServiceName: "address",
+		Operation: func() error {
+			current, err := s.GetAddress(GetAddressFuncParams{
+				Ctx:       opts.Ctx,
+				UserId:    opts.UserId,
+				AddressId: opts.AddressId,
+			})
+			if err != nil {
+				return err
+			}
+			if current.Version != opts.ExpectedVersion {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "address was modified by another request",
+				})
+			}
+
+			now := time.Now().UTC()
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(current).
+					Where("version = ?", opts.ExpectedVersion).
+					Updates(map[string]interface{}{
+						"is_active":  false,
+						"is_default": false,
+						"deleted_at": now,
+						"version":    opts.ExpectedVersion + 1,
+						"updated_at": now,
+					})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "address was modified by another request",
+					})
+				}
+				if !current.IsDefault {
+					return nil
+				}
+
+				var next addressModel.Address
+				err := tx.Where("user_id = ?", opts.UserId).Order("created_at ASC").First(&next).Error
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("error getting next default address: %w", err)
+				}
+				return tx.Model(&next).Updates(map[string]interface{}{
+					"is_default": true,
+					"version":    next.Version + 1,
+					"updated_at": now,
+				}).Error
+			})
+			if err != nil {
+				return err
+			}
+			current.IsActive = false
+			current.IsDefault = false
+			current.DeletedAt.Scan(now)
+			current.Version = opts.ExpectedVersion + 1
+			current.UpdatedAt = now
+			address = current
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// prepareAddress valida el tipo y la etiqueta, normaliza la dirección y la geocodifica si
+// cambió respecto de current. Los errores se devuelven juntos en un error de validación.
+func (s *addressService) prepareAddress(ctx context.Context, address *addressModel.Address, current *addressModel.Address) error {
+	fields := map[string]string{}
+
+	address.Label = strings.TrimSpace(address.Label)
+	if !addressModel.IsType(address.Type) {
+		fields["type"] = "must be one of home, work, billing or custom"
+	} else if address.Label == "" {
+		if address.Type == addressModel.TypeCustom {
+			fields["label"] = "is required for custom addresses"
+		}
+		address.Label = address.Type
+	}
+
+	normalized, err := geo.NormalizeAddress(geo.Address{
+		Street:  address.Street,
+		City:    address.City,
+		State:   address.State,
+		ZipCode: address.ZipCode,
+		Country: address.Country,
+	})
+	var invalid *geo.ValidationError
+	if stderrors.As(err, &invalid) {
+		for field, message := range invalid.Fields {
+			fields[field] = message
+		}
+	}
+	if normalized.City == "" {
+		fields["city"] = "is required"
+	}
+	if normalized.Country == "" {
+		if _, ok := fields["country"]; !ok {
+			fields["country"] = "is required"
+		}
+	}
+	if len(fields) > 0 {
+		return errors.NewValidation(errors.ValidationErrorFuncOptions{
+			Message: "invalid address",
+			Fields:  fields,
+		})
+	}
+
+	address.Street = normalized.Street
+	address.City = normalized.City
+	address.State = normalized.State
+	address.ZipCode = normalized.ZipCode
+	address.Country = normalized.Country
+
+	if current != nil && current.Latitude != nil && normalized == (geo.Address{
+		Street:  current.Street,
+		City:    current.City,
+		State:   current.State,
+		ZipCode: current.ZipCode,
+		Country: current.Country,
+	}) {
+		return nil
+	}
+
+	address.Latitude, address.Longitude, address.GeocodedAt = nil, nil, nil
+	coordinates, err := s.Geocoder.Geocode(geo.GeocodeFuncParams{Ctx: ctx, Address: normalized})
+	if err != nil {
+		// Una falla del geocoder no impide guardar la dirección; queda sin ubicar hasta el próximo cambio
+		logger.Log.Warn("⚠️ Error al geocodificar la dirección", zap.Error(err))
+		return nil
+	}
+	if coordinates != nil {
+		now := time.Now().UTC()
+		address.Latitude = &coordinates.Latitude
+		address.Longitude = &coordinates.Longitude
+		address.GeocodedAt = &now
+	}
+	return nil
+}
+
+// clearDefault quita la marca de dirección por defecto de las direcciones del usuario.
+func clearDefault(tx *gorm.DB, userId uuid.UUID) error {
+	if err := tx.Model(&addressModel.Address{}).
+		Where("user_id = ? AND is_default = ?", userId, true).
+		Updates(map[string]interface{}{
+			"is_default": false,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now().UTC(),
+		}).Error; err != nil {
+		return fmt.Errorf("error clearing default address: %w", err)
+	}
+	return nil
+}
+
+// /------ structs ------///
+type ListAddressesFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+}
+
+type GetAddressFuncParams struct {
+	Ctx       context.Context
+	UserId    uuid.UUID
+	AddressId uuid.UUID
+}
+
+type CreateAddressFuncParams struct {
+	Ctx     context.Context
+	UserId  uuid.UUID
+	Address *addressModel.Address
+}
+
+type UpdateAddressFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	AddressId       uuid.UUID
+	Changes         addressModel.UpdateAddressDTO
+	ExpectedVersion int64
+}
+
+type DeleteAddressFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	AddressId       uuid.UUID
+	ExpectedVersion int64
+}
@@ -6,6 +6,8 @@ import (
 	authModel "github.com/aragornz325/piloto-api/internal/auth/model"
 	authService "github.com/aragornz325/piloto-api/internal/auth/service"
 	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	userPresenter "github.com/aragornz325/piloto-api/internal/user/presenter"
+	"github.com/aragornz325/piloto-api/pkg/errors"
 	"github.com/aragornz325/piloto-api/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -35,7 +37,7 @@ func NewAuthHandler(authService authService.AuthService) *AuthHandler {
 // @Accept json
 // @Produce json
 // @Param input body authModel.RegisterDTO true "Data to register a new user"
-// @Success 201 {object} string
+// @Success 201 {object} userPresenter.UserSelfResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /auth/register [post]
 func (h *AuthHandler) RegisterUser(c *gin.Context) {
@@ -74,7 +76,7 @@ func (h *AuthHandler) RegisterUser(c *gin.Context) {
 				return err
 			}
 
-			c.JSON(http.StatusCreated, createdUser)
+			c.JSON(http.StatusCreated, userPresenter.PresentForOwner(c.Request.Context(), createdUser))
 			return nil
 		},
 	})
@@ -95,6 +97,7 @@ func (h *AuthHandler) RegisterUser(c *gin.Context) {
 // @Success 200 {object} string
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) LoginUser(c *gin.Context) {
 	err := utils.PerformHandlerOperation(utils.PerformHandlerOperationFunc{
@@ -119,7 +122,12 @@ func (h *AuthHandler) LoginUser(c *gin.Context) {
 				LoginDTO: &payload,
 			})
 			if err != nil {
-				c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+				// Cuentas suspendidas, baneadas o desactivadas responden 403; el resto 401
+				status := http.StatusUnauthorized
+				if errors.StatusCode(err, status) == http.StatusForbidden {
+					status = http.StatusForbidden
+				}
+				c.JSON(status, ErrorResponse{Error: err.Error()})
 				return err
 			}
 
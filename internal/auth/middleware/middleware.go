@@ -0,0 +1,120 @@
+package authMiddleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	authService "github.com/aragornz325/piloto-api/internal/auth/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/gin-gonic/gin"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type AuthMiddleware struct {
+	JwtService authService.JwtService
+}
+
+func NewAuthMiddleware(jwtService authService.JwtService) *AuthMiddleware {
+	return &AuthMiddleware{
+		JwtService: jwtService,
+	}
+}
+
+// Authenticate lee el header "Authorization: Bearer <token>" y, si viene, valida el token
+// y guarda el usuario en el context de la request. Las requests sin token siguen de largo
+// como anónimas; un token inválido se rechaza con 401 y el de una cuenta no activa con 403.
+func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid authorization header"})
+			return
+		}
+
+		claims, err := m.JwtService.ParseClaims(authService.TokenFuncParams{
+			Ctx:   c.Request.Context(),
+			Token: token,
+		})
+		if err != nil {
+			c.AbortWithStatusJSON(errors.StatusCode(err, http.StatusUnauthorized), ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		principal := &requestctx.Principal{
+			UserId: claims.UserId,
+			Email:  claims.Email,
+			Role:   claims.Role,
+		}
+		if claims.Exp > 0 {
+			principal.ExpiresAt = time.Unix(claims.Exp, 0)
+		}
+		c.Request = c.Request.WithContext(requestctx.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireAuth rechaza con 401 las requests que no traen un usuario autenticado.
+// Debe usarse después de Authenticate.
+func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requestctx.PrincipalFrom(c.Request.Context()); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole rechaza con 401 las requests anónimas y con 403 las de usuarios cuyo rol
+// no está entre los permitidos. Debe usarse después de Authenticate.
+func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+			return
+		}
+		for _, role := range roles {
+			if principal.Role == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: "insufficient permissions"})
+	}
+}
+
+// RequireSelfOrRole deja pasar al usuario cuyo ID coincide con el parámetro de ruta
+// indicado (por ejemplo ":id") o a quien tenga alguno de los roles permitidos.
+// Debe usarse después de Authenticate.
+func (m *AuthMiddleware) RequireSelfOrRole(param string, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+			return
+		}
+		if principal.UserId.String() == c.Param(param) {
+			c.Next()
+			return
+		}
+		for _, role := range roles {
+			if principal.Role == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: "insufficient permissions"})
+	}
+}
@@ -11,7 +11,6 @@ type RegisterDTO struct {
 	FirstName *string `json:"first_name" binding:"required"`
 	LastName  *string `json:"last_name" binding:"required"`
 	Email     *string `json:"email" binding:"required,email"`
-	Driver    *bool   `json:"driver"`
 }
 
 type TokenPayload struct {
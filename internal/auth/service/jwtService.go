@@ -3,10 +3,11 @@ package authService
 import (
 	"context"
 	"fmt"
-	"os"
+	"net/http"
 	"time"
 
 	authModel "github.com/aragornz325/piloto-api/internal/auth/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
 	"github.com/aragornz325/piloto-api/internal/user/service"
 	 "github.com/aragornz325/piloto-api/pkg/errors"
 	"github.com/aragornz325/piloto-api/pkg/utils"
@@ -19,14 +20,20 @@ type JwtService interface {
 	ValidateToken(TokenFuncParams) (bool, error)
 	ParseToken(TokenFuncParams) (string, error)
 	GenerateJWT(GenerateTokenFuncParams) (string, error)
+	ParseClaims(TokenFuncParams) (*authModel.TokenPayload, error)
 }
 
 type jwtService struct {
 	UserService service.UserService
+	// Secret firma y valida los tokens (HS256).
+	Secret []byte
 }
 
-func NewJwtService() JwtService {
-	return &jwtService{}
+func NewJwtService(userService service.UserService, secret []byte) JwtService {
+	return &jwtService{
+		UserService: userService,
+		Secret:      secret,
+	}
 }
 
 // SignToken generates a signed JWT token for a user specified by the given options.
@@ -34,8 +41,7 @@ func NewJwtService() JwtService {
 // constructs the token payload, and signs the JWT. The token is valid for 24 hours.
 // Returns the signed JWT token string or an error if the operation fails.
 // The function uses the PerformServiceOperation utility to handle errors and logging.
-// The token is signed using the HS256 algorithm and a secret key from the environment variable "
-// JWT_SECRET".
+// The token is signed using the HS256 algorithm and the configured JWT secret.
 func (s *jwtService) SignToken(opts SignTokenFuncParams) (string, error) {
 	var signedToken string
 
@@ -92,7 +98,7 @@ func (s *jwtService) SignToken(opts SignTokenFuncParams) (string, error) {
 
 // GenerateJWT generates a JSON Web Token (JWT) using the provided options.
 // It creates a token with user-specific claims such as user ID, email, role, and expiration time.
-// The token is signed using the HS256 algorithm and a secret key from the environment variable "JWT_SECRET".
+// The token is signed using the HS256 algorithm and the configured JWT secret.
 // Returns the signed JWT as a string, or an error if token generation fails.
 func (s *jwtService) GenerateJWT(opts GenerateTokenFuncParams) (string, error) {
 	var signedToken string
@@ -111,7 +117,7 @@ func (s *jwtService) GenerateJWT(opts GenerateTokenFuncParams) (string, error) {
 			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 			var err error
-			signedToken, err = token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+			signedToken, err = token.SignedString(s.Secret)
 			if err != nil {
 				return errors.NewInternal(errors.ErrorFuncOptions{
 					Message: "error signing token",
@@ -135,7 +141,8 @@ func (s *jwtService) GenerateJWT(opts GenerateTokenFuncParams) (string, error) {
 
 // ValidateToken validates a JWT token using the provided TokenFuncParams.
 // It parses the token, checks its signing method, and verifies its validity
-// using the secret from the environment variable "JWT_SECRET".
+// using the configured JWT secret.
+// Tokens of accounts that are no longer active (suspended, banned, deactivated) are invalid.
 // Returns true if the token is valid, otherwise returns false and an error
 // describing the validation failure.
 func (s *jwtService) ValidateToken(opts TokenFuncParams) (bool, error) {
@@ -149,7 +156,7 @@ func (s *jwtService) ValidateToken(opts TokenFuncParams) (bool, error) {
 				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 				}
-				return []byte(os.Getenv("JWT_SECRET")), nil
+				return s.Secret, nil
 			})
 
 			if err != nil || !token.Valid {
@@ -157,6 +164,19 @@ func (s *jwtService) ValidateToken(opts TokenFuncParams) (bool, error) {
 				return fmt.Errorf("invalid token: %w", err)
 			}
 
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return fmt.Errorf("invalid token claims")
+			}
+			uid, _ := claims["user_id"].(string)
+			userId, err := uuid.Parse(uid)
+			if err != nil {
+				return fmt.Errorf("invalid user ID in token claims: %w", err)
+			}
+			if err := s.ensureAccountActive(opts.Ctx, userId); err != nil {
+				return err
+			}
+
 			valid = true
 			return nil
 		},
@@ -189,7 +209,7 @@ func (s *jwtService) ParseToken(opts TokenFuncParams) (string, error) {
 						Err:     fmt.Errorf("unexpected signing method: %v", token.Header["alg"]),
 					})
 				}
-				return []byte(os.Getenv("JWT_SECRET")), nil
+				return s.Secret, nil
 			})
 
 			if err != nil {
@@ -230,6 +250,82 @@ func (s *jwtService) ParseToken(opts TokenFuncParams) (string, error) {
 	return userID, nil
 }
 
+// ParseClaims validates a JWT token and returns its payload (user ID, email, role and expiration).
+// It is used by the auth middleware to build the principal of the request.
+// Returns an unauthorized error if the token is invalid, expired or its claims are malformed,
+// and a forbidden error if the account is no longer active.
+func (s *jwtService) ParseClaims(opts TokenFuncParams) (*authModel.TokenPayload, error) {
+	var payload authModel.TokenPayload
+
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Name:        "ParseClaims",
+		ServiceName: "JWT Service",
+		Operation: func() error {
+			token, err := jwt.Parse(opts.Token, func(token *jwt.Token) (interface{}, error) {
+				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+				}
+				return s.Secret, nil
+			})
+			if err != nil || !token.Valid {
+				return fmt.Errorf("invalid token: %w", err)
+			}
+
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return fmt.Errorf("invalid token claims")
+			}
+
+			uid, _ := claims["user_id"].(string)
+			userId, err := uuid.Parse(uid)
+			if err != nil {
+				return fmt.Errorf("invalid user ID in token claims: %w", err)
+			}
+			if err := s.ensureAccountActive(opts.Ctx, userId); err != nil {
+				return err
+			}
+			email, _ := claims["email"].(string)
+			role, _ := claims["role"].(string)
+			exp, _ := claims["exp"].(float64)
+
+			payload = authModel.TokenPayload{
+				UserId: userId,
+				Email:  email,
+				Role:   role,
+				Exp:    int64(exp),
+			}
+			return nil
+		},
+	})
+
+	if err != nil {
+		if errors.StatusCode(err, http.StatusUnauthorized) == http.StatusForbidden {
+			return nil, err
+		}
+		return nil, errors.NewUnauthorized(errors.SimpleErrorFuncOptions{
+			Message: "invalid token",
+		})
+	}
+
+	return &payload, nil
+}
+
+// ensureAccountActive verifica que la cuenta dueña del token exista y pueda autenticarse.
+func (s *jwtService) ensureAccountActive(ctx context.Context, userId uuid.UUID) error {
+	user, err := s.UserService.GetUserById(service.GetUserByIdFuncParams{
+		Ctx:    ctx,
+		UserId: userId,
+	})
+	if err != nil {
+		return fmt.Errorf("error getting token user: %w", err)
+	}
+	if !userModel.CanAuthenticate(user.Status) {
+		return errors.NewForbidden(errors.ErrorFuncOptions{
+			Message: "account is " + user.Status,
+		})
+	}
+	return nil
+}
 
 // -------structs-------//
 type SignTokenFuncParams struct {
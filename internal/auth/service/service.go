@@ -22,12 +22,13 @@ type AuthService interface {
 
 type authService struct {
 	UserService service.UserService
-	JwtService jwtService
+	JwtService JwtService
 }
 
-func NewAuthService(userService service.UserService) AuthService {
+func NewAuthService(userService service.UserService, jwtService JwtService) AuthService {
 	return &authService{
 		UserService: userService,
+		JwtService:  jwtService,
 	}
 }
 
@@ -93,6 +94,7 @@ func (s *authService) RegisterUser(opts RegisterUserFuncParams) (*userModel.User
 
 // LoginUser authenticates a user using the provided login parameters.
 // It retrieves the user by email and verifies the password using bcrypt.
+// Accounts that are not active (suspended, banned, deactivated...) are rejected with a 403.
 // Returns the authenticated user on success, or an error if authentication fails.
 //
 // Parameters:
@@ -125,6 +127,12 @@ func (s *authService) LoginUser(opts LoginUserFuncParams) (*string, error) {
 				})
 			}
 
+			if !userModel.CanAuthenticate(userDb.Status) {
+				return errors.NewForbidden(errors.ErrorFuncOptions{
+					Message: "account is " + userDb.Status,
+				})
+			}
+
 			signedToken, err := s.JwtService.SignToken(SignTokenFuncParams{
 				Ctx:    opts.Ctx,
 				UserId: userDb.ID,
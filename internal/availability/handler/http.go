@@ -0,0 +1,260 @@
+package availabilityHandler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	m "github.com/aragornz325/piloto-api/internal/availability/model"
+	availabilityService "github.com/aragornz325/piloto-api/internal/availability/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type AvailabilityHandler struct {
+	AvailabilityService availabilityService.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService availabilityService.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		AvailabilityService: availabilityService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Get availability
+// @Description Get the weekly schedule of a driver, the upcoming date exceptions and the duty status. Times are in the time zone of the driver's profile (UTC if not set).
+// @Tags availability
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} availabilityModel.Availability
+// @Failure 400 {object} ErrorResponse
+// @Router /users/{id}/availability [get]
+func (h *AvailabilityHandler) GetAvailabilityHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	result, err := h.AvailabilityService.GetAvailability(availabilityService.GetAvailabilityFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Set weekly schedule
+// @Description Replace the weekly availability windows of a driver. Weekday 0 is sunday and times are "HH:MM" in the driver's time zone; a window can not cross midnight (use 24:00 as end). An empty list clears the schedule.
+// @Tags availability
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body availabilityModel.ScheduleDTO true "Weekly windows"
+// @Success 200 {object} availabilityModel.Availability
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /users/{id}/availability/schedule [put]
+func (h *AvailabilityHandler) SetScheduleHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.ScheduleDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+	windows := make([]availabilityService.Window, len(payload.Windows))
+	for i, window := range payload.Windows {
+		windows[i] = availabilityService.Window{
+			Weekday: *window.Weekday,
+			Start:   *window.Start,
+			End:     *window.End,
+		}
+	}
+
+	result, err := h.AvailabilityService.SetSchedule(availabilityService.SetScheduleFuncParams{
+		Ctx:     c.Request.Context(),
+		UserId:  userId,
+		Windows: windows,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Set date exception
+// @Description Create or replace the availability of a specific date in the driver's time zone. The windows replace the weekly schedule for that date; without windows the driver is not available that day.
+// @Tags availability
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param date path string true "Date (YYYY-MM-DD)"
+// @Param input body availabilityModel.ExceptionDTO true "Windows of the date"
+// @Success 200 {object} availabilityModel.AvailabilityException
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /users/{id}/availability/exceptions/{date} [put]
+func (h *AvailabilityHandler) SetExceptionHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.ExceptionDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+	windows := make([]m.TimeRange, len(payload.Windows))
+	for i, window := range payload.Windows {
+		windows[i] = m.TimeRange{Start: *window.Start, End: *window.End}
+	}
+	reason := ""
+	if payload.Reason != nil {
+		reason = *payload.Reason
+	}
+
+	result, err := h.AvailabilityService.SetException(availabilityService.SetExceptionFuncParams{
+		Ctx:     c.Request.Context(),
+		UserId:  userId,
+		Date:    c.Param("date"),
+		Windows: windows,
+		Reason:  reason,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Delete date exception
+// @Description Remove the exception of a date so the weekly schedule applies again
+// @Tags availability
+// @Param id path string true "User ID"
+// @Param date path string true "Date (YYYY-MM-DD)"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/availability/exceptions/{date} [delete]
+func (h *AvailabilityHandler) DeleteExceptionHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	if err := h.AvailabilityService.DeleteException(availabilityService.DeleteExceptionFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+		Date:   c.Param("date"),
+	}); err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Set duty status
+// @Description Put a driver on or off duty. Only approved drivers can go on duty, and a driver that stops being approved goes off duty automatically.
+// @Tags availability
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body availabilityModel.DutyDTO true "Duty status"
+// @Success 200 {object} driverModel.Driver
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/duty [put]
+func (h *AvailabilityHandler) SetDutyHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.DutyDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.AvailabilityService.SetDuty(availabilityService.SetDutyFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+		OnDuty: *payload.OnDuty,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary List available drivers
+// @Description List the approved drivers whose schedule covers the given instant in their own time zone. By default only drivers on duty are listed.
+// @Tags availability
+// @Produce json
+// @Param at query string false "Instant in RFC3339 format (defaults to now)"
+// @Param include_off_duty query bool false "Include drivers that are off duty"
+// @Success 200 {array} availabilityService.AvailableDriver
+// @Failure 400 {object} ErrorResponse
+// @Router /drivers/available [get]
+func (h *AvailabilityHandler) ListAvailableDriversHandler(c *gin.Context) {
+	at := time.Now().UTC()
+	if raw := c.Query("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at must be a RFC3339 timestamp", Fields: map[string]string{"at": "must be a RFC3339 timestamp"}})
+			return
+		}
+		at = parsed
+	}
+	includeOffDuty := false
+	if raw := c.Query("include_off_duty"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "include_off_duty must be a boolean", Fields: map[string]string{"include_off_duty": "must be a boolean"}})
+			return
+		}
+		includeOffDuty = parsed
+	}
+
+	result, err := h.AvailabilityService.ListAvailableDrivers(availabilityService.ListAvailableDriversFuncParams{
+		Ctx:            c.Request.Context(),
+		At:             at,
+		IncludeOffDuty: includeOffDuty,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
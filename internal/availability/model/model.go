@@ -0,0 +1,151 @@
+package availabilityModel
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+// DateLayout es el formato de las fechas de excepción, en la zona horaria del conductor.
+const DateLayout = "2006-01-02"
+
+// clockPattern acepta horas "HH:MM" de 00:00 a 24:00 (24:00 solo como fin de franja).
+var clockPattern = regexp.MustCompile(`^(([01][0-9]|2[0-3]):[0-5][0-9]|24:00)$`)
+
+// TimeRange es una franja horaria [Start, End) en formato "HH:MM" en la zona horaria del
+// conductor. Las franjas no cruzan la medianoche: 22:00 a 02:00 se cargan como 22:00-24:00
+// y 00:00-02:00 del día siguiente.
+type TimeRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Contains indica si la hora "HH:MM" está dentro de la franja. Como las horas tienen ancho
+// fijo se pueden comparar como texto.
+func (r TimeRange) Contains(clock string) bool {
+	return r.Start <= clock && clock < r.End
+}
+
+// Validate devuelve un error si la franja no tiene horas válidas o termina antes de empezar.
+func (r TimeRange) Validate() error {
+	if !clockPattern.MatchString(r.Start) || r.Start == "24:00" {
+		return fmt.Errorf("start must be a time between 00:00 and 23:59")
+	}
+	if !clockPattern.MatchString(r.End) {
+		return fmt.Errorf("end must be a time between 00:01 and 24:00")
+	}
+	if r.End <= r.Start {
+		return fmt.Errorf("end must be after start")
+	}
+	return nil
+}
+
+// AvailabilityWindow es una franja semanal en la que el conductor está disponible.
+type AvailabilityWindow struct {
+	baseModel.BaseModel
+	UserId uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	// Weekday es el día de la semana, 0 es domingo.
+	Weekday   int    `gorm:"not null" json:"weekday"`
+	StartTime string `gorm:"not null" json:"start"`
+	EndTime   string `gorm:"not null" json:"end"`
+}
+
+// Range devuelve la franja horaria de la ventana.
+func (w *AvailabilityWindow) Range() TimeRange {
+	return TimeRange{Start: w.StartTime, End: w.EndTime}
+}
+
+// AvailabilityException reemplaza las franjas semanales de una fecha puntual. Sin franjas,
+// el conductor no está disponible en todo el día.
+type AvailabilityException struct {
+	baseModel.BaseModel
+	UserId  uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex:idx_availability_exceptions_user_date,where:deleted_at IS NULL" json:"user_id"`
+	Date    string      `gorm:"not null;uniqueIndex:idx_availability_exceptions_user_date,where:deleted_at IS NULL" json:"date"`
+	Windows []TimeRange `gorm:"type:jsonb;serializer:json" json:"windows"`
+	Reason  string      `json:"reason,omitempty"`
+}
+
+// Availability es la disponibilidad completa de un conductor.
+type Availability struct {
+	UserId     uuid.UUID                `json:"user_id"`
+	Timezone   string                   `json:"timezone"`
+	OnDuty     bool                     `json:"on_duty"`
+	Windows    []*AvailabilityWindow    `json:"windows"`
+	Exceptions []*AvailabilityException `json:"exceptions"`
+}
+
+// AvailableAt indica si las franjas cubren el instante at en la zona horaria location. Si la
+// fecha local tiene una excepción se usan sus franjas en lugar de las semanales.
+// exceptions está indexado por fecha (DateLayout).
+func AvailableAt(windows []*AvailabilityWindow, exceptions map[string]*AvailabilityException, location *time.Location, at time.Time) bool {
+	local := at.In(location)
+	clock := local.Format("15:04")
+	if exception, ok := exceptions[local.Format(DateLayout)]; ok {
+		for _, r := range exception.Windows {
+			if r.Contains(clock) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, window := range windows {
+		if window.Weekday == int(local.Weekday()) && window.Range().Contains(clock) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overlapping devuelve el índice de una franja que se superpone con otra del mismo día, o -1.
+// days indica el día de cada franja (para las excepciones todas comparten el día).
+func Overlapping(ranges []TimeRange, days []int) int {
+	indexes := make([]int, len(ranges))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(a, b int) bool {
+		i, j := indexes[a], indexes[b]
+		if days[i] != days[j] {
+			return days[i] < days[j]
+		}
+		return ranges[i].Start < ranges[j].Start
+	})
+	for k := 1; k < len(indexes); k++ {
+		prev, curr := indexes[k-1], indexes[k]
+		if days[prev] == days[curr] && ranges[curr].Start < ranges[prev].End {
+			return curr
+		}
+	}
+	return -1
+}
+
+// WindowDTO es una franja semanal; las horas son "HH:MM".
+type WindowDTO struct {
+	Weekday *int    `json:"weekday" binding:"required,min=0,max=6"`
+	Start   *string `json:"start" binding:"required"`
+	End     *string `json:"end" binding:"required"`
+}
+
+// ScheduleDTO reemplaza todas las franjas semanales; una lista vacía borra el horario.
+type ScheduleDTO struct {
+	Windows []WindowDTO `json:"windows" binding:"required,max=50,dive"`
+}
+
+type TimeRangeDTO struct {
+	Start *string `json:"start" binding:"required"`
+	End   *string `json:"end" binding:"required"`
+}
+
+// ExceptionDTO son las franjas de una fecha; sin franjas el conductor no está disponible.
+type ExceptionDTO struct {
+	Windows []TimeRangeDTO `json:"windows" binding:"max=10,dive"`
+	Reason  *string        `json:"reason" binding:"omitempty,max=200"`
+}
+
+type DutyDTO struct {
+	OnDuty *bool `json:"on_duty" binding:"required"`
+}
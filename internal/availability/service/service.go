@@ -0,0 +1,572 @@
+package availabilityService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	availabilityModel "github.com/aragornz325/piloto-api/internal/availability/model"
+	driverModel "github.com/aragornz325/piloto-api/internal/driver/model"
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	vehicleModel "github.com/aragornz325/piloto-api/internal/vehicle/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Package availabilityService maneja el horario semanal de los conductores, las excepciones
+// por fecha y el estado en servicio. Las horas se interpretan en la zona horaria del perfil.
+
+type AvailabilityService interface {
+	GetAvailability(GetAvailabilityFuncParams) (*availabilityModel.Availability, error)
+	SetSchedule(SetScheduleFuncParams) (*availabilityModel.Availability, error)
+	SetException(SetExceptionFuncParams) (*availabilityModel.AvailabilityException, error)
+	DeleteException(DeleteExceptionFuncParams) error
+	SetDuty(SetDutyFuncParams) (*driverModel.Driver, error)
+	ListAvailableDrivers(ListAvailableDriversFuncParams) ([]AvailableDriver, error)
+}
+
+type availabilityService struct{}
+
+func NewAvailabilityService() AvailabilityService {
+	return &availabilityService{}
+}
+
+// GetAvailability returns the weekly schedule of a user, the exceptions from today on (in
+// the user's time zone) and the duty status.
+//
+// Parameters:
+//   - opts: GetAvailabilityFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - *availabilityModel.Availability: The availability of the user.
+//   - error: An error if the query fails.
+func (s *availabilityService) GetAvailability(opts GetAvailabilityFuncParams) (*availabilityModel.Availability, error) {
+	var availability *availabilityModel.Availability
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetAvailability",
+		ServiceName: "availability",
+		Operation: func() error {
+			var err error
+			availability, err = loadAvailability(db.DB.WithContext(opts.Ctx), opts.UserId)
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return availability, nil
+}
+
+// SetSchedule replaces the weekly availability windows of a driver. Windows of the same day
+// can not overlap and an empty list clears the schedule.
+//
+// Parameters:
+//   - opts: SetScheduleFuncParams containing the context, the user ID and the windows.
+//
+// Returns:
+//   - *availabilityModel.Availability: The availability of the driver with the new schedule.
+//   - error: A 400 with the invalid windows or 403 if the user is not a driver.
+func (s *availabilityService) SetSchedule(opts SetScheduleFuncParams) (*availabilityModel.Availability, error) {
+	var availability *availabilityModel.Availability
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "SetSchedule",
+		ServiceName: "availability",
+		Operation: func() error {
+			fields := map[string]string{}
+			ranges := make([]availabilityModel.TimeRange, len(opts.Windows))
+			days := make([]int, len(opts.Windows))
+			for i, window := range opts.Windows {
+				ranges[i] = window.Range()
+				days[i] = window.Weekday
+				if window.Weekday < 0 || window.Weekday > 6 {
+					fields[fmt.Sprintf("windows[%d].weekday", i)] = "must be between 0 (sunday) and 6 (saturday)"
+				}
+				if err := ranges[i].Validate(); err != nil {
+					fields[fmt.Sprintf("windows[%d]", i)] = err.Error()
+				}
+			}
+			if len(fields) == 0 {
+				if i := availabilityModel.Overlapping(ranges, days); i >= 0 {
+					fields[fmt.Sprintf("windows[%d]", i)] = "overlaps another window of the same day"
+				}
+			}
+			if len(fields) > 0 {
+				return errors.NewValidation(errors.ValidationErrorFuncOptions{
+					Message: "invalid schedule",
+					Fields:  fields,
+				})
+			}
+
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := requireDriver(tx, opts.UserId); err != nil {
+					return err
+				}
+				now := time.Now().UTC()
+				if err := tx.Model(&availabilityModel.AvailabilityWindow{}).
+					Where("user_id = ?", opts.UserId).
+					Updates(map[string]interface{}{
+						"is_active":  false,
+						"deleted_at": now,
+						"updated_at": now,
+					}).Error; err != nil {
+					return fmt.Errorf("error clearing schedule: %w", err)
+				}
+				if len(opts.Windows) > 0 {
+					windows := make([]*availabilityModel.AvailabilityWindow, len(opts.Windows))
+					for i, window := range opts.Windows {
+						windows[i] = &availabilityModel.AvailabilityWindow{
+							UserId:    opts.UserId,
+							Weekday:   window.Weekday,
+							StartTime: window.Start,
+							EndTime:   window.End,
+						}
+					}
+					if err := tx.Create(&windows).Error; err != nil {
+						return fmt.Errorf("error saving schedule: %w", err)
+					}
+				}
+				var err error
+				availability, err = loadAvailability(tx, opts.UserId)
+				return err
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return availability, nil
+}
+
+// SetException creates or replaces the exception of a date. The windows replace the weekly
+// schedule for that date; without windows the driver is not available the whole day.
+//
+// Parameters:
+//   - opts: SetExceptionFuncParams containing the context, the user ID, the date (YYYY-MM-DD), the windows and the reason.
+//
+// Returns:
+//   - *availabilityModel.AvailabilityException: The saved exception.
+//   - error: A 400 with the invalid fields or 403 if the user is not a driver.
+func (s *availabilityService) SetException(opts SetExceptionFuncParams) (*availabilityModel.AvailabilityException, error) {
+	exception := availabilityModel.AvailabilityException{
+		UserId:  opts.UserId,
+		Date:    opts.Date,
+		Windows: opts.Windows,
+		Reason:  strings.TrimSpace(opts.Reason),
+	}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "SetException",
+		ServiceName: "availability",
+		Operation: func() error {
+			fields := map[string]string{}
+			if _, err := time.Parse(availabilityModel.DateLayout, opts.Date); err != nil {
+				fields["date"] = "must be a date in YYYY-MM-DD format"
+			}
+			for i, r := range exception.Windows {
+				if err := r.Validate(); err != nil {
+					fields[fmt.Sprintf("windows[%d]", i)] = err.Error()
+				}
+			}
+			if len(fields) == 0 {
+				if i := availabilityModel.Overlapping(exception.Windows, make([]int, len(exception.Windows))); i >= 0 {
+					fields[fmt.Sprintf("windows[%d]", i)] = "overlaps another window"
+				}
+			}
+			if len(fields) > 0 {
+				return errors.NewValidation(errors.ValidationErrorFuncOptions{
+					Message: "invalid exception",
+					Fields:  fields,
+				})
+			}
+			if exception.Windows == nil {
+				exception.Windows = []availabilityModel.TimeRange{}
+			}
+
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := requireDriver(tx, opts.UserId); err != nil {
+					return err
+				}
+				var current availabilityModel.AvailabilityException
+				err := tx.Where("user_id = ? AND date = ?", opts.UserId, opts.Date).First(&current).Error
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					if err := tx.Create(&exception).Error; err != nil {
+						return fmt.Errorf("error saving exception: %w", err)
+					}
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("error getting exception: %w", err)
+				}
+
+				// Updates con struct para que Windows pase por el serializer; Select escribe
+				// también el motivo vacío
+				update := availabilityModel.AvailabilityException{
+					Windows: exception.Windows,
+					Reason:  exception.Reason,
+				}
+				update.Version = current.Version + 1
+				update.UpdatedAt = time.Now().UTC()
+				if err := tx.Model(&current).
+					Select("windows", "reason", "version", "updated_at").
+					Updates(&update).Error; err != nil {
+					return fmt.Errorf("error saving exception: %w", err)
+				}
+				current.Windows = update.Windows
+				current.Reason = update.Reason
+				current.Version = update.Version
+				current.UpdatedAt = update.UpdatedAt
+				exception = current
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &exception, nil
+}
+
+// DeleteException removes the exception of a date, so the weekly schedule applies again.
+//
+// Parameters:
+//   - opts: DeleteExceptionFuncParams containing the context, the user ID and the date.
+//
+// Returns:
+//   - error: A 404 if there is no exception for that date.
+func (s *availabilityService) DeleteException(opts DeleteExceptionFuncParams) error {
+	return utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "DeleteException",
+		ServiceName: "availability",
+		Operation: func() error {
+			var exception availabilityModel.AvailabilityException
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("user_id = ? AND date = ?", opts.UserId, opts.Date).
+				First(&exception).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "availability exception not found",
+					})
+				}
+				return fmt.Errorf("error getting exception: %w", err)
+			}
+			now := time.Now().UTC()
+			if err := db.DB.WithContext(opts.Ctx).Model(&exception).Updates(map[string]interface{}{
+				"is_active":  false,
+				"deleted_at": now,
+				"version":    exception.Version + 1,
+				"updated_at": now,
+			}).Error; err != nil {
+				return fmt.Errorf("error deleting exception: %w", err)
+			}
+			return nil
+		},
+	})
+}
+
+// SetDuty puts a driver on or off duty. Only approved drivers whose license and active
+// vehicle insurance are not expired can go on duty; doing so also clears a previous
+// non-compliant flag. Going off duty is always allowed.
+//
+// Parameters:
+//   - opts: SetDutyFuncParams containing the context, the user ID and the new duty status.
+//
+// Returns:
+//   - *driverModel.Driver: The driver with the new duty status.
+//   - error: A 404 if the user is not registered as a driver or 409 if the driver is not approved or has something expired.
+func (s *availabilityService) SetDuty(opts SetDutyFuncParams) (*driverModel.Driver, error) {
+	var driver driverModel.Driver
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "SetDuty",
+		ServiceName: "availability",
+		Operation: func() error {
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("user_id = ?", opts.UserId).
+					First(&driver).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "driver not found",
+						})
+					}
+					return fmt.Errorf("error getting driver: %w", err)
+				}
+				if opts.OnDuty && driver.Status != driverModel.StatusApproved {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "only approved drivers can go on duty",
+					})
+				}
+				if driver.OnDuty == opts.OnDuty {
+					return nil
+				}
+
+				now := time.Now().UTC()
+				changes := map[string]interface{}{
+					"on_duty":         opts.OnDuty,
+					"duty_changed_at": now,
+					"version":         driver.Version + 1,
+					"updated_at":      now,
+				}
+				if opts.OnDuty {
+					// Se revisan los vencimientos en el momento: quien renovó no espera al job
+					if err := checkCompliance(tx, &driver, now); err != nil {
+						return err
+					}
+					changes["compliant"] = true
+					changes["compliance_issues"] = nil
+					driver.Compliant = true
+					driver.ComplianceIssues = nil
+				}
+				if err := tx.Model(&driver).Updates(changes).Error; err != nil {
+					return fmt.Errorf("error updating duty status: %w", err)
+				}
+				driver.OnDuty = opts.OnDuty
+				driver.DutyChangedAt = &now
+				driver.Version++
+				driver.UpdatedAt = now
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// ListAvailableDrivers returns the approved drivers whose schedule covers the instant
+// opts.At in their own time zone. Unless opts.IncludeOffDuty is set, only drivers on duty
+// are returned. opts.UserIds restricts the query to the given drivers.
+//
+// Parameters:
+//   - opts: ListAvailableDriversFuncParams containing the context, the instant, whether to include drivers off duty and the optional drivers to consider.
+//
+// Returns:
+//   - []AvailableDriver: The available drivers with their local time.
+//   - error: An error if the query fails.
+func (s *availabilityService) ListAvailableDrivers(opts ListAvailableDriversFuncParams) ([]AvailableDriver, error) {
+	available := []AvailableDriver{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListAvailableDrivers",
+		ServiceName: "availability",
+		Operation: func() error {
+			tx := db.DB.WithContext(opts.Ctx)
+
+			var candidates []AvailableDriver
+			query := tx.Table("drivers").
+				Select("drivers.user_id, users.first_name, users.last_name, drivers.on_duty, drivers.active_vehicle_id, COALESCE(profiles.timezone, '') AS timezone").
+				Joins("JOIN users ON users.id = drivers.user_id AND users.deleted_at IS NULL").
+				Joins("LEFT JOIN profiles ON profiles.user_id = drivers.user_id AND profiles.deleted_at IS NULL").
+				Where("drivers.deleted_at IS NULL AND users.driver = ? AND users.status = ?", true, userModel.StatusActive)
+			query = query.Where("drivers.compliant = ?", true)
+			if !opts.IncludeOffDuty {
+				query = query.Where("drivers.on_duty = ?", true)
+			}
+			if opts.UserIds != nil {
+				query = query.Where("drivers.user_id IN ?", opts.UserIds)
+			}
+			if err := query.Order("drivers.duty_changed_at ASC").Scan(&candidates).Error; err != nil {
+				return fmt.Errorf("error listing drivers: %w", err)
+			}
+			if len(candidates) == 0 {
+				return nil
+			}
+			userIds := make([]uuid.UUID, len(candidates))
+			for i, candidate := range candidates {
+				userIds[i] = candidate.UserId
+			}
+
+			var windows []*availabilityModel.AvailabilityWindow
+			if err := tx.Where("user_id IN ?", userIds).Find(&windows).Error; err != nil {
+				return fmt.Errorf("error listing windows: %w", err)
+			}
+			// La fecha local de cada conductor está a lo sumo un día antes o después de la fecha UTC
+			utcDay := opts.At.UTC()
+			var exceptions []*availabilityModel.AvailabilityException
+			if err := tx.Where("user_id IN ? AND date BETWEEN ? AND ?", userIds,
+				utcDay.AddDate(0, 0, -1).Format(availabilityModel.DateLayout),
+				utcDay.AddDate(0, 0, 1).Format(availabilityModel.DateLayout)).
+				Find(&exceptions).Error; err != nil {
+				return fmt.Errorf("error listing exceptions: %w", err)
+			}
+
+			windowsByUser := map[uuid.UUID][]*availabilityModel.AvailabilityWindow{}
+			for _, window := range windows {
+				windowsByUser[window.UserId] = append(windowsByUser[window.UserId], window)
+			}
+			exceptionsByUser := map[uuid.UUID]map[string]*availabilityModel.AvailabilityException{}
+			for _, exception := range exceptions {
+				if exceptionsByUser[exception.UserId] == nil {
+					exceptionsByUser[exception.UserId] = map[string]*availabilityModel.AvailabilityException{}
+				}
+				exceptionsByUser[exception.UserId][exception.Date] = exception
+			}
+
+			for _, candidate := range candidates {
+				location := (&profileModel.Profile{Timezone: candidate.Timezone}).Location()
+				if !availabilityModel.AvailableAt(windowsByUser[candidate.UserId], exceptionsByUser[candidate.UserId], location, opts.At) {
+					continue
+				}
+				candidate.Timezone = location.String()
+				candidate.LocalTime = opts.At.In(location).Format(time.RFC3339)
+				available = append(available, candidate)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return available, nil
+}
+
+// loadAvailability arma la disponibilidad de un usuario: horario semanal, excepciones desde
+// hoy en su zona horaria y estado en servicio.
+func loadAvailability(tx *gorm.DB, userId uuid.UUID) (*availabilityModel.Availability, error) {
+	var profile profileModel.Profile
+	if err := tx.Select("id", "timezone").Where("user_id = ?", userId).First(&profile).Error; err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("error getting profile: %w", err)
+	}
+	location := profile.Location()
+
+	var driver driverModel.Driver
+	if err := tx.Select("id", "on_duty").Where("user_id = ?", userId).First(&driver).Error; err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("error getting driver: %w", err)
+	}
+
+	availability := availabilityModel.Availability{
+		UserId:     userId,
+		Timezone:   location.String(),
+		OnDuty:     driver.OnDuty,
+		Windows:    []*availabilityModel.AvailabilityWindow{},
+		Exceptions: []*availabilityModel.AvailabilityException{},
+	}
+	if err := tx.Where("user_id = ?", userId).
+		Order("weekday ASC, start_time ASC").
+		Find(&availability.Windows).Error; err != nil {
+		return nil, fmt.Errorf("error listing windows: %w", err)
+	}
+	today := time.Now().In(location).Format(availabilityModel.DateLayout)
+	if err := tx.Where("user_id = ? AND date >= ?", userId, today).
+		Order("date ASC").
+		Find(&availability.Exceptions).Error; err != nil {
+		return nil, fmt.Errorf("error listing exceptions: %w", err)
+	}
+	return &availability, nil
+}
+
+// requireDriver responde 403 si el usuario no es un conductor aprobado (User.Driver).
+func requireDriver(tx *gorm.DB, userId uuid.UUID) error {
+	var user userModel.User
+	if err := tx.Select("id", "driver").Where("id = ?", userId).First(&user).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+				Message: "user not found",
+			})
+		}
+		return fmt.Errorf("error getting user: %w", err)
+	}
+	if !user.Driver {
+		return errors.NewForbidden(errors.ErrorFuncOptions{
+			Message: "only drivers can set their availability",
+		})
+	}
+	return nil
+}
+
+// checkCompliance responde 409 si el conductor tiene vencida la licencia o el seguro del
+// vehículo activo.
+func checkCompliance(tx *gorm.DB, driver *driverModel.Driver, now time.Time) error {
+	var insuranceExpiresAt *time.Time
+	if driver.ActiveVehicleId != nil {
+		var vehicle vehicleModel.Vehicle
+		if err := tx.Select("id", "insurance_expires_at").Where("id = ?", *driver.ActiveVehicleId).First(&vehicle).Error; err != nil {
+			if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("error getting active vehicle: %w", err)
+			}
+		} else {
+			insuranceExpiresAt = &vehicle.InsuranceExpiresAt
+		}
+	}
+	if issues := driver.ComplianceIssuesAt(now, insuranceExpiresAt); len(issues) > 0 {
+		return errors.NewConflict(errors.SimpleErrorFuncOptions{
+			Message: "renew what expired to go on duty: " + strings.Join(issues, ", "),
+		})
+	}
+	return nil
+}
+
+// /------ structs ------///
+
+// Window es una franja semanal validada por SetSchedule.
+type Window struct {
+	Weekday int
+	Start   string
+	End     string
+}
+
+func (w Window) Range() availabilityModel.TimeRange {
+	return availabilityModel.TimeRange{Start: w.Start, End: w.End}
+}
+
+// AvailableDriver es un conductor disponible en el instante consultado.
+type AvailableDriver struct {
+	UserId          uuid.UUID  `json:"user_id"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	OnDuty          bool       `json:"on_duty"`
+	ActiveVehicleId *uuid.UUID `json:"active_vehicle_id,omitempty"`
+	Timezone        string     `json:"timezone"`
+	// LocalTime es el instante consultado en la zona horaria del conductor.
+	LocalTime string `json:"local_time" gorm:"-"`
+}
+
+type GetAvailabilityFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+}
+
+type SetScheduleFuncParams struct {
+	Ctx     context.Context
+	UserId  uuid.UUID
+	Windows []Window
+}
+
+type SetExceptionFuncParams struct {
+	Ctx     context.Context
+	UserId  uuid.UUID
+	Date    string
+	Windows []availabilityModel.TimeRange
+	Reason  string
+}
+
+type DeleteExceptionFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+	Date   string
+}
+
+type SetDutyFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+	OnDuty bool
+}
+
+type ListAvailableDriversFuncParams struct {
+	Ctx            context.Context
+	At             time.Time
+	IncludeOffDuty bool
+	// UserIds, si no es nil, restringe la consulta a esos conductores.
+	UserIds []uuid.UUID
+}
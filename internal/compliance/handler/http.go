@@ -0,0 +1,42 @@
+package complianceHandler
+
+import (
+	"net/http"
+
+	complianceService "github.com/aragornz325/piloto-api/internal/compliance/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type ComplianceHandler struct {
+	ComplianceService complianceService.ComplianceService
+}
+
+func NewComplianceHandler(complianceService complianceService.ComplianceService) *ComplianceHandler {
+	return &ComplianceHandler{
+		ComplianceService: complianceService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Run expiry checks
+// @Description Run now the job that also runs every hour: send the license and insurance expiry reminders due (30, 7 and 1 day before, and once expired) and flag as non compliant the drivers with something expired, taking them off duty. Reminders are never repeated.
+// @Tags drivers
+// @Produce json
+// @Success 200 {object} complianceService.CheckResult
+// @Failure 500 {object} ErrorResponse
+// @Router /drivers/compliance/run [post]
+func (h *ComplianceHandler) RunChecksHandler(c *gin.Context) {
+	result, err := h.ComplianceService.RunChecks(c.Request.Context())
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
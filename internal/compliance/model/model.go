@@ -0,0 +1,46 @@
+package complianceModel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Qué vence: la licencia de un conductor o el seguro de un vehículo.
+const (
+	KindLicense   = "license"
+	KindInsurance = "insurance"
+)
+
+// ReminderTiers son los días antes del vencimiento en los que se avisa, de menor a mayor.
+// Además se manda un último aviso (0 días) cuando ya venció.
+var ReminderTiers = []int{1, 7, 30}
+
+// TierFor devuelve el aviso que corresponde mandar en now para algo que vence en expiresAt:
+// el escalón más cercano que ya se alcanzó, o 0 si está vencido. Si el job no corrió a
+// tiempo no se mandan los escalones que quedaron atrás. ok es falso si todavía falta más
+// que el escalón más lejano.
+func TierFor(expiresAt, now time.Time) (days int, ok bool) {
+	if !now.Before(expiresAt) {
+		return 0, true
+	}
+	remaining := expiresAt.Sub(now)
+	for _, tier := range ReminderTiers {
+		if remaining <= time.Duration(tier)*24*time.Hour {
+			return tier, true
+		}
+	}
+	return 0, false
+}
+
+// ExpiryReminder registra cada aviso de vencimiento mandado, para no repetirlo. Si se renueva
+// el documento cambia ExpiresAt y los avisos vuelven a empezar.
+type ExpiryReminder struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Kind       string    `gorm:"not null;uniqueIndex:idx_expiry_reminders_subject_tier" json:"kind"`
+	SubjectId  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_expiry_reminders_subject_tier" json:"subject_id"`
+	ExpiresAt  time.Time `gorm:"not null;uniqueIndex:idx_expiry_reminders_subject_tier" json:"expires_at"`
+	DaysBefore int       `gorm:"not null;uniqueIndex:idx_expiry_reminders_subject_tier" json:"days_before"`
+	UserId     uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
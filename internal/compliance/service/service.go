@@ -0,0 +1,265 @@
+package complianceService
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	complianceModel "github.com/aragornz325/piloto-api/internal/compliance/model"
+	driverModel "github.com/aragornz325/piloto-api/internal/driver/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/notifier"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// CheckInterval es cada cuánto corre el job de vencimientos.
+const CheckInterval = time.Hour
+
+type ComplianceService interface {
+	RunChecks(ctx context.Context) (*CheckResult, error)
+	Run(ctx context.Context)
+}
+
+type complianceService struct {
+	Notifier notifier.Notifier
+}
+
+func NewComplianceService(notifier notifier.Notifier) ComplianceService {
+	return &complianceService{
+		Notifier: notifier,
+	}
+}
+
+// RunChecks scans driver licenses and vehicle insurances: it sends the reminders due (30, 7
+// and 1 day before the expiry, and once expired) and flags as non compliant the drivers
+// whose license or active vehicle insurance expired, taking them off duty. Drivers that
+// renewed are flagged as compliant again. Running it several times, or from several
+// instances, does not repeat reminders.
+//
+// Parameters:
+//   - ctx: Context of the operation.
+//
+// Returns:
+//   - *CheckResult: How many reminders were sent and drivers flagged or cleared.
+//   - error: An error if a query fails.
+func (s *complianceService) RunChecks(ctx context.Context) (*CheckResult, error) {
+	result := CheckResult{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         ctx,
+		Name:        "RunChecks",
+		ServiceName: "compliance",
+		Operation: func() error {
+			now := time.Now().UTC()
+			sent, err := s.sendReminders(ctx, now)
+			if err != nil {
+				return err
+			}
+			result.RemindersSent = sent
+			result.DriversFlagged, result.DriversCleared, err = flagDrivers(ctx, now)
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Run executes RunChecks right away and then every CheckInterval until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the loop.
+func (s *complianceService) Run(ctx context.Context) {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+	for {
+		if result, err := s.RunChecks(ctx); err != nil {
+			logger.Log.Error("💥 Error al revisar vencimientos", zap.Error(err))
+		} else if result.RemindersSent > 0 || result.DriversFlagged > 0 || result.DriversCleared > 0 {
+			logger.Log.Info("📅 Vencimientos revisados",
+				zap.Int("reminders", result.RemindersSent),
+				zap.Int("flagged", result.DriversFlagged),
+				zap.Int("cleared", result.DriversCleared),
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendReminders manda los avisos de licencias y seguros que vencen en los próximos 30 días
+// o ya vencieron. Cada aviso se registra antes de mandarlo: el índice único evita que dos
+// instancias lo manden dos veces. Si el envío falla se borra para reintentar en la próxima
+// corrida.
+func (s *complianceService) sendReminders(ctx context.Context, now time.Time) (int, error) {
+	horizon := now.Add(time.Duration(slices.Max(complianceModel.ReminderTiers)) * 24 * time.Hour)
+	tx := db.DB.WithContext(ctx)
+
+	var licenses []expiring
+	if err := tx.Table("drivers").
+		Select("drivers.id AS subject_id, drivers.user_id, users.email, users.first_name, drivers.license_expires_at AS expires_at").
+		Joins("JOIN users ON users.id = drivers.user_id AND users.deleted_at IS NULL").
+		Where("drivers.deleted_at IS NULL AND drivers.license_expires_at <= ?", horizon).
+		Scan(&licenses).Error; err != nil {
+		return 0, fmt.Errorf("error listing expiring licenses: %w", err)
+	}
+	var insurances []expiring
+	if err := tx.Table("vehicles").
+		Select("vehicles.id AS subject_id, vehicles.user_id, users.email, users.first_name, vehicles.plate, vehicles.insurance_expires_at AS expires_at").
+		Joins("JOIN users ON users.id = vehicles.user_id AND users.deleted_at IS NULL").
+		Where("vehicles.deleted_at IS NULL AND vehicles.insurance_expires_at <= ?", horizon).
+		Scan(&insurances).Error; err != nil {
+		return 0, fmt.Errorf("error listing expiring insurances: %w", err)
+	}
+
+	sent := 0
+	for kind, items := range map[string][]expiring{
+		complianceModel.KindLicense:   licenses,
+		complianceModel.KindInsurance: insurances,
+	} {
+		for _, item := range items {
+			days, ok := complianceModel.TierFor(item.ExpiresAt, now)
+			if !ok {
+				continue
+			}
+			reminder := complianceModel.ExpiryReminder{
+				ID:         uuid.New(),
+				Kind:       kind,
+				SubjectId:  item.SubjectId,
+				ExpiresAt:  item.ExpiresAt,
+				DaysBefore: days,
+				UserId:     item.UserId,
+				CreatedAt:  now,
+			}
+			created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reminder)
+			if created.Error != nil {
+				return sent, fmt.Errorf("error recording reminder: %w", created.Error)
+			}
+			if created.RowsAffected == 0 {
+				continue
+			}
+
+			subject, body := reminderMessage(kind, item, days)
+			if err := s.Notifier.Notify(notifier.NotifyFuncParams{
+				Ctx:     ctx,
+				UserId:  item.UserId,
+				Email:   item.Email,
+				Subject: subject,
+				Body:    body,
+			}); err != nil {
+				logger.Log.Warn("No se pudo mandar el aviso de vencimiento",
+					zap.String("kind", kind),
+					zap.String("subject_id", item.SubjectId.String()),
+					zap.Error(err),
+				)
+				if err := tx.Delete(&reminder).Error; err != nil {
+					return sent, fmt.Errorf("error releasing reminder: %w", err)
+				}
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// flagDrivers marca como fuera de regla a los conductores con la licencia o el seguro del
+// vehículo activo vencidos (y los saca de servicio), y vuelve a poner en regla a los que
+// renovaron. Cada conductor se actualiza condicionado a su versión: si cambió mientras
+// tanto, se revisa en la próxima corrida.
+func flagDrivers(ctx context.Context, now time.Time) (flagged int, cleared int, err error) {
+	tx := db.DB.WithContext(ctx)
+	var rows []struct {
+		driverModel.Driver
+		InsuranceExpiresAt *time.Time
+	}
+	if err := tx.Model(&driverModel.Driver{}).
+		Select("drivers.*, vehicles.insurance_expires_at").
+		Joins("LEFT JOIN vehicles ON vehicles.id = drivers.active_vehicle_id AND vehicles.deleted_at IS NULL").
+		Where("drivers.deleted_at IS NULL").
+		Scan(&rows).Error; err != nil {
+		return 0, 0, fmt.Errorf("error listing drivers: %w", err)
+	}
+
+	for _, row := range rows {
+		driver := row.Driver
+		issues := driver.ComplianceIssuesAt(now, row.InsuranceExpiresAt)
+		compliant := len(issues) == 0
+		if compliant == driver.Compliant && slices.Equal(issues, driver.ComplianceIssues) {
+			continue
+		}
+
+		update := driverModel.Driver{
+			Compliant:        compliant,
+			ComplianceIssues: issues,
+			OnDuty:           driver.OnDuty && compliant,
+			DutyChangedAt:    driver.DutyChangedAt,
+		}
+		update.Version = driver.Version + 1
+		update.UpdatedAt = now
+		if driver.OnDuty && !compliant {
+			update.DutyChangedAt = &now
+		}
+		// Select para escribir los valores en cero y pasar ComplianceIssues por el serializer
+		result := tx.Model(&driver).
+			Where("version = ?", driver.Version).
+			Select("compliant", "compliance_issues", "on_duty", "duty_changed_at", "version", "updated_at").
+			Updates(&update)
+		if result.Error != nil {
+			return flagged, cleared, fmt.Errorf("error updating driver compliance: %w", result.Error)
+		}
+		if result.RowsAffected == 0 || compliant == driver.Compliant {
+			continue
+		}
+		if compliant {
+			cleared++
+		} else {
+			flagged++
+		}
+	}
+	return flagged, cleared, nil
+}
+
+// reminderMessage arma el asunto y el cuerpo del aviso.
+func reminderMessage(kind string, item expiring, days int) (string, string) {
+	what := "driver license"
+	if kind == complianceModel.KindInsurance {
+		what = fmt.Sprintf("insurance of the vehicle %s", item.Plate)
+	}
+	date := item.ExpiresAt.Format("2006-01-02")
+	if days == 0 {
+		return fmt.Sprintf("Your %s has expired", what),
+			fmt.Sprintf("Hi %s,\n\nYour %s expired on %s. You can not go on duty until you renew it and update it in Piloto de Tormenta.",
+				item.FirstName, what, date)
+	}
+	return fmt.Sprintf("Your %s expires in %d day(s)", what, days),
+		fmt.Sprintf("Hi %s,\n\nYour %s expires on %s. Renew it and update it in Piloto de Tormenta to keep driving without interruptions.",
+			item.FirstName, what, date)
+}
+
+// /------ structs ------///
+
+// CheckResult resume una corrida del job de vencimientos.
+type CheckResult struct {
+	RemindersSent  int `json:"reminders_sent"`
+	DriversFlagged int `json:"drivers_flagged"`
+	DriversCleared int `json:"drivers_cleared"`
+}
+
+// expiring es una licencia o un seguro próximo a vencer con los datos del dueño.
+type expiring struct {
+	SubjectId uuid.UUID
+	UserId    uuid.UUID
+	Email     string
+	FirstName string
+	Plate     string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,355 @@
+package driverHandler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	m "github.com/aragornz325/piloto-api/internal/driver/model"
+	driverService "github.com/aragornz325/piloto-api/internal/driver/service"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type DriverHandler struct {
+	DriverService driverService.DriverService
+}
+
+func NewDriverHandler(driverService driverService.DriverService) *DriverHandler {
+	return &DriverHandler{
+		DriverService: driverService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Register as driver
+// @Description Start the driver onboarding of a user with the license data. The driver stays pending until the license, ID and insurance documents are approved by an admin.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body driverModel.LicenseDTO true "License data"
+// @Success 201 {object} driverModel.Driver
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/driver [post]
+func (h *DriverHandler) RegisterDriverHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.LicenseDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.DriverService.RegisterDriver(driverService.RegisterDriverFuncParams{
+		Ctx:     c.Request.Context(),
+		UserId:  userId,
+		License: licenseFromDTO(payload),
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Summary Get driver
+// @Description Get the driver record of a user with its license data, status and documents
+// @Tags drivers
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} driverModel.Driver
+// @Header 200 {string} ETag "Current version of the driver"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/driver [get]
+func (h *DriverHandler) GetDriverHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	result, err := h.DriverService.GetDriver(driverService.GetDriverFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Update driver license
+// @Description Replace the license data of a driver. The license document has to be submitted and approved again, so the driver goes back to pending.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the driver being updated"
+// @Param input body driverModel.LicenseDTO true "License data"
+// @Success 200 {object} driverModel.Driver
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Router /users/{id}/driver/license [put]
+func (h *DriverHandler) UpdateLicenseHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var payload m.LicenseDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.DriverService.UpdateLicense(driverService.UpdateLicenseFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		License:         licenseFromDTO(payload),
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Submit driver document
+// @Description Upload a driver document (license, id or insurance) for admin review. PDF, JPEG or PNG up to 10MB. A previous document of the same type still waiting for review is superseded. The file is kept in private storage and is only available through GET /drivers/documents/{documentId}/file.
+// @Tags drivers
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "User ID"
+// @Param type formData string true "Document type (license, id, insurance)"
+// @Param file formData file true "Document file"
+// @Success 201 {object} driverModel.DriverDocument
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Router /users/{id}/driver/documents [post]
+func (h *DriverHandler) SubmitDocumentHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	// Margen para los headers del multipart por encima del tamaño máximo del archivo
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, driverService.MaxDocumentBytes+(1<<20))
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "document file is required"})
+		return
+	}
+	if fileHeader.Size > driverService.MaxDocumentBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "document is too large"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, driverService.MaxDocumentBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.DriverService.SubmitDocument(driverService.SubmitDocumentFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+		Type:   c.PostForm("type"),
+		Data:   data,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Summary List drivers
+// @Description List the drivers, oldest first, optionally filtered by status (pending, approved)
+// @Tags drivers
+// @Produce json
+// @Param status query string false "Driver status"
+// @Success 200 {array} driverModel.Driver
+// @Router /drivers [get]
+func (h *DriverHandler) ListDriversHandler(c *gin.Context) {
+	result, err := h.DriverService.ListDrivers(driverService.ListDriversFuncParams{
+		Ctx:    c.Request.Context(),
+		Status: c.Query("status"),
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary List driver documents
+// @Description List the driver documents with the given status, oldest first. Defaults to the submitted documents waiting for review.
+// @Tags drivers
+// @Produce json
+// @Param status query string false "Document status (submitted, approved, rejected, superseded)"
+// @Success 200 {array} driverModel.DriverDocument
+// @Router /drivers/documents [get]
+func (h *DriverHandler) ListDocumentsHandler(c *gin.Context) {
+	result, err := h.DriverService.ListDocuments(driverService.ListDocumentsFuncParams{
+		Ctx:    c.Request.Context(),
+		Status: c.Query("status"),
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Approve driver document
+// @Description Approve a submitted document. When every required document is approved and the license is not expired, the user becomes a driver.
+// @Tags drivers
+// @Produce json
+// @Param documentId path string true "Document ID"
+// @Success 200 {object} driverModel.DriverDocument
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /drivers/documents/{documentId}/approve [post]
+func (h *DriverHandler) ApproveDocumentHandler(c *gin.Context) {
+	h.reviewDocument(c, true, "")
+}
+
+// @Summary Reject driver document
+// @Description Reject a submitted document with a reason shown to the driver, who can submit a new one.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param documentId path string true "Document ID"
+// @Param input body driverModel.RejectDocumentDTO true "Rejection reason"
+// @Success 200 {object} driverModel.DriverDocument
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /drivers/documents/{documentId}/reject [post]
+func (h *DriverHandler) RejectDocumentHandler(c *gin.Context) {
+	var payload m.RejectDocumentDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+	h.reviewDocument(c, false, *payload.Reason)
+}
+
+// @Summary Download driver document
+// @Description Download the file of a driver document. Only the driver who submitted it and admins can download it; for anyone else the document does not exist.
+// @Tags drivers
+// @Produce application/pdf,image/jpeg,image/png
+// @Param documentId path string true "Document ID"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /drivers/documents/{documentId}/file [get]
+func (h *DriverHandler) DownloadDocumentHandler(c *gin.Context) {
+	documentId, err := uuid.Parse(c.Param("documentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid document ID"})
+		return
+	}
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Unauthorized"})
+		return
+	}
+
+	document, data, err := h.DriverService.GetDocumentFile(driverService.GetDocumentFileFuncParams{
+		Ctx:        c.Request.Context(),
+		DocumentId: documentId,
+		UserId:     principal.UserId,
+		Admin:      principal.Role == userModel.RoleAdmin,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Son documentos personales: que no queden en caches intermedias
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(document.FileKey)))
+	c.Data(http.StatusOK, document.ContentType, data)
+}
+
+func (h *DriverHandler) reviewDocument(c *gin.Context, approve bool, reason string) {
+	documentId, err := uuid.Parse(c.Param("documentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid document ID"})
+		return
+	}
+	var reviewerId *uuid.UUID
+	if principal, ok := requestctx.PrincipalFrom(c.Request.Context()); ok {
+		reviewerId = &principal.UserId
+	}
+
+	result, err := h.DriverService.ReviewDocument(driverService.ReviewDocumentFuncParams{
+		Ctx:        c.Request.Context(),
+		DocumentId: documentId,
+		Approve:    approve,
+		Reason:     reason,
+		ReviewerId: reviewerId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func licenseFromDTO(payload m.LicenseDTO) driverService.License {
+	return driverService.License{
+		Number:    *payload.LicenseNumber,
+		Class:     *payload.LicenseClass,
+		ExpiresAt: *payload.LicenseExpiresAt,
+	}
+}
@@ -0,0 +1,122 @@
+package driverModel
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+const (
+	// StatusPending es un conductor con documentación incompleta o en revisión.
+	StatusPending = "pending"
+	// StatusApproved es un conductor con todos los documentos aprobados y la licencia vigente.
+	StatusApproved = "approved"
+)
+
+const (
+	DocumentSubmitted = "submitted"
+	DocumentApproved  = "approved"
+	DocumentRejected  = "rejected"
+	// DocumentSuperseded es un documento reemplazado por uno más nuevo del mismo tipo o
+	// invalidado porque cambiaron los datos de la licencia.
+	DocumentSuperseded = "superseded"
+)
+
+const (
+	DocumentLicense   = "license"
+	DocumentID        = "id"
+	DocumentInsurance = "insurance"
+)
+
+// RequiredDocuments son los tipos de documento que tienen que estar aprobados para que el
+// conductor quede aprobado.
+var RequiredDocuments = []string{DocumentLicense, DocumentID, DocumentInsurance}
+
+// LicenseClassPattern admite clases como "B1", "D2" o "A.2.1".
+var LicenseClassPattern = regexp.MustCompile(`^[A-G](\.?[0-9]){0,2}$`)
+
+// Driver es el alta de un usuario como conductor. User.Driver refleja si está aprobado.
+type Driver struct {
+	baseModel.BaseModel
+	UserId           uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	LicenseNumber    string            `gorm:"not null;uniqueIndex" json:"license_number"`
+	LicenseClass     string            `gorm:"not null" json:"license_class"`
+	LicenseExpiresAt time.Time         `gorm:"not null;index" json:"license_expires_at"`
+	Status           string            `gorm:"index;not null;default:pending" json:"status"`
+	ApprovedAt       *time.Time        `json:"approved_at,omitempty"`
+	// ActiveVehicleId es el vehículo verificado que el conductor eligió para operar.
+	ActiveVehicleId  *uuid.UUID        `gorm:"type:uuid" json:"active_vehicle_id,omitempty"`
+	// OnDuty es el estado de servicio que activa el conductor; solo un conductor aprobado
+	// puede estar en servicio.
+	OnDuty           bool              `gorm:"index;not null;default:false" json:"on_duty"`
+	DutyChangedAt    *time.Time        `json:"duty_changed_at,omitempty"`
+	// Compliant es falso cuando venció la licencia o el seguro del vehículo activo: el
+	// conductor sale de servicio hasta renovarlos. ComplianceIssues dice qué venció.
+	Compliant        bool              `gorm:"not null;default:true" json:"compliant"`
+	ComplianceIssues []string          `gorm:"type:jsonb;serializer:json" json:"compliance_issues,omitempty"`
+	Documents        []*DriverDocument `gorm:"foreignKey:DriverId" json:"documents,omitempty"`
+}
+
+// Motivos por los que un conductor deja de estar en regla.
+const (
+	IssueLicenseExpired   = "license_expired"
+	IssueInsuranceExpired = "insurance_expired"
+)
+
+// ComplianceIssuesAt devuelve lo que tiene vencido el conductor en now, dado el vencimiento
+// del seguro de su vehículo activo (nil si no tiene uno).
+func (d *Driver) ComplianceIssuesAt(now time.Time, insuranceExpiresAt *time.Time) []string {
+	issues := []string{}
+	if d.LicenseExpired(now) {
+		issues = append(issues, IssueLicenseExpired)
+	}
+	if insuranceExpiresAt != nil && !now.Before(*insuranceExpiresAt) {
+		issues = append(issues, IssueInsuranceExpired)
+	}
+	return issues
+}
+
+// LicenseExpired indica si la licencia está vencida en now.
+func (d *Driver) LicenseExpired(now time.Time) bool {
+	return !now.Before(d.LicenseExpiresAt)
+}
+
+// DriverDocument es un archivo presentado por el conductor para que un admin lo revise.
+// El archivo vive en el storage privado bajo FileKey y se descarga con
+// GET /drivers/documents/{documentId}/file. URL es la URL pública que tenían los
+// documentos subidos antes de eso; queda vacía cuando se mueven al storage privado.
+type DriverDocument struct {
+	baseModel.BaseModel
+	DriverId        uuid.UUID  `gorm:"type:uuid;not null;index" json:"driver_id"`
+	Type            string     `gorm:"index;not null" json:"type"`
+	Status          string     `gorm:"index;not null;default:submitted" json:"status"`
+	FileKey         string     `gorm:"not null" json:"-"`
+	URL             string     `json:"-"`
+	ContentType     string     `json:"content_type"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	ReviewedBy      *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// IsDocumentType indica si value es un tipo de documento aceptado.
+func IsDocumentType(value string) bool {
+	for _, documentType := range RequiredDocuments {
+		if value == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// LicenseDTO son los datos de la licencia; la fecha de vencimiento es "YYYY-MM-DD".
+type LicenseDTO struct {
+	LicenseNumber    *string `json:"license_number" binding:"required"`
+	LicenseClass     *string `json:"license_class" binding:"required"`
+	LicenseExpiresAt *string `json:"license_expires_at" binding:"required"`
+}
+
+type RejectDocumentDTO struct {
+	Reason *string `json:"reason" binding:"required,min=3,max=500"`
+}
@@ -0,0 +1,764 @@
+package driverService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	driverModel "github.com/aragornz325/piloto-api/internal/driver/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/imaging"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/storage"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Package driverService maneja el alta de conductores: los datos de la licencia, los
+// documentos presentados y su revisión por parte de los admins. Un usuario solo es
+// conductor (User.Driver) mientras su alta está aprobada.
+
+// MaxDocumentBytes es el tamaño máximo aceptado para un documento subido.
+const MaxDocumentBytes = 10 << 20
+
+// documentExtensions son los tipos de archivo aceptados para los documentos, detectados por contenido.
+var documentExtensions = map[string]string{
+	"application/pdf": "pdf",
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+}
+
+type DriverService interface {
+	RegisterDriver(RegisterDriverFuncParams) (*driverModel.Driver, error)
+	GetDriver(GetDriverFuncParams) (*driverModel.Driver, error)
+	UpdateLicense(UpdateLicenseFuncParams) (*driverModel.Driver, error)
+	SubmitDocument(SubmitDocumentFuncParams) (*driverModel.DriverDocument, error)
+	ListDrivers(ListDriversFuncParams) ([]*driverModel.Driver, error)
+	ListDocuments(ListDocumentsFuncParams) ([]*driverModel.DriverDocument, error)
+	ReviewDocument(ReviewDocumentFuncParams) (*driverModel.DriverDocument, error)
+	GetDocumentFile(GetDocumentFileFuncParams) (*driverModel.DriverDocument, []byte, error)
+	MoveLegacyDocuments(MoveLegacyDocumentsFuncParams) (int, error)
+}
+
+type driverService struct {
+	// Storage es el storage privado: los documentos no se sirven públicamente y se
+	// descargan con GetDocumentFile.
+	Storage storage.BlobStorage
+}
+
+func NewDriverService(storage storage.BlobStorage) DriverService {
+	return &driverService{
+		Storage: storage,
+	}
+}
+
+// RegisterDriver creates the driver record of a user with its license data. The driver
+// starts as pending until every required document is approved.
+//
+// Parameters:
+//   - opts: RegisterDriverFuncParams containing the context, the user ID and the license data.
+//
+// Returns:
+//   - *driverModel.Driver: The created driver.
+//   - error: A 400 for invalid license data, or 409 if the user is already registered or the license is in use.
+func (s *driverService) RegisterDriver(opts RegisterDriverFuncParams) (*driverModel.Driver, error) {
+	var driver driverModel.Driver
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "RegisterDriver",
+		ServiceName: "driver",
+		Operation: func() error {
+			license, err := normalizeLicense(opts.License)
+			if err != nil {
+				return err
+			}
+
+			var user userModel.User
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("is_active = ?", true).
+				First(&user, opts.UserId).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "user not found",
+					})
+				}
+				return fmt.Errorf("error getting user: %w", err)
+			}
+
+			driver = driverModel.Driver{
+				UserId:           opts.UserId,
+				LicenseNumber:    license.Number,
+				LicenseClass:     license.Class,
+				LicenseExpiresAt: license.ExpiresAt,
+				Status:           driverModel.StatusPending,
+			}
+			if err := db.DB.WithContext(opts.Ctx).Create(&driver).Error; err != nil {
+				if db.IsUniqueViolation(err) {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "user is already registered as a driver or the license is in use",
+					})
+				}
+				return fmt.Errorf("error creating driver: %w", err)
+			}
+			driver.Documents = []*driverModel.DriverDocument{}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// GetDriver returns the driver record of a user with its documents, newest first.
+//
+// Parameters:
+//   - opts: GetDriverFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - *driverModel.Driver: The driver with its documents.
+//   - error: A 404 if the user is not registered as a driver.
+func (s *driverService) GetDriver(opts GetDriverFuncParams) (*driverModel.Driver, error) {
+	var driver driverModel.Driver
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetDriver",
+		ServiceName: "driver",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Preload("Documents", func(tx *gorm.DB) *gorm.DB {
+					return tx.Order("created_at DESC")
+				}).
+				Where("user_id = ?", opts.UserId).
+				First(&driver).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "driver not found",
+					})
+				}
+				return fmt.Errorf("error getting driver: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// UpdateLicense replaces the license data of a driver. The license document has to be
+// reviewed again, so the approved and pending license documents are superseded and the
+// driver goes back to pending until a new one is approved.
+// The write only succeeds if the stored version matches opts.ExpectedVersion.
+//
+// Parameters:
+//   - opts: UpdateLicenseFuncParams containing the context, the user ID, the license data and the expected version.
+//
+// Returns:
+//   - *driverModel.Driver: The updated driver.
+//   - error: A 400 for invalid license data, 404 if not registered, 409 if the license is in use or 412 on a version mismatch.
+func (s *driverService) UpdateLicense(opts UpdateLicenseFuncParams) (*driverModel.Driver, error) {
+	var driver driverModel.Driver
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "UpdateLicense",
+		ServiceName: "driver",
+		Operation: func() error {
+			license, err := normalizeLicense(opts.License)
+			if err != nil {
+				return err
+			}
+
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := lockDriver(tx, "user_id = ?", opts.UserId, &driver); err != nil {
+					return err
+				}
+				if driver.Version != opts.ExpectedVersion {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "driver was modified by another request",
+					})
+				}
+
+				now := time.Now().UTC()
+				if err := tx.Model(&driver).Updates(map[string]interface{}{
+					"license_number":     license.Number,
+					"license_class":      license.Class,
+					"license_expires_at": license.ExpiresAt,
+					"version":            driver.Version + 1,
+					"updated_at":         now,
+				}).Error; err != nil {
+					if db.IsUniqueViolation(err) {
+						return errors.NewConflict(errors.SimpleErrorFuncOptions{
+							Message: "the license is already registered by another driver",
+						})
+					}
+					return fmt.Errorf("error updating license: %w", err)
+				}
+				driver.LicenseNumber = license.Number
+				driver.LicenseClass = license.Class
+				driver.LicenseExpiresAt = license.ExpiresAt
+				driver.Version++
+				driver.UpdatedAt = now
+
+				if err := supersedeDocuments(tx, driver.ID, driverModel.DocumentLicense,
+					driverModel.DocumentSubmitted, driverModel.DocumentApproved); err != nil {
+					return err
+				}
+				return evaluateDriver(tx, &driver, now)
+			})
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// SubmitDocument stores a document (PDF, JPEG or PNG) for review. A previous document of
+// the same type that was still waiting for review is superseded; an approved one stays
+// valid until the new one is approved.
+//
+// Parameters:
+//   - opts: SubmitDocumentFuncParams containing the context, the user ID, the document type and the raw file.
+//
+// Returns:
+//   - *driverModel.DriverDocument: The submitted document.
+//   - error: A 400 for an unknown type or unsupported file, or 404 if the user is not registered as a driver.
+func (s *driverService) SubmitDocument(opts SubmitDocumentFuncParams) (*driverModel.DriverDocument, error) {
+	var document driverModel.DriverDocument
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "SubmitDocument",
+		ServiceName: "driver",
+		Operation: func() error {
+			if !driverModel.IsDocumentType(opts.Type) {
+				return errors.NewValidation(errors.ValidationErrorFuncOptions{
+					Message: "invalid document",
+					Fields:  map[string]string{"type": "must be one of " + strings.Join(driverModel.RequiredDocuments, ", ")},
+				})
+			}
+			if len(opts.Data) == 0 || len(opts.Data) > MaxDocumentBytes {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: fmt.Sprintf("document must be between 1 byte and %d bytes", MaxDocumentBytes),
+				})
+			}
+			contentType := imaging.DetectContentType(opts.Data)
+			extension, ok := documentExtensions[contentType]
+			if !ok {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: "document must be a PDF, JPEG or PNG file",
+				})
+			}
+
+			driver, err := s.GetDriver(GetDriverFuncParams{Ctx: opts.Ctx, UserId: opts.UserId})
+			if err != nil {
+				return err
+			}
+
+			key := fmt.Sprintf("drivers/%s/%s-%s.%s", opts.UserId, opts.Type, uuid.NewString(), extension)
+			if _, err := s.Storage.Put(storage.PutObjectFuncParams{
+				Ctx:         opts.Ctx,
+				Key:         key,
+				ContentType: contentType,
+				Data:        opts.Data,
+			}); err != nil {
+				return fmt.Errorf("error storing document: %w", err)
+			}
+
+			document = driverModel.DriverDocument{
+				DriverId:    driver.ID,
+				Type:        opts.Type,
+				Status:      driverModel.DocumentSubmitted,
+				FileKey:     key,
+				ContentType: contentType,
+			}
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := supersedeDocuments(tx, driver.ID, opts.Type, driverModel.DocumentSubmitted); err != nil {
+					return err
+				}
+				if err := tx.Create(&document).Error; err != nil {
+					return fmt.Errorf("error creating document: %w", err)
+				}
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// ListDrivers returns the drivers, optionally filtered by status, oldest first.
+//
+// Parameters:
+//   - opts: ListDriversFuncParams containing the context and the optional status.
+//
+// Returns:
+//   - []*driverModel.Driver: The drivers.
+//   - error: An error if the query fails.
+func (s *driverService) ListDrivers(opts ListDriversFuncParams) ([]*driverModel.Driver, error) {
+	drivers := []*driverModel.Driver{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListDrivers",
+		ServiceName: "driver",
+		Operation: func() error {
+			tx := db.DB.WithContext(opts.Ctx).Order("created_at ASC")
+			if opts.Status != "" {
+				tx = tx.Where("status = ?", opts.Status)
+			}
+			if err := tx.Find(&drivers).Error; err != nil {
+				return fmt.Errorf("error listing drivers: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return drivers, nil
+}
+
+// ListDocuments returns the documents with the given status (submitted by default), oldest
+// first, which is the review queue for admins.
+//
+// Parameters:
+//   - opts: ListDocumentsFuncParams containing the context and the optional status.
+//
+// Returns:
+//   - []*driverModel.DriverDocument: The documents.
+//   - error: An error if the query fails.
+func (s *driverService) ListDocuments(opts ListDocumentsFuncParams) ([]*driverModel.DriverDocument, error) {
+	documents := []*driverModel.DriverDocument{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListDocuments",
+		ServiceName: "driver",
+		Operation: func() error {
+			status := opts.Status
+			if status == "" {
+				status = driverModel.DocumentSubmitted
+			}
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("status = ?", status).
+				Order("created_at ASC").
+				Find(&documents).Error; err != nil {
+				return fmt.Errorf("error listing documents: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// ReviewDocument approves or rejects a submitted document. Rejections require a reason.
+// Approving a document supersedes the previously approved one of the same type. After the
+// review the driver status is evaluated again and User.Driver is updated to match.
+//
+// Parameters:
+//   - opts: ReviewDocumentFuncParams containing the context, the document ID, the decision, the reason and the reviewer.
+//
+// Returns:
+//   - *driverModel.DriverDocument: The reviewed document.
+//   - error: A 400 if a rejection has no reason, 404 if not found or 409 if the document is not waiting for review.
+func (s *driverService) ReviewDocument(opts ReviewDocumentFuncParams) (*driverModel.DriverDocument, error) {
+	var document driverModel.DriverDocument
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ReviewDocument",
+		ServiceName: "driver",
+		Operation: func() error {
+			reason := strings.TrimSpace(opts.Reason)
+			if !opts.Approve && reason == "" {
+				return errors.NewValidation(errors.ValidationErrorFuncOptions{
+					Message: "invalid review",
+					Fields:  map[string]string{"reason": "is required to reject a document"},
+				})
+			}
+
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Where("id = ?", opts.DocumentId).First(&document).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "document not found",
+						})
+					}
+					return fmt.Errorf("error getting document: %w", err)
+				}
+				// Se bloquea el conductor para serializar las revisiones de sus documentos
+				var driver driverModel.Driver
+				if err := lockDriver(tx, "id = ?", document.DriverId, &driver); err != nil {
+					return err
+				}
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&document, document.ID).Error; err != nil {
+					return fmt.Errorf("error getting document: %w", err)
+				}
+				if document.Status != driverModel.DocumentSubmitted {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: fmt.Sprintf("document is %s, only submitted documents can be reviewed", document.Status),
+					})
+				}
+
+				now := time.Now().UTC()
+				status := driverModel.DocumentRejected
+				if opts.Approve {
+					status = driverModel.DocumentApproved
+					reason = ""
+					if err := supersedeDocuments(tx, driver.ID, document.Type, driverModel.DocumentApproved); err != nil {
+						return err
+					}
+				}
+				if err := tx.Model(&document).Updates(map[string]interface{}{
+					"status":           status,
+					"rejection_reason": reason,
+					"reviewed_by":      opts.ReviewerId,
+					"reviewed_at":      now,
+					"version":          document.Version + 1,
+					"updated_at":       now,
+				}).Error; err != nil {
+					return fmt.Errorf("error reviewing document: %w", err)
+				}
+				document.Status = status
+				document.RejectionReason = reason
+				document.ReviewedBy = opts.ReviewerId
+				document.ReviewedAt = &now
+				document.Version++
+				document.UpdatedAt = now
+
+				return evaluateDriver(tx, &driver, now)
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// GetDocumentFile returns a document with its file, read from the private storage. Only
+// the driver who submitted it and admins can get it.
+//
+// Parameters:
+//   - opts: GetDocumentFileFuncParams containing the context, the document ID and the requesting user.
+//
+// Returns:
+//   - *driverModel.DriverDocument: The document.
+//   - []byte: The file contents.
+//   - error: A 404 if the document does not exist, belongs to another driver or its file is missing.
+func (s *driverService) GetDocumentFile(opts GetDocumentFileFuncParams) (*driverModel.DriverDocument, []byte, error) {
+	var document driverModel.DriverDocument
+	var data []byte
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetDocumentFile",
+		ServiceName: "driver",
+		Operation: func() error {
+			notFound := errors.NewNotFound(errors.SimpleErrorFuncOptions{
+				Message: "document not found",
+			})
+			query := db.DB.WithContext(opts.Ctx).
+				Joins("JOIN drivers ON drivers.id = driver_documents.driver_id AND drivers.deleted_at IS NULL").
+				Where("driver_documents.id = ?", opts.DocumentId)
+			// A quien no es admin un documento ajeno le da 404, igual que uno inexistente
+			if !opts.Admin {
+				query = query.Where("drivers.user_id = ?", opts.UserId)
+			}
+			if err := query.First(&document).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return notFound
+				}
+				return fmt.Errorf("error getting document: %w", err)
+			}
+
+			var err error
+			data, err = s.Storage.Get(storage.GetObjectFuncParams{Ctx: opts.Ctx, Key: document.FileKey})
+			if err != nil {
+				if stderrors.Is(err, storage.ErrNotFound) {
+					return notFound
+				}
+				return fmt.Errorf("error reading document: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &document, data, nil
+}
+
+// MoveLegacyDocuments moves to the private storage the documents submitted when they were
+// stored in the public one (the ones that still have a URL), deletes the public copy and
+// clears the URL. It is idempotent and a document that fails is retried on the next run.
+//
+// Parameters:
+//   - opts: MoveLegacyDocumentsFuncParams containing the context and the public storage.
+//
+// Returns:
+//   - int: How many documents were moved.
+//   - error: An error if the documents cannot be listed.
+func (s *driverService) MoveLegacyDocuments(opts MoveLegacyDocumentsFuncParams) (int, error) {
+	moved := 0
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "MoveLegacyDocuments",
+		ServiceName: "driver",
+		Operation: func() error {
+			var documents []*driverModel.DriverDocument
+			if err := db.DB.WithContext(opts.Ctx).
+				Unscoped().
+				Where("url IS NOT NULL AND url <> ''").
+				Find(&documents).Error; err != nil {
+				return fmt.Errorf("error listing legacy documents: %w", err)
+			}
+			for _, document := range documents {
+				if err := s.moveDocument(opts.Ctx, opts.From, document); err != nil {
+					logger.Log.Warn("No se pudo mover el documento al storage privado",
+						zap.String("document_id", document.ID.String()),
+						zap.Error(err),
+					)
+					continue
+				}
+				moved++
+			}
+			return nil
+		},
+	})
+	return moved, err
+}
+
+// moveDocument copia el archivo al storage privado, limpia la URL y recién después borra
+// la copia pública. Si el archivo público ya no existe solo se limpia la URL.
+func (s *driverService) moveDocument(ctx context.Context, from storage.BlobStorage, document *driverModel.DriverDocument) error {
+	data, err := from.Get(storage.GetObjectFuncParams{Ctx: ctx, Key: document.FileKey})
+	switch {
+	case stderrors.Is(err, storage.ErrNotFound):
+	case err != nil:
+		return err
+	default:
+		if _, err := s.Storage.Put(storage.PutObjectFuncParams{
+			Ctx:         ctx,
+			Key:         document.FileKey,
+			ContentType: document.ContentType,
+			Data:        data,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := db.DB.WithContext(ctx).Model(document).Update("url", "").Error; err != nil {
+		return err
+	}
+	return from.Delete(storage.DeleteObjectFuncParams{Ctx: ctx, Key: document.FileKey})
+}
+
+// evaluateDriver recalcula el estado del conductor: queda aprobado si la licencia está
+// vigente y cada documento requerido tiene una versión aprobada. Si el estado cambia se
+// actualiza también User.Driver, que es lo que el resto de la API usa para saber si un
+// usuario es conductor.
+func evaluateDriver(tx *gorm.DB, driver *driverModel.Driver, now time.Time) error {
+	var approvedTypes []string
+	if err := tx.Model(&driverModel.DriverDocument{}).
+		Where("driver_id = ? AND status = ?", driver.ID, driverModel.DocumentApproved).
+		Distinct().
+		Pluck("type", &approvedTypes).Error; err != nil {
+		return fmt.Errorf("error getting approved documents: %w", err)
+	}
+	approved := map[string]bool{}
+	for _, documentType := range approvedTypes {
+		approved[documentType] = true
+	}
+
+	status := driverModel.StatusApproved
+	if driver.LicenseExpired(now) {
+		status = driverModel.StatusPending
+	}
+	for _, documentType := range driverModel.RequiredDocuments {
+		if !approved[documentType] {
+			status = driverModel.StatusPending
+		}
+	}
+	if status == driver.Status {
+		return nil
+	}
+
+	changes := map[string]interface{}{
+		"status":     status,
+		"version":    driver.Version + 1,
+		"updated_at": now,
+	}
+	if status == driverModel.StatusApproved {
+		changes["approved_at"] = now
+	} else if driver.OnDuty {
+		// Un conductor que deja de estar aprobado sale de servicio
+		changes["on_duty"] = false
+		changes["duty_changed_at"] = now
+	}
+	if err := tx.Model(driver).Updates(changes).Error; err != nil {
+		return fmt.Errorf("error updating driver status: %w", err)
+	}
+	driver.Status = status
+	driver.Version++
+	driver.UpdatedAt = now
+	if status == driverModel.StatusApproved {
+		driver.ApprovedAt = &now
+	} else if driver.OnDuty {
+		driver.OnDuty = false
+		driver.DutyChangedAt = &now
+	}
+
+	if err := tx.Model(&userModel.User{}).
+		Where("id = ?", driver.UserId).
+		Updates(map[string]interface{}{
+			"driver":     status == driverModel.StatusApproved,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": now,
+		}).Error; err != nil {
+		return fmt.Errorf("error updating user driver flag: %w", err)
+	}
+	return nil
+}
+
+// supersedeDocuments marca como reemplazados los documentos del tipo indicado que están en
+// alguno de los estados dados.
+func supersedeDocuments(tx *gorm.DB, driverId uuid.UUID, documentType string, statuses ...string) error {
+	if err := tx.Model(&driverModel.DriverDocument{}).
+		Where("driver_id = ? AND type = ? AND status IN ?", driverId, documentType, statuses).
+		Updates(map[string]interface{}{
+			"status":     driverModel.DocumentSuperseded,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now().UTC(),
+		}).Error; err != nil {
+		return fmt.Errorf("error superseding documents: %w", err)
+	}
+	return nil
+}
+
+func lockDriver(tx *gorm.DB, query string, value interface{}, driver *driverModel.Driver) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(query, value).First(driver).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+				Message: "driver not found",
+			})
+		}
+		return fmt.Errorf("error getting driver: %w", err)
+	}
+	return nil
+}
+
+type license struct {
+	Number    string
+	Class     string
+	ExpiresAt time.Time
+}
+
+// normalizeLicense valida los datos de la licencia: número alfanumérico, clase conocida y
+// vencimiento futuro. Los errores se devuelven juntos por campo.
+func normalizeLicense(input License) (license, error) {
+	fields := map[string]string{}
+	result := license{
+		Number: strings.ToUpper(strings.Join(strings.FieldsFunc(input.Number, func(r rune) bool {
+			return r == ' ' || r == '-' || r == '.'
+		}), "")),
+		Class: strings.ToUpper(strings.TrimSpace(input.Class)),
+	}
+	if len(result.Number) < 4 || len(result.Number) > 20 || strings.IndexFunc(result.Number, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) >= 0 {
+		fields["license_number"] = "must be 4 to 20 letters or digits"
+	}
+	if !driverModel.LicenseClassPattern.MatchString(result.Class) {
+		fields["license_class"] = "must be a license class like B1, D2 or A.2.1"
+	}
+	expiresAt, err := time.Parse("2006-01-02", strings.TrimSpace(input.ExpiresAt))
+	if err != nil {
+		fields["license_expires_at"] = "must be a date in YYYY-MM-DD format"
+	} else if !expiresAt.After(time.Now().UTC()) {
+		fields["license_expires_at"] = "license is expired"
+	}
+	result.ExpiresAt = expiresAt
+
+	if len(fields) > 0 {
+		return license{}, errors.NewValidation(errors.ValidationErrorFuncOptions{
+			Message: "invalid license",
+			Fields:  fields,
+		})
+	}
+	return result, nil
+}
+
+// /------ structs ------///
+type License struct {
+	Number    string
+	Class     string
+	ExpiresAt string
+}
+
+type RegisterDriverFuncParams struct {
+	Ctx     context.Context
+	UserId  uuid.UUID
+	License License
+}
+
+type GetDriverFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+}
+
+type UpdateLicenseFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	License         License
+	ExpectedVersion int64
+}
+
+type SubmitDocumentFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+	Type   string
+	Data   []byte
+}
+
+type GetDocumentFileFuncParams struct {
+	Ctx        context.Context
+	DocumentId uuid.UUID
+	// UserId es quien pide el archivo; si no es Admin tiene que ser el conductor.
+	UserId uuid.UUID
+	Admin  bool
+}
+
+type MoveLegacyDocumentsFuncParams struct {
+	Ctx context.Context
+	// From es el storage público donde quedaron los documentos viejos.
+	From storage.BlobStorage
+}
+
+type ListDriversFuncParams struct {
+	Ctx    context.Context
+	Status string
+}
+
+type ListDocumentsFuncParams struct {
+	Ctx    context.Context
+	Status string
+}
+
+type ReviewDocumentFuncParams struct {
+	Ctx        context.Context
+	DocumentId uuid.UUID
+	Approve    bool
+	Reason     string
+	ReviewerId *uuid.UUID
+}
@@ -0,0 +1,50 @@
+package healthHandler
+
+import (
+	"net/http"
+
+	"github.com/aragornz325/piloto-api/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+type StatusResponse struct {
+	Status string `json:"status"`
+}
+
+type HealthHandler struct {
+	Registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		Registry: registry,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Liveness
+// @Description Answer 200 while the process is up and serving requests. It does not check dependencies: use it to decide when to restart the process, and /readyz to decide when to send it traffic.
+// @Tags health
+// @Produce json
+// @Success 200 {object} StatusResponse
+// @Router /healthz [get]
+func (h *HealthHandler) LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, StatusResponse{Status: health.StatusOK})
+}
+
+// @Summary Readiness
+// @Description Run the readiness checks (database ping, migrations applied, and the storage and mailer when they support it), each with a 2 second timeout, and report the status and duration of each one. The error details are only logged, not returned. Answers 503 when any check fails or the server is shutting down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) ReadinessHandler(c *gin.Context) {
+	report := h.Registry.Run(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,97 @@
+package historyHandler
+
+import (
+	"net/http"
+
+	historyService "github.com/aragornz325/piloto-api/internal/history/service"
+	profileService "github.com/aragornz325/piloto-api/internal/profile/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type HistoryHandler struct {
+	HistoryService historyService.HistoryService
+	ProfileService profileService.ProfileService
+}
+
+func NewHistoryHandler(historyService historyService.HistoryService, profileService profileService.ProfileService) *HistoryHandler {
+	return &HistoryHandler{
+		HistoryService: historyService,
+		ProfileService: profileService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Get user change history
+// @Description List the changes made to a user, newest first
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} history.EntityHistory
+// @Failure 400 {object} ErrorResponse
+// @Router /users/{id}/history [get]
+func (h *HistoryHandler) GetUserHistoryHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	entries, err := h.HistoryService.ListEntityHistory(historyService.ListEntityHistoryFuncParams{
+		Ctx:        c.Request.Context(),
+		EntityType: "User",
+		EntityId:   userId,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// @Summary Get profile change history
+// @Description List the changes made to the profile of a user, newest first. Only the owner and admins can see it, since it includes private fields.
+// @Tags profile
+// @Produce json
+// @Param id path string true "User ID owning the profile"
+// @Success 200 {array} history.EntityHistory
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /profile/{id}/history [get]
+func (h *HistoryHandler) GetProfileHistoryHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+
+	profile, err := h.ProfileService.GetUserProfile(profileService.GeProfileByUserIdFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	entries, err := h.HistoryService.ListEntityHistory(historyService.ListEntityHistoryFuncParams{
+		Ctx:        c.Request.Context(),
+		EntityType: "Profile",
+		EntityId:   profile.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,60 @@
+package historyService
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/history"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+)
+
+// HistoryService expone la lectura del historial de cambios que registran
+// los callbacks de pkg/history.
+type HistoryService interface {
+	ListEntityHistory(ListEntityHistoryFuncParams) ([]*history.EntityHistory, error)
+}
+
+type historyService struct{}
+
+func NewHistoryService() HistoryService {
+	return &historyService{}
+}
+
+// ListEntityHistory returns the change history of a single entity, newest first.
+//
+// Parameters:
+//   - opts: ListEntityHistoryFuncParams containing the context, the entity type (e.g. "User") and its ID.
+//
+// Returns:
+//   - []*history.EntityHistory: The history entries of the entity.
+//   - error: An error if the query fails, otherwise nil.
+func (s *historyService) ListEntityHistory(opts ListEntityHistoryFuncParams) ([]*history.EntityHistory, error) {
+	var entries []*history.EntityHistory
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListEntityHistory",
+		ServiceName: "history",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("entity_type = ? AND entity_id = ?", opts.EntityType, opts.EntityId).
+				Order("created_at DESC").
+				Find(&entries).Error; err != nil {
+				return fmt.Errorf("error listing entity history: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// /-------- structs ---------///
+type ListEntityHistoryFuncParams struct {
+	Ctx        context.Context
+	EntityType string
+	EntityId   uuid.UUID
+}
@@ -0,0 +1,222 @@
+package invitationHandler
+
+import (
+	"net/http"
+	"time"
+
+	m "github.com/aragornz325/piloto-api/internal/invitation/model"
+	invitationService "github.com/aragornz325/piloto-api/internal/invitation/service"
+	userPresenter "github.com/aragornz325/piloto-api/internal/user/presenter"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type InvitationHandler struct {
+	InvitationService invitationService.InvitationService
+}
+
+func NewInvitationHandler(invitationService invitationService.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		InvitationService: invitationService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Invite a user
+// @Description Invite a user by email with preassigned roles. The driver flag marks invitees expected to complete the driver onboarding after accepting; it does not make them drivers, only an approved driver registration does. The user is created when the invitation is accepted.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param input body invitationModel.CreateInvitationDTO true "Invitation data"
+// @Success 201 {object} invitationModel.Invitation
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /invitations [post]
+func (h *InvitationHandler) CreateInvitationHandler(c *gin.Context) {
+	var payload m.CreateInvitationDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	invitation := m.Invitation{
+		Email: *payload.Email,
+		Role:  *payload.Role,
+	}
+	if payload.Driver != nil {
+		invitation.Driver = *payload.Driver
+	}
+	var expiresIn time.Duration
+	if payload.ExpiresInHours != nil {
+		expiresIn = time.Duration(*payload.ExpiresInHours) * time.Hour
+	}
+	var invitedBy *uuid.UUID
+	if principal, ok := requestctx.PrincipalFrom(c.Request.Context()); ok {
+		invitedBy = &principal.UserId
+	}
+
+	result, err := h.InvitationService.CreateInvitation(invitationService.CreateInvitationFuncParams{
+		Ctx:        c.Request.Context(),
+		Invitation: &invitation,
+		ExpiresIn:  expiresIn,
+		InvitedBy:  invitedBy,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Summary List invitations
+// @Description List invitations, newest first
+// @Tags invitations
+// @Produce json
+// @Param status query string false "Filter by status (pending, accepted, revoked, expired)"
+// @Success 200 {array} invitationModel.Invitation
+// @Failure 500 {object} ErrorResponse
+// @Router /invitations [get]
+func (h *InvitationHandler) ListInvitationsHandler(c *gin.Context) {
+	result, err := h.InvitationService.ListInvitations(invitationService.ListInvitationsFuncParams{
+		Ctx:    c.Request.Context(),
+		Status: c.Query("status"),
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Resend an invitation
+// @Description Rotate the token of a pending invitation, renew its expiry and send the link again
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} invitationModel.Invitation
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /invitations/{id}/resend [post]
+func (h *InvitationHandler) ResendInvitationHandler(c *gin.Context) {
+	invitationId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid invitation ID"})
+		return
+	}
+
+	result, err := h.InvitationService.ResendInvitation(invitationService.InvitationIdFuncParams{
+		Ctx:          c.Request.Context(),
+		InvitationId: invitationId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Revoke an invitation
+// @Description Revoke a pending invitation so its link can no longer be used
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} invitationModel.Invitation
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /invitations/{id}/revoke [post]
+func (h *InvitationHandler) RevokeInvitationHandler(c *gin.Context) {
+	invitationId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid invitation ID"})
+		return
+	}
+
+	result, err := h.InvitationService.RevokeInvitation(invitationService.InvitationIdFuncParams{
+		Ctx:          c.Request.Context(),
+		InvitationId: invitationId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Get an invitation by token
+// @Description Target of the emailed link. Returns who the pending invitation is for so the client can ask for the name and password, which are then sent with the same token to POST /invitations/accept.
+// @Tags invitations
+// @Produce json
+// @Param token query string true "Token from the email link"
+// @Success 200 {object} invitationModel.InvitationPreview
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /invitations/accept [get]
+func (h *InvitationHandler) GetInvitationByTokenHandler(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "token is required"})
+		return
+	}
+
+	invitation, err := h.InvitationService.GetInvitationByToken(invitationService.InvitationTokenFuncParams{
+		Ctx:   c.Request.Context(),
+		Token: token,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, m.InvitationPreview{
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		Driver:    invitation.Driver,
+		ExpiresAt: invitation.ExpiresAt,
+	})
+}
+
+// @Summary Accept an invitation
+// @Description Accept an invitation with the token from the email link (see GET /invitations/accept) and set the password. Creates the user.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param input body invitationModel.AcceptInvitationDTO true "Token and user data"
+// @Success 201 {object} userPresenter.UserSelfResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitationHandler(c *gin.Context) {
+	var payload m.AcceptInvitationDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.InvitationService.AcceptInvitation(invitationService.AcceptInvitationFuncParams{
+		Ctx:       c.Request.Context(),
+		Token:     *payload.Token,
+		Password:  *payload.Password,
+		FirstName: *payload.FirstName,
+		LastName:  *payload.LastName,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, userPresenter.PresentForOwner(c.Request.Context(), user))
+}
@@ -0,0 +1,62 @@
+package invitationModel
+
+import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+	StatusRevoked  = "revoked"
+	StatusExpired  = "expired"
+)
+
+// Invitation es la invitación de un admin a crear una cuenta. Driver marca que el invitado
+// viene a manejar y tiene que completar el alta de conductor después de aceptar; no lo
+// convierte en conductor, eso solo lo hace el alta aprobada.
+type Invitation struct {
+	baseModel.BaseModel
+	Email          string     `gorm:"index;not null" json:"email"`
+	Role           []string   `gorm:"type:json;serializer:json" json:"role"`
+	Driver         bool       `gorm:"not null;default:false" json:"driver"`
+	Status         string     `gorm:"index;not null;default:pending" json:"status"`
+	TokenHash      string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	LastSentAt     time.Time  `json:"last_sent_at"`
+	SendCount      int        `json:"send_count"`
+	InvitedBy      *uuid.UUID `gorm:"type:uuid" json:"invited_by,omitempty"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedUserId *uuid.UUID `gorm:"type:uuid" json:"accepted_user_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsExpired indica si la invitación pendiente ya venció.
+func (i *Invitation) IsExpired(now time.Time) bool {
+	return i.Status == StatusPending && now.After(i.ExpiresAt)
+}
+
+type CreateInvitationDTO struct {
+	Email          *string   `json:"email" binding:"required,email"`
+	Role           *[]string `json:"role" binding:"required,min=1"`
+	Driver         *bool     `json:"driver"`
+	ExpiresInHours *int      `json:"expires_in_hours" binding:"omitempty,min=1,max=720"`
+}
+
+// InvitationPreview es lo que ve el invitado al abrir el link, antes de aceptar. Driver
+// indica que después de aceptar tiene que completar el alta de conductor.
+type InvitationPreview struct {
+	Email     string    `json:"email"`
+	Role      []string  `json:"role"`
+	Driver    bool      `json:"driver"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AcceptInvitationDTO struct {
+	Token     *string `json:"token" binding:"required"`
+	Password  *string `json:"password" binding:"required,min=8"`
+	FirstName *string `json:"first_name" binding:"required"`
+	LastName  *string `json:"last_name" binding:"required"`
+}
@@ -0,0 +1,483 @@
+package invitationService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	invitationModel "github.com/aragornz325/piloto-api/internal/invitation/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/internal/user/service"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/mailer"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultExpiration es la vigencia de una invitación cuando el admin no indica otra.
+const DefaultExpiration = 72 * time.Hour
+
+// InvitationService maneja el alta de usuarios por invitación: el admin invita por email
+// con roles y flag de conductor preasignados, y el usuario se crea recién cuando el
+// invitado acepta y elige su contraseña.
+type InvitationService interface {
+	CreateInvitation(CreateInvitationFuncParams) (*invitationModel.Invitation, error)
+	ListInvitations(ListInvitationsFuncParams) ([]*invitationModel.Invitation, error)
+	ResendInvitation(InvitationIdFuncParams) (*invitationModel.Invitation, error)
+	RevokeInvitation(InvitationIdFuncParams) (*invitationModel.Invitation, error)
+	GetInvitationByToken(InvitationTokenFuncParams) (*invitationModel.Invitation, error)
+	AcceptInvitation(AcceptInvitationFuncParams) (*userModel.User, error)
+}
+
+type invitationService struct {
+	UserService service.UserService
+	Mailer      mailer.Mailer
+	// BaseURL es la URL pública de la API con la que se arma el link de aceptación.
+	BaseURL string
+}
+
+func NewInvitationService(userService service.UserService, mailer mailer.Mailer, baseURL string) InvitationService {
+	return &invitationService{
+		UserService: userService,
+		Mailer:      mailer,
+		BaseURL:     baseURL,
+	}
+}
+
+// CreateInvitation creates a pending invitation for the given email and sends the
+// tokenized acceptance link. It fails with a 409 if a user with that email already exists
+// or if there is another pending invitation for it; a partial unique index on the pending
+// emails makes the second of two concurrent requests fail too. Pending invitations for
+// the email that already expired are marked as expired.
+//
+// Parameters:
+//   - opts: CreateInvitationFuncParams containing the context, the invitation data and the inviting admin.
+//
+// Returns:
+//   - *invitationModel.Invitation: The created invitation.
+//   - error: An error if the invitation cannot be created or sent.
+func (s *invitationService) CreateInvitation(opts CreateInvitationFuncParams) (*invitationModel.Invitation, error) {
+	invitation := opts.Invitation
+	invitation.Email = strings.ToLower(strings.TrimSpace(invitation.Email))
+
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "CreateInvitation",
+		ServiceName: "invitation",
+		Operation: func() error {
+			_, err := s.UserService.GetUserByEmail(service.GetUserByEmailFuncParams{
+				Ctx:   opts.Ctx,
+				Email: invitation.Email,
+			})
+			if err == nil {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "a user with this email already exists",
+				})
+			}
+			if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			token, hash, err := utils.GenerateToken()
+			if err != nil {
+				return fmt.Errorf("error generating invitation token: %w", err)
+			}
+
+			now := time.Now().UTC()
+			if opts.ExpiresIn <= 0 {
+				opts.ExpiresIn = DefaultExpiration
+			}
+			invitation.Status = invitationModel.StatusPending
+			invitation.TokenHash = hash
+			invitation.ExpiresAt = now.Add(opts.ExpiresIn)
+			invitation.LastSentAt = now
+			invitation.SendCount = 1
+			invitation.InvitedBy = opts.InvitedBy
+			invitation.IsActive = true
+
+			// Las pendientes vencidas pasan a "expired" para liberar el índice único de
+			// pendientes por email; si queda otra vigente, el índice rechaza la nueva
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Model(&invitationModel.Invitation{}).
+					Where("lower(email) = ? AND status = ? AND expires_at <= ?", invitation.Email, invitationModel.StatusPending, now).
+					Updates(map[string]interface{}{
+						"status":     invitationModel.StatusExpired,
+						"version":    gorm.Expr("version + 1"),
+						"updated_at": now,
+					}).Error; err != nil {
+					return fmt.Errorf("error expiring old invitations: %w", err)
+				}
+				if err := tx.Create(invitation).Error; err != nil {
+					if db.IsUniqueViolation(err) {
+						return errors.NewConflict(errors.SimpleErrorFuncOptions{
+							Message: "there is already a pending invitation for this email",
+						})
+					}
+					return fmt.Errorf("error creating invitation: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			return s.sendInvitation(opts.Ctx, invitation, token)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ListInvitations returns the invitations, newest first, optionally filtered by status.
+// Pending invitations past their expiry are reported with status "expired".
+//
+// Parameters:
+//   - opts: ListInvitationsFuncParams containing the context and the optional status filter.
+//
+// Returns:
+//   - []*invitationModel.Invitation: The invitations found.
+//   - error: An error if the query fails.
+func (s *invitationService) ListInvitations(opts ListInvitationsFuncParams) ([]*invitationModel.Invitation, error) {
+	var invitations []*invitationModel.Invitation
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListInvitations",
+		ServiceName: "invitation",
+		Operation: func() error {
+			now := time.Now().UTC()
+			query := db.DB.WithContext(opts.Ctx).Order("created_at DESC")
+			switch opts.Status {
+			case "":
+			case invitationModel.StatusExpired:
+				query = query.Where("status = ? OR (status = ? AND expires_at <= ?)", invitationModel.StatusExpired, invitationModel.StatusPending, now)
+			case invitationModel.StatusPending:
+				query = query.Where("status = ? AND expires_at > ?", invitationModel.StatusPending, now)
+			default:
+				query = query.Where("status = ?", opts.Status)
+			}
+			if err := query.Find(&invitations).Error; err != nil {
+				return fmt.Errorf("error listing invitations: %w", err)
+			}
+			for _, invitation := range invitations {
+				if invitation.IsExpired(now) {
+					invitation.Status = invitationModel.StatusExpired
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// ResendInvitation rotates the token of a pending (or expired) invitation, renews its
+// expiry and sends the new link. The previous link stops working.
+//
+// Parameters:
+//   - opts: InvitationIdFuncParams containing the context and the invitation ID.
+//
+// Returns:
+//   - *invitationModel.Invitation: The updated invitation.
+//   - error: An error if the invitation is not pending or cannot be sent.
+func (s *invitationService) ResendInvitation(opts InvitationIdFuncParams) (*invitationModel.Invitation, error) {
+	var invitation *invitationModel.Invitation
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ResendInvitation",
+		ServiceName: "invitation",
+		Operation: func() error {
+			var err error
+			invitation, err = s.getInvitation(opts.Ctx, opts.InvitationId)
+			if err != nil {
+				return err
+			}
+			if invitation.Status != invitationModel.StatusPending {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "only pending invitations can be resent",
+				})
+			}
+
+			token, hash, err := utils.GenerateToken()
+			if err != nil {
+				return fmt.Errorf("error generating invitation token: %w", err)
+			}
+			now := time.Now().UTC()
+			result := db.DB.WithContext(opts.Ctx).
+				Model(invitation).
+				Where("version = ? AND status = ?", invitation.Version, invitationModel.StatusPending).
+				Updates(map[string]interface{}{
+					"token_hash":   hash,
+					"expires_at":   now.Add(DefaultExpiration),
+					"last_sent_at": now,
+					"send_count":   gorm.Expr("send_count + 1"),
+					"version":      invitation.Version + 1,
+					"updated_at":   now,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("error updating invitation: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "invitation was modified by another request",
+				})
+			}
+			invitation.SendCount++
+
+			return s.sendInvitation(opts.Ctx, invitation, token)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// RevokeInvitation marks a pending invitation as revoked so its link can no longer be used.
+//
+// Parameters:
+//   - opts: InvitationIdFuncParams containing the context and the invitation ID.
+//
+// Returns:
+//   - *invitationModel.Invitation: The revoked invitation.
+//   - error: An error if the invitation is not pending.
+func (s *invitationService) RevokeInvitation(opts InvitationIdFuncParams) (*invitationModel.Invitation, error) {
+	var invitation *invitationModel.Invitation
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "RevokeInvitation",
+		ServiceName: "invitation",
+		Operation: func() error {
+			var err error
+			invitation, err = s.getInvitation(opts.Ctx, opts.InvitationId)
+			if err != nil {
+				return err
+			}
+			if invitation.Status != invitationModel.StatusPending {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "only pending invitations can be revoked",
+				})
+			}
+
+			now := time.Now().UTC()
+			result := db.DB.WithContext(opts.Ctx).
+				Model(invitation).
+				Where("version = ? AND status = ?", invitation.Version, invitationModel.StatusPending).
+				Updates(map[string]interface{}{
+					"status":     invitationModel.StatusRevoked,
+					"revoked_at": now,
+					"version":    invitation.Version + 1,
+					"updated_at": now,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("error revoking invitation: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "invitation was modified by another request",
+				})
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// GetInvitationByToken returns the pending invitation of an emailed link, so the client can
+// show who it is for before asking for the password. The invitation is accepted with
+// AcceptInvitation using the same token.
+//
+// Parameters:
+//   - opts: InvitationTokenFuncParams containing the context and the token.
+//
+// Returns:
+//   - *invitationModel.Invitation: The invitation.
+//   - error: A 404 for unknown tokens, 410 for expired, revoked or accepted invitations.
+func (s *invitationService) GetInvitationByToken(opts InvitationTokenFuncParams) (*invitationModel.Invitation, error) {
+	var invitation invitationModel.Invitation
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetInvitationByToken",
+		ServiceName: "invitation",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("token_hash = ?", utils.HashToken(opts.Token)).
+				First(&invitation).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "invitation not found",
+					})
+				}
+				return fmt.Errorf("error getting invitation: %w", err)
+			}
+			if invitation.Status != invitationModel.StatusPending || invitation.IsExpired(time.Now().UTC()) {
+				return errors.NewGone(errors.SimpleErrorFuncOptions{
+					Message: "invitation is no longer valid",
+				})
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// AcceptInvitation creates the invited user with the chosen password and the preassigned
+// roles, and marks the invitation as accepted. The driver flag is not copied to the user:
+// only an approved driver registration makes a user a driver. Everything runs in a single
+// transaction with the invitation row locked, so a link can only be used once.
+//
+// Parameters:
+//   - opts: AcceptInvitationFuncParams containing the context, the token and the user data.
+//
+// Returns:
+//   - *userModel.User: The created user.
+//   - error: A 404 for unknown tokens, 410 for expired or revoked invitations, 409 if the email is taken.
+func (s *invitationService) AcceptInvitation(opts AcceptInvitationFuncParams) (*userModel.User, error) {
+	var user userModel.User
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "AcceptInvitation",
+		ServiceName: "invitation",
+		Operation: func() error {
+			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("error hashing password: %w", err)
+			}
+
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				var invitation invitationModel.Invitation
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("token_hash = ?", utils.HashToken(opts.Token)).
+					First(&invitation).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "invitation not found",
+						})
+					}
+					return fmt.Errorf("error getting invitation: %w", err)
+				}
+
+				now := time.Now().UTC()
+				if invitation.Status != invitationModel.StatusPending || invitation.IsExpired(now) {
+					return errors.NewGone(errors.SimpleErrorFuncOptions{
+						Message: "invitation is no longer valid",
+					})
+				}
+
+				var existing int64
+				if err := tx.Model(&userModel.User{}).Where("email = ?", invitation.Email).Count(&existing).Error; err != nil {
+					return fmt.Errorf("error checking email: %w", err)
+				}
+				if existing > 0 {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "a user with this email already exists",
+					})
+				}
+
+				user = userModel.User{
+					FirstName: opts.FirstName,
+					LastName:  opts.LastName,
+					Email:     invitation.Email,
+					Password:  string(hashedPassword),
+					Role:      invitation.Role,
+				}
+				user.CreatedAt = now
+				user.IsActive = true
+				if err := tx.Create(&user).Error; err != nil {
+					return fmt.Errorf("error creating user: %w", err)
+				}
+
+				return tx.Model(&invitation).Updates(map[string]interface{}{
+					"status":           invitationModel.StatusAccepted,
+					"accepted_at":      now,
+					"accepted_user_id": user.ID,
+					"version":          invitation.Version + 1,
+					"updated_at":       now,
+				}).Error
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *invitationService) getInvitation(ctx context.Context, invitationId uuid.UUID) (*invitationModel.Invitation, error) {
+	var invitation invitationModel.Invitation
+	if err := db.DB.WithContext(ctx).First(&invitation, invitationId).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFound(errors.SimpleErrorFuncOptions{
+				Message: "invitation not found",
+			})
+		}
+		return nil, fmt.Errorf("error getting invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+func (s *invitationService) sendInvitation(ctx context.Context, invitation *invitationModel.Invitation, token string) error {
+	link := fmt.Sprintf("%s/api/v1/invitations/accept?token=%s", strings.TrimRight(s.BaseURL, "/"), token)
+
+	next := ""
+	if invitation.Driver {
+		next = "\n\nOnce your account is created, complete your driver registration (license and documents) in the app."
+	}
+
+	if err := s.Mailer.Send(mailer.SendMailFuncParams{
+		Ctx:     ctx,
+		To:      invitation.Email,
+		Subject: "You have been invited to Piloto de Tormenta",
+		Body: fmt.Sprintf("You have been invited to join Piloto de Tormenta.\n\nOpen this link in the app to accept the invitation and choose your password:\n%s\n\nThis link expires on %s.%s",
+			link, invitation.ExpiresAt.Format(time.RFC1123), next),
+	}); err != nil {
+		return fmt.Errorf("error sending invitation email: %w", err)
+	}
+	return nil
+}
+
+// /-------- structs ---------///
+type CreateInvitationFuncParams struct {
+	Ctx        context.Context
+	Invitation *invitationModel.Invitation
+	ExpiresIn  time.Duration
+	InvitedBy  *uuid.UUID
+}
+
+type ListInvitationsFuncParams struct {
+	Ctx    context.Context
+	Status string
+}
+
+type InvitationIdFuncParams struct {
+	Ctx          context.Context
+	InvitationId uuid.UUID
+}
+
+type InvitationTokenFuncParams struct {
+	Ctx   context.Context
+	Token string
+}
+
+type AcceptInvitationFuncParams struct {
+	Ctx       context.Context
+	Token     string
+	Password  string
+	FirstName string
+	LastName  string
+}
@@ -0,0 +1,244 @@
+package locationHandler
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	m "github.com/aragornz325/piloto-api/internal/location/model"
+	locationService "github.com/aragornz325/piloto-api/internal/location/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// streamIdleTimeout cierra la conexión si la app no manda nada en ese tiempo.
+	streamIdleTimeout = 2 * time.Minute
+	// streamWriteTimeout limita cuánto se espera a un cliente lento al responder.
+	streamWriteTimeout = 10 * time.Second
+	// maxMessageBytes es el tamaño máximo de un mensaje de la app.
+	maxMessageBytes = 1024
+	// streamRecheckInterval es cada cuánto se vuelve a verificar, mientras la conexión siga
+	// abierta, que la cuenta esté activa y el conductor aprobado y en regla.
+	streamRecheckInterval = time.Minute
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type LocationHandler struct {
+	LocationService locationService.LocationService
+	// streams son las conexiones abiertas: http.Server.Shutdown no espera ni cierra las
+	// conexiones tomadas por el WebSocket, se cierran con CloseStreams.
+	mu      sync.Mutex
+	streams map[*websocket.Conn]struct{}
+	closed  bool
+}
+
+func NewLocationHandler(locationService locationService.LocationService) *LocationHandler {
+	return &LocationHandler{
+		LocationService: locationService,
+		streams:         map[*websocket.Conn]struct{}{},
+	}
+}
+
+// CloseStreams cierra las conexiones de ubicación abiertas y rechaza las nuevas. Se llama
+// al apagar el servidor, antes del último guardado de posiciones.
+func (h *LocationHandler) CloseStreams() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ws := range h.streams {
+		ws.Close()
+	}
+	logger.Log.Info("🛑 Conexiones de ubicación cerradas", zap.Int("count", len(h.streams)))
+}
+
+//----------------------------------------------------
+
+// @Summary Stream driver location
+// @Description Upgrade to a WebSocket where the driver's app sends its GPS positions as JSON messages ({"lat":..,"lng":..,"heading":..,"speed":..,"recorded_at":..}). Each message is answered with {"type":"ack"|"ignored"|"error"}. Requires an active, approved and compliant driver authenticated with the Authorization header; the connection is closed after an error message when the token expires or the driver stops meeting these conditions.
+// @Tags location
+// @Success 101
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /me/location/stream [get]
+func (h *LocationHandler) StreamLocationHandler(c *gin.Context) {
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+	if err := h.LocationService.AuthorizeStream(locationService.AuthorizeStreamFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: principal.UserId,
+	}); err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	server := websocket.Server{
+		// El usuario se autentica con el header Authorization, que un navegador no manda en
+		// un WebSocket iniciado desde otro sitio: no hace falta validar el Origin
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			h.stream(ws, principal)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// stream lee las posiciones de la conexión hasta que el cliente la cierra, queda inactiva,
+// manda un mensaje demasiado grande o deja de estar autorizado (ver authorized).
+func (h *LocationHandler) stream(ws *websocket.Conn, principal *requestctx.Principal) {
+	defer ws.Close()
+	if !h.track(ws) {
+		return
+	}
+	defer h.untrack(ws)
+	ws.MaxPayloadBytes = maxMessageBytes
+	userId := principal.UserId
+	checkedAt := time.Now()
+
+	for {
+		ws.SetReadDeadline(time.Now().Add(streamIdleTimeout))
+		var update m.LocationUpdate
+		var reply m.StreamMessage
+		err := websocket.JSON.Receive(ws, &update)
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if err != nil && !stderrors.As(err, &syntaxErr) && !stderrors.As(err, &typeErr) {
+			return
+		}
+		if reason := h.authorized(principal, &checkedAt); reason != "" {
+			ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			websocket.JSON.Send(ws, m.StreamMessage{Type: m.MessageError, Error: reason})
+			logger.Log.Info("🔒 Conexión de ubicación cerrada por autorización",
+				zap.String("user_id", userId.String()), zap.String("reason", reason))
+			return
+		}
+		if err != nil {
+			reply = m.StreamMessage{Type: m.MessageError, Error: "invalid message: " + err.Error()}
+		} else {
+			position, err := h.LocationService.RecordLocation(locationService.RecordLocationFuncParams{
+				UserId: userId,
+				Update: update,
+			})
+			switch {
+			case err != nil:
+				reply = m.StreamMessage{Type: m.MessageError, Error: err.Error(), Fields: errors.FieldErrors(err)}
+			case position == nil:
+				reply = m.StreamMessage{Type: m.MessageIgnored}
+			default:
+				reply = m.StreamMessage{Type: m.MessageAck, RecordedAt: &position.RecordedAt}
+			}
+		}
+
+		ws.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err := websocket.JSON.Send(ws, reply); err != nil {
+			logger.Log.Debug("Conexión de ubicación cerrada", zap.String("user_id", userId.String()), zap.Error(err))
+			return
+		}
+	}
+}
+
+// authorized devuelve por qué la conexión ya no está autorizada, o "" si lo sigue. El
+// vencimiento del token se mira en cada mensaje; la cuenta y el alta de conductor, cada
+// streamRecheckInterval desde checkedAt, que se actualiza al verificarlas.
+func (h *LocationHandler) authorized(principal *requestctx.Principal, checkedAt *time.Time) string {
+	now := time.Now()
+	if !principal.ExpiresAt.IsZero() && !now.Before(principal.ExpiresAt) {
+		return "token expired"
+	}
+	if now.Sub(*checkedAt) < streamRecheckInterval {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), streamWriteTimeout)
+	defer cancel()
+	err := h.LocationService.AuthorizeStream(locationService.AuthorizeStreamFuncParams{
+		Ctx:    ctx,
+		UserId: principal.UserId,
+	})
+	if err != nil && errors.StatusCode(err, http.StatusInternalServerError) == http.StatusInternalServerError {
+		// Una falla de la base no corta la conexión: se reintenta en el próximo mensaje
+		logger.Log.Warn("⚠️ No se pudo verificar la conexión de ubicación",
+			zap.String("user_id", principal.UserId.String()), zap.Error(err))
+		return ""
+	}
+	*checkedAt = now
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// track registra una conexión abierta; devuelve false si el servidor se está apagando.
+func (h *LocationHandler) track(ws *websocket.Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.streams[ws] = struct{}{}
+	return true
+}
+
+func (h *LocationHandler) untrack(ws *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.streams, ws)
+}
+
+// @Summary Nearest available drivers
+// @Description Find the available drivers (approved, on duty and inside their schedule) with a live position within the given radius, closest first
+// @Tags location
+// @Produce json
+// @Param lat query number true "Latitude of the center point"
+// @Param lng query number true "Longitude of the center point"
+// @Param radius query number true "Search radius in kilometers (max 50)"
+// @Param limit query int false "Maximum number of results (default 10, max 50)"
+// @Success 200 {array} locationService.NearestDriver
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /drivers/nearest [get]
+func (h *LocationHandler) NearestDriversHandler(c *gin.Context) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+	radius, errRadius := strconv.ParseFloat(c.Query("radius"), 64)
+	if errLat != nil || errLng != nil || errRadius != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lat, lng and radius must be numbers"})
+		return
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be an integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	result, err := h.LocationService.FindNearestDrivers(locationService.FindNearestDriversFuncParams{
+		Ctx:      c.Request.Context(),
+		Center:   geo.Coordinates{Latitude: lat, Longitude: lng},
+		RadiusKm: radius,
+		Limit:    limit,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
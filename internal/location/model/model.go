@@ -0,0 +1,58 @@
+package locationModel
+
+import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+// DriverLocation es la última posición conocida de un conductor. La posición en vivo está en
+// memoria (geo.Index); esta tabla se actualiza periódicamente para no perderla al reiniciar.
+type DriverLocation struct {
+	baseModel.BaseModel
+	UserId     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Latitude   float64   `gorm:"not null" json:"latitude"`
+	Longitude  float64   `gorm:"not null" json:"longitude"`
+	Heading    *float64  `json:"heading,omitempty"`
+	Speed      *float64  `json:"speed,omitempty"`
+	RecordedAt time.Time `gorm:"not null;index" json:"recorded_at"`
+}
+
+// Position convierte la fila guardada en una posición del índice.
+func (l *DriverLocation) Position() geo.Position {
+	return geo.Position{
+		Id:          l.UserId,
+		Coordinates: geo.Coordinates{Latitude: l.Latitude, Longitude: l.Longitude},
+		Heading:     l.Heading,
+		Speed:       l.Speed,
+		RecordedAt:  l.RecordedAt,
+	}
+}
+
+// LocationUpdate es un mensaje que manda la app del conductor por el WebSocket.
+// RecordedAt es el momento del fix GPS (RFC3339); si no viene se usa la hora de llegada.
+type LocationUpdate struct {
+	Latitude   *float64   `json:"lat"`
+	Longitude  *float64   `json:"lng"`
+	Heading    *float64   `json:"heading,omitempty"`
+	Speed      *float64   `json:"speed,omitempty"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+}
+
+// StreamMessage es la respuesta del servidor a cada LocationUpdate.
+type StreamMessage struct {
+	// Type es "ack" si la posición se guardó, "ignored" si se descartó por vieja o por
+	// llegar demasiado seguido y "error" si el mensaje es inválido.
+	Type       string            `json:"type"`
+	RecordedAt *time.Time        `json:"recorded_at,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+const (
+	MessageAck     = "ack"
+	MessageIgnored = "ignored"
+	MessageError   = "error"
+)
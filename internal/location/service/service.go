@@ -0,0 +1,363 @@
+package locationService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	availabilityService "github.com/aragornz325/piloto-api/internal/availability/service"
+	driverModel "github.com/aragornz325/piloto-api/internal/driver/model"
+	locationModel "github.com/aragornz325/piloto-api/internal/location/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// CellSizeDeg es el tamaño de las celdas del índice espacial (unos 5 km).
+	CellSizeDeg = 0.05
+	// MaxLocationAge es la antigüedad máxima de una posición para considerarla en vivo.
+	MaxLocationAge = 2 * time.Minute
+	// MinUpdateInterval descarta las posiciones de un conductor que llegan más seguido.
+	MinUpdateInterval = time.Second
+	// MaxClockSkew es cuánto puede adelantar el reloj del dispositivo; más allá se usa la
+	// hora del servidor.
+	MaxClockSkew = 30 * time.Second
+	// PersistInterval es cada cuánto se guardan en la base las posiciones que cambiaron.
+	PersistInterval = 15 * time.Second
+	// MaxNearestRadiusKm, DefaultNearestLimit y MaxNearestLimit acotan la búsqueda de
+	// conductores cercanos.
+	MaxNearestRadiusKm  = 50.0
+	DefaultNearestLimit = 10
+	MaxNearestLimit     = 50
+)
+
+type LocationService interface {
+	AuthorizeStream(AuthorizeStreamFuncParams) error
+	RecordLocation(RecordLocationFuncParams) (*geo.Position, error)
+	FindNearestDrivers(FindNearestDriversFuncParams) ([]NearestDriver, error)
+	Run(ctx context.Context)
+	Flush(ctx context.Context) error
+}
+
+type locationService struct {
+	Index        *geo.Index
+	Availability availabilityService.AvailabilityService
+}
+
+func NewLocationService(availability availabilityService.AvailabilityService) LocationService {
+	return &locationService{
+		Index:        geo.NewIndex(CellSizeDeg),
+		Availability: availability,
+	}
+}
+
+// AuthorizeStream checks that a user can stream positions: only active accounts of approved
+// drivers in good standing (license and insurance not expired) can. It is checked when the
+// stream is opened and again periodically while it stays open.
+//
+// Parameters:
+//   - opts: AuthorizeStreamFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - error: A 403 if the account is not active or the user is not an approved, compliant driver.
+func (s *locationService) AuthorizeStream(opts AuthorizeStreamFuncParams) error {
+	return utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "AuthorizeStream",
+		ServiceName: "location",
+		Operation: func() error {
+			var user userModel.User
+			if err := db.DB.WithContext(opts.Ctx).Select("id", "driver", "status").
+				Where("id = ?", opts.UserId).
+				First(&user).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "user not found",
+					})
+				}
+				return fmt.Errorf("error getting user: %w", err)
+			}
+			if !userModel.CanAuthenticate(user.Status) {
+				return errors.NewForbidden(errors.ErrorFuncOptions{
+					Message: "account is " + user.Status,
+				})
+			}
+			if !user.Driver {
+				return errors.NewForbidden(errors.ErrorFuncOptions{
+					Message: "only approved drivers can stream their location",
+				})
+			}
+
+			var driver driverModel.Driver
+			if err := db.DB.WithContext(opts.Ctx).Select("id", "status", "compliant").
+				Where("user_id = ?", opts.UserId).
+				First(&driver).Error; err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("error getting driver: %w", err)
+			}
+			if driver.Status != driverModel.StatusApproved {
+				return errors.NewForbidden(errors.ErrorFuncOptions{
+					Message: "only approved drivers can stream their location",
+				})
+			}
+			if !driver.Compliant {
+				return errors.NewForbidden(errors.ErrorFuncOptions{
+					Message: "driver license or insurance expired",
+				})
+			}
+			return nil
+		},
+	})
+}
+
+// RecordLocation stores the position of a driver in the in-memory index. It is persisted
+// with the next flush. Positions older than the one already known, older than
+// MaxLocationAge or arriving less than MinUpdateInterval after the previous one are ignored.
+//
+// Parameters:
+//   - opts: RecordLocationFuncParams containing the user ID and the update sent by the app.
+//
+// Returns:
+//   - *geo.Position: The stored position, or nil if it was ignored.
+//   - error: A 400 with the invalid fields.
+func (s *locationService) RecordLocation(opts RecordLocationFuncParams) (*geo.Position, error) {
+	update := opts.Update
+	fields := map[string]string{}
+	if update.Latitude == nil || update.Longitude == nil {
+		fields["lat"] = "lat and lng are required"
+	} else if !(geo.Coordinates{Latitude: *update.Latitude, Longitude: *update.Longitude}).Valid() {
+		fields["lat"] = "lat must be between -90 and 90 and lng between -180 and 180"
+	}
+	if update.Heading != nil && (*update.Heading < 0 || *update.Heading >= 360) {
+		fields["heading"] = "must be between 0 and 360"
+	}
+	if update.Speed != nil && *update.Speed < 0 {
+		fields["speed"] = "can not be negative"
+	}
+	if len(fields) > 0 {
+		return nil, errors.NewValidation(errors.ValidationErrorFuncOptions{
+			Message: "invalid location",
+			Fields:  fields,
+		})
+	}
+
+	now := time.Now().UTC()
+	recordedAt := now
+	if update.RecordedAt != nil && !update.RecordedAt.After(now.Add(MaxClockSkew)) {
+		recordedAt = update.RecordedAt.UTC()
+	}
+	if now.Sub(recordedAt) > MaxLocationAge {
+		return nil, nil
+	}
+	if previous, ok := s.Index.Get(opts.UserId); ok && recordedAt.Sub(previous.RecordedAt) < MinUpdateInterval {
+		return nil, nil
+	}
+
+	position := geo.Position{
+		Id:          opts.UserId,
+		Coordinates: geo.Coordinates{Latitude: *update.Latitude, Longitude: *update.Longitude},
+		Heading:     update.Heading,
+		Speed:       update.Speed,
+		RecordedAt:  recordedAt,
+	}
+	if !s.Index.Update(position) {
+		return nil, nil
+	}
+	return &position, nil
+}
+
+// FindNearestDrivers returns the available drivers (approved, on duty and inside their
+// schedule) with a live position within RadiusKm of the given point, closest first.
+//
+// Parameters:
+//   - opts: FindNearestDriversFuncParams containing the context, center point, radius and limit.
+//
+// Returns:
+//   - []NearestDriver: The drivers with their position and distance in kilometers.
+//   - error: A 400 for invalid coordinates or radius, or any other error.
+func (s *locationService) FindNearestDrivers(opts FindNearestDriversFuncParams) ([]NearestDriver, error) {
+	nearest := []NearestDriver{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "FindNearestDrivers",
+		ServiceName: "location",
+		Operation: func() error {
+			if !opts.Center.Valid() {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: "lat must be between -90 and 90 and lng between -180 and 180",
+				})
+			}
+			if opts.RadiusKm <= 0 || opts.RadiusKm > MaxNearestRadiusKm {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: fmt.Sprintf("radius must be greater than 0 and at most %g km", MaxNearestRadiusKm),
+				})
+			}
+			limit := opts.Limit
+			if limit <= 0 {
+				limit = DefaultNearestLimit
+			}
+			limit = min(limit, MaxNearestLimit)
+
+			now := time.Now().UTC()
+			candidates := s.Index.Nearby(opts.Center, opts.RadiusKm, now.Add(-MaxLocationAge), nil)
+			if len(candidates) == 0 {
+				return nil
+			}
+			userIds := make([]uuid.UUID, len(candidates))
+			for i, candidate := range candidates {
+				userIds[i] = candidate.Id
+			}
+			available, err := s.Availability.ListAvailableDrivers(availabilityService.ListAvailableDriversFuncParams{
+				Ctx:     opts.Ctx,
+				At:      now,
+				UserIds: userIds,
+			})
+			if err != nil {
+				return err
+			}
+			drivers := make(map[uuid.UUID]availabilityService.AvailableDriver, len(available))
+			for _, driver := range available {
+				drivers[driver.UserId] = driver
+			}
+
+			for _, candidate := range candidates {
+				driver, ok := drivers[candidate.Id]
+				if !ok {
+					continue
+				}
+				nearest = append(nearest, NearestDriver{
+					AvailableDriver: driver,
+					Location:        candidate.Position,
+					DistanceKm:      candidate.DistanceKm,
+				})
+				if len(nearest) == limit {
+					break
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nearest, nil
+}
+
+// Run restores the recent positions saved in the database and then persists the changed
+// positions every PersistInterval until ctx is cancelled, when it does a last flush.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the loop.
+func (s *locationService) Run(ctx context.Context) {
+	if err := s.restore(ctx); err != nil {
+		logger.Log.Error("💥 Error al restaurar las posiciones de los conductores", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(PersistInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Último guardado con un contexto propio: el del loop ya está cancelado
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.Flush(flushCtx); err != nil {
+				logger.Log.Error("💥 Error al guardar las posiciones de los conductores", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				logger.Log.Error("💥 Error al guardar las posiciones de los conductores", zap.Error(err))
+			}
+			s.Index.Prune(time.Now().UTC().Add(-MaxLocationAge))
+		}
+	}
+}
+
+// Flush saves in the database the positions that changed since the last flush. If the
+// write fails the positions stay pending for the next one.
+//
+// Parameters:
+//   - ctx: Context of the operation.
+//
+// Returns:
+//   - error: An error if the write fails.
+func (s *locationService) Flush(ctx context.Context) error {
+	positions := s.Index.Drain()
+	if len(positions) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	rows := make([]*locationModel.DriverLocation, len(positions))
+	for i, position := range positions {
+		rows[i] = &locationModel.DriverLocation{
+			UserId:     position.Id,
+			Latitude:   position.Coordinates.Latitude,
+			Longitude:  position.Coordinates.Longitude,
+			Heading:    position.Heading,
+			Speed:      position.Speed,
+			RecordedAt: position.RecordedAt,
+		}
+		rows[i].UpdatedAt = now
+	}
+	if err := db.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"latitude", "longitude", "heading", "speed", "recorded_at", "updated_at"}),
+		}).
+		CreateInBatches(rows, 500).Error; err != nil {
+		s.Index.MarkDirty(positions)
+		return fmt.Errorf("error saving driver locations: %w", err)
+	}
+	logger.Log.Debug("📍 Posiciones de conductores guardadas", zap.Int("count", len(positions)))
+	return nil
+}
+
+// restore carga en el índice las posiciones guardadas que todavía están en vivo, por
+// ejemplo después de un reinicio.
+func (s *locationService) restore(ctx context.Context) error {
+	var rows []*locationModel.DriverLocation
+	if err := db.DB.WithContext(ctx).
+		Where("recorded_at >= ?", time.Now().UTC().Add(-MaxLocationAge)).
+		Find(&rows).Error; err != nil {
+		return fmt.Errorf("error loading driver locations: %w", err)
+	}
+	for _, row := range rows {
+		s.Index.Restore(row.Position())
+	}
+	return nil
+}
+
+// /------ structs ------///
+
+// NearestDriver es un conductor disponible con su última posición y la distancia al punto buscado.
+type NearestDriver struct {
+	availabilityService.AvailableDriver
+	Location   geo.Position `json:"location"`
+	DistanceKm float64      `json:"distance_km"`
+}
+
+type AuthorizeStreamFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+}
+
+type RecordLocationFuncParams struct {
+	UserId uuid.UUID
+	Update locationModel.LocationUpdate
+}
+
+type FindNearestDriversFuncParams struct {
+	Ctx      context.Context
+	Center   geo.Coordinates
+	RadiusKm float64
+	Limit    int
+}
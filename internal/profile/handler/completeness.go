@@ -0,0 +1,37 @@
+package profileHandler
+
+import (
+	"net/http"
+
+	profilePresenter "github.com/aragornz325/piloto-api/internal/profile/presenter"
+	profileService "github.com/aragornz325/piloto-api/internal/profile/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get my profile completeness
+// @Description Get the completeness score (0 to 100) of the authenticated user's profile, computed from profile fields and verification states with configurable weights, and the onboarding checklist with the missing items sorted by weight.
+// @Tags profile
+// @Produce json
+// @Success 200 {object} profilePresenter.CompletenessResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /me/profile/completeness [get]
+func (h *ProfileHandler) GetMyCompletenessHandler(c *gin.Context) {
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+
+	completeness, err := h.ProfileService.GetCompleteness(profileService.GeProfileByUserIdFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: principal.UserId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profilePresenter.PresentCompleteness(completeness))
+}
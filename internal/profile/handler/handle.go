@@ -0,0 +1,93 @@
+package profileHandler
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+
+	m "github.com/aragornz325/piloto-api/internal/profile/model"
+	profilePresenter "github.com/aragornz325/piloto-api/internal/profile/presenter"
+	profileService "github.com/aragornz325/piloto-api/internal/profile/service"
+	userPresenter "github.com/aragornz325/piloto-api/internal/user/presenter"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Claim profile handle
+// @Description Set or change the public handle of a profile. Handles are 3 to 30 letters, numbers, dots or underscores, case-insensitive and unique. Reserved words are rejected and a handle can only be changed once every 14 days. The previous handle redirects to the profile for 30 days.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID owning the profile"
+// @Param If-Match header string true "ETag of the profile being updated"
+// @Param input body profileModel.ClaimHandleDTO true "New handle"
+// @Success 200 {object} profilePresenter.ProfileOwnerResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /profile/{id}/handle [put]
+func (h *ProfileHandler) ClaimHandleHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var payload m.ClaimHandleDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.ProfileService.ClaimHandle(profileService.ClaimHandleFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		Handle:          *payload.Handle,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
+}
+
+// @Summary Get profile by handle
+// @Description Get the public profile of the user owning a handle (case-insensitive). A previous handle still in its grace period answers with a redirect to the current one.
+// @Tags profile
+// @Produce json
+// @Param handle path string true "Profile handle"
+// @Success 200 {object} userPresenter.UserPublicResponse
+// @Success 302 "Redirect to the current handle"
+// @Failure 404 {object} ErrorResponse
+// @Router /profile/handle/{handle} [get]
+func (h *ProfileHandler) GetProfileByHandleHandler(c *gin.Context) {
+	user, redirectTo, err := h.ProfileService.ResolveHandle(profileService.ResolveHandleFuncParams{
+		Ctx:    c.Request.Context(),
+		Handle: c.Param("handle"),
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if redirectTo != "" {
+		// Se reemplaza solo el último segmento para conservar el prefijo de la ruta y la query
+		location := url.URL{Path: path.Join(path.Dir(c.Request.URL.Path), redirectTo), RawQuery: c.Request.URL.RawQuery}
+		c.Redirect(http.StatusFound, location.String())
+		return
+	}
+	c.JSON(http.StatusOK, userPresenter.PresentForViewer(c.Request.Context(), user))
+}
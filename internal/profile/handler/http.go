@@ -1,10 +1,18 @@
 package profileHandler
 
 import (
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 
 	m "github.com/aragornz325/piloto-api/internal/profile/model"
+	profilePresenter "github.com/aragornz325/piloto-api/internal/profile/presenter"
 	profileService "github.com/aragornz325/piloto-api/internal/profile/service"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/presenter"
 	"github.com/aragornz325/piloto-api/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -13,7 +21,8 @@ import (
 
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 type ProfileHandler struct {
@@ -31,13 +40,15 @@ func NewProfileHandler(profileService profileService.ProfileService) *ProfileHan
 //----------------------------------------------------
 
 // @Summary Create a new Profile
-// @Description Create a user profile
+// @Description Create a user profile. Social links accept a handle or a URL of the network and are stored canonicalized; invalid fields are listed in "fields". Only the user in user_id or an admin can create it. The location is the default address of /users/{id}/addresses.
 // @Tags profile
 // @Accept json
 // @Produce json
 // @Param input body profileModel.UserProfileDTO true "Data to create a new user profile"
-// @Success 201 {object} profileModel.Profile
+// @Success 201 {object} profilePresenter.ProfileResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /profile [post]
 func (h *ProfileHandler) CreateProfileHandler(c *gin.Context) {
 	var payload m.UserProfileDTO
@@ -45,12 +56,17 @@ func (h *ProfileHandler) CreateProfileHandler(c *gin.Context) {
 
 	// Bind JSON
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
 		return
 	}
 	// Validar struct con validator
 	if err := validate.Struct(payload); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+	// Solo el propio usuario o un admin crea el perfil
+	if audience := h.audienceFor(c, *payload.UserId); audience != presenter.AudienceSelf && audience != presenter.AudienceAdmin {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "insufficient permissions"})
 		return
 	}
 	// Mapear campos no nulos al modelo
@@ -69,11 +85,11 @@ func (h *ProfileHandler) CreateProfileHandler(c *gin.Context) {
 	})
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(errors.StatusCode(err, http.StatusBadRequest), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
 		return
 	}
 
-	c.JSON(http.StatusCreated, result)
+	c.JSON(http.StatusCreated, profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
 }
 
 // @Summary Get profile by ID
@@ -82,36 +98,63 @@ func (h *ProfileHandler) CreateProfileHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Profile ID"
-// @Success 200 {object} profileModel.Profile
+// @Param fields query string false "Comma separated list of fields to return (e.g. bio,city)"
+// @Success 200 {object} profilePresenter.ProfileResponse
+// @Header 200 {string} ETag "Current version of the profile"
 // @Failure 404 {object} ErrorResponse
 // @Router /profile/{id} [get]
 func (h *ProfileHandler) GetProfileByIdHandler(c *gin.Context) {
 	id := c.Param("id")
 	userId, err := uuid.Parse(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+	query, err := utils.ParseQueryOptions(c, utils.ParseQueryOptionsFuncParams{
+		AllowedFields:   m.SelectableFields,
+		AllowedIncludes: m.Includes,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
 	// Llamar al servicio
 	result, err := h.ProfileService.GetUserProfile(profileService.GeProfileByUserIdFuncParams{
 		Ctx:      c.Request.Context(),
 		UserId:  userId,
+		Query:   query,
 	})
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		c.JSON(errors.StatusCode(err, http.StatusNotFound), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := query.Project(profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Update profile
-// @Description Update a user profile
+// @Description Update a user profile. Social links accept a handle or a URL of the network and are stored canonicalized; invalid fields are listed in "fields". Only the user or an admin can update it. The location is the default address of /users/{id}/addresses.
 // @Tags profile
 // @Accept json
 // @Produce json
 // @Param id path string true "Profile ID"
+// @Param If-Match header string true "ETag of the profile being updated"
 // @Param input body profileModel.UserProfileDTO true "Data to update a user profile"
-// @Success 200 {object} profileModel.Profile
+// @Success 200 {object} profilePresenter.ProfileResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Router /profile/{id} [put]
 func (h *ProfileHandler) UpdateProfileHandler(c *gin.Context) {	
 	id := c.Param("id")
@@ -120,19 +163,24 @@ func (h *ProfileHandler) UpdateProfileHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
 		return
 	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
 
 	var payload m.UserProfileDTO
 	var profile m.Profile
 
 	// Bind JSON
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
 		return
 	}
 
 	// Validar struct con validator
 	if err := validate.Struct(payload); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
 		return
 	}
 
@@ -146,27 +194,34 @@ func (h *ProfileHandler) UpdateProfileHandler(c *gin.Context) {
 	}
 
 	result, err := h.ProfileService.UpdateUserProfile(profileService.UpdateUserProfileFuncParams{
-		Ctx:     c.Request.Context(),
-		UserId:  userId,
-		Profile: &profile,
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		Profile:         &profile,
+		ExpectedVersion: expectedVersion,
 	})
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		c.JSON(errors.StatusCode(err, http.StatusNotFound), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
 }
 
 // @Summary Soft delete profile
-// @Description Soft delete a user profile
+// @Description Soft delete a user profile. Only the user or an admin can delete it.
 // @Tags profile
 // @Accept json
 // @Produce json
 // @Param id path string true "Profile ID"
-// @Success 200 {object} profileModel.Profile
+// @Param If-Match header string true "ETag of the profile being deleted"
+// @Success 200 {object} profilePresenter.ProfileResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Router /profile/{id} [delete]
 func (h *ProfileHandler) SoftDeleteProfileHandler(c *gin.Context) {
 	id := c.Param("id")
@@ -176,15 +231,143 @@ func (h *ProfileHandler) SoftDeleteProfileHandler(c *gin.Context) {
 		return
 	}
 
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	result, err := h.ProfileService.SoftDeleteUserProfile(profileService.SoftDeleteUserProfileFuncParams{
-		Ctx:     c.Request.Context(),
-		UserId:  userId,
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		ExpectedVersion: expectedVersion,
 	})
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		c.JSON(errors.StatusCode(err, http.StatusNotFound), ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
-}
\ No newline at end of file
+	c.JSON(http.StatusOK, profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
+}
+
+// audienceFor resuelve la audiencia del usuario autenticado frente al dueño del perfil.
+func (h *ProfileHandler) audienceFor(c *gin.Context, ownerId uuid.UUID) presenter.Audience {
+	return presenter.AudienceFor(presenter.AudienceForFuncParams{
+		Ctx:        c.Request.Context(),
+		OwnerId:    ownerId,
+		AdminRoles: []string{userModel.RoleAdmin},
+	})
+}
+
+// @Summary Upload avatar
+// @Description Upload a profile avatar (JPEG, PNG or GIF, up to 5MB). The image is re-encoded without EXIF metadata, resized and stored with thumbnails; Profile.Avatar is set to the served URL.
+// @Tags profile
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "User ID owning the profile"
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} profilePresenter.ProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Router /profile/{id}/avatar [post]
+func (h *ProfileHandler) UploadAvatarHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+
+	// Margen para los headers del multipart por encima del tamaño máximo del archivo
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, profileService.MaxAvatarBytes+(1<<20))
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "avatar file is required"})
+		return
+	}
+	if fileHeader.Size > profileService.MaxAvatarBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "avatar is too large"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, profileService.MaxAvatarBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.ProfileService.UploadAvatar(profileService.UploadAvatarFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+		Data:   data,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
+}
+
+// @Summary Find nearby profiles
+// @Description List the profiles whose user's default address is within radius kilometers of the given point, closest first. Profiles whose location is not visible to the caller are skipped.
+// @Tags profile
+// @Produce json
+// @Param lat query number true "Latitude of the center point"
+// @Param lng query number true "Longitude of the center point"
+// @Param radius query number true "Search radius in kilometers (max 500)"
+// @Param limit query int false "Maximum number of results (default 50, max 200)"
+// @Success 200 {array} profilePresenter.NearbyProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /profile/nearby [get]
+func (h *ProfileHandler) NearbyProfilesHandler(c *gin.Context) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+	radius, errRadius := strconv.ParseFloat(c.Query("radius"), 64)
+	if errLat != nil || errLng != nil || errRadius != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lat, lng and radius must be numbers"})
+		return
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be an integer"})
+			return
+		}
+		limit = parsed
+	}
+
+	result, err := h.ProfileService.FindNearbyProfiles(profileService.FindNearbyProfilesFuncParams{
+		Ctx:      c.Request.Context(),
+		Center:   geo.Coordinates{Latitude: lat, Longitude: lng},
+		RadiusKm: radius,
+		Limit:    limit,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response := make([]profilePresenter.NearbyProfileResponse, 0, len(result))
+	for _, nearby := range result {
+		audience := h.audienceFor(c, nearby.Profile.UserId)
+		// La distancia revela la ubicación: se omiten los perfiles que la ocultan a este usuario
+		if !profilePresenter.CanView(nearby.Profile, "location", audience) {
+			continue
+		}
+		response = append(response, profilePresenter.NearbyProfileResponse{
+			Profile:    profilePresenter.Present(nearby.Profile, audience),
+			DistanceKm: math.Round(nearby.DistanceKm*100) / 100,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,82 @@
+package profileHandler
+
+import (
+	"net/http"
+
+	m "github.com/aragornz325/piloto-api/internal/profile/model"
+	profilePresenter "github.com/aragornz325/piloto-api/internal/profile/presenter"
+	profileService "github.com/aragornz325/piloto-api/internal/profile/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Request phone verification
+// @Description Send a 6-digit verification code by SMS to the phone number of the profile. Only the profile owner can request it.
+// @Tags profile
+// @Produce json
+// @Param id path string true "User ID owning the profile"
+// @Success 202 {object} profilePresenter.PhoneVerificationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /profile/{id}/phone/verification [post]
+func (h *ProfileHandler) RequestPhoneVerificationHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+
+	result, err := h.ProfileService.RequestPhoneVerification(profileService.PhoneVerificationFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, profilePresenter.PresentPhoneVerification(result))
+}
+
+// @Summary Confirm phone verification
+// @Description Confirm the phone number of the profile with the code received by SMS
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID owning the profile"
+// @Param input body profileModel.ConfirmPhoneVerificationDTO true "Verification code"
+// @Success 200 {object} profilePresenter.ProfileResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /profile/{id}/phone/verification/confirm [post]
+func (h *ProfileHandler) ConfirmPhoneVerificationHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+
+	var payload m.ConfirmPhoneVerificationDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.ProfileService.ConfirmPhoneVerification(profileService.ConfirmPhoneVerificationFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+		Code:   *payload.Code,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
+}
@@ -0,0 +1,89 @@
+package profileHandler
+
+import (
+	"net/http"
+
+	m "github.com/aragornz325/piloto-api/internal/profile/model"
+	profilePresenter "github.com/aragornz325/piloto-api/internal/profile/presenter"
+	profileService "github.com/aragornz325/piloto-api/internal/profile/service"
+	userPresenter "github.com/aragornz325/piloto-api/internal/user/presenter"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Update profile privacy
+// @Description Set the visibility (public, authenticated or private) of profile fields. Configurable fields: bio, avatar, instagram, facebook, twitter, website, street, city, state, zip_code, country, location, phone_number, whatsapp. Fields not sent keep their visibility.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID owning the profile"
+// @Param If-Match header string true "ETag of the profile being updated"
+// @Param input body profileModel.UpdatePrivacyDTO true "Visibility per field"
+// @Success 200 {object} profilePresenter.ProfileOwnerResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Router /profile/{id}/privacy [put]
+func (h *ProfileHandler) UpdatePrivacyHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var payload m.UpdatePrivacyDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.ProfileService.UpdatePrivacy(profileService.UpdatePrivacyFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		Privacy:         payload.Privacy,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, profilePresenter.Present(result, h.audienceFor(c, result.UserId)))
+}
+
+// @Summary Get public profile
+// @Description Get the public profile of a user: name and the profile fields the viewer is allowed to see according to the owner's privacy settings. Anonymous requests only see public fields.
+// @Tags profile
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} userPresenter.UserPublicResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /profile/{id}/public [get]
+func (h *ProfileHandler) GetPublicProfileHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid ID"})
+		return
+	}
+
+	user, err := h.ProfileService.GetPublicProfile(profileService.GeProfileByUserIdFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, userPresenter.PresentForViewer(c.Request.Context(), user))
+}
@@ -0,0 +1,240 @@
+package profileModel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// completenessRule define un ítem del checklist de onboarding: cómo saber si el perfil lo
+// cumple y la misma condición en SQL (sobre la tabla profiles) para poder filtrar por puntaje.
+// Los ítems de dirección miran la dirección por defecto del usuario, así que Done necesita
+// el perfil con Addresses cargado.
+type completenessRule struct {
+	Key       string
+	Label     string
+	Done      func(p *Profile) bool
+	Condition string
+}
+
+// completenessRules son los ítems que componen el puntaje, en el orden del checklist.
+var completenessRules = []completenessRule{
+	{
+		Key:       "avatar",
+		Label:     "Upload a profile picture",
+		Done:      func(p *Profile) bool { return !blank(p.Avatar) },
+		Condition: filledSQL("profiles.avatar"),
+	},
+	{
+		Key:       "bio",
+		Label:     "Write a short bio",
+		Done:      func(p *Profile) bool { return !blank(p.Bio) },
+		Condition: filledSQL("profiles.bio"),
+	},
+	{
+		Key:       "handle",
+		Label:     "Choose a public handle",
+		Done:      func(p *Profile) bool { return p.Handle != nil },
+		Condition: "profiles.handle IS NOT NULL",
+	},
+	{
+		Key:   "address",
+		Label: "Add your city and country",
+		Done: func(p *Profile) bool {
+			address := p.DefaultAddress()
+			return address != nil && !blank(address.City) && !blank(address.Country)
+		},
+		Condition: defaultAddressSQL(filledSQL("a.city") + " AND " + filledSQL("a.country")),
+	},
+	{
+		Key:   "location",
+		Label: "Add an address we can locate on the map",
+		Done: func(p *Profile) bool {
+			address := p.DefaultAddress()
+			return address != nil && address.Latitude != nil && address.Longitude != nil
+		},
+		Condition: defaultAddressSQL("a.latitude IS NOT NULL AND a.longitude IS NOT NULL"),
+	},
+	{
+		Key:       "phone_number",
+		Label:     "Add a phone number",
+		Done:      func(p *Profile) bool { return !blank(p.PhoneNumber) },
+		Condition: filledSQL("profiles.phone_number"),
+	},
+	{
+		Key:       "phone_verified",
+		Label:     "Verify your phone number",
+		Done:      func(p *Profile) bool { return p.PhoneVerifiedAt != nil },
+		Condition: "profiles.phone_verified_at IS NOT NULL",
+	},
+	{
+		Key:       "whatsapp",
+		Label:     "Add a WhatsApp number",
+		Done:      func(p *Profile) bool { return !blank(p.Whatsapp) },
+		Condition: filledSQL("profiles.whatsapp"),
+	},
+	{
+		Key:   "social",
+		Label: "Link a social network or website",
+		Done: func(p *Profile) bool {
+			return !blank(p.InstagramHandle) || !blank(p.FacebookHandle) || !blank(p.TwitterHandle) || !blank(p.Website)
+		},
+		Condition: "(" + strings.Join([]string{
+			filledSQL("profiles.instagram_handle"),
+			filledSQL("profiles.facebook_handle"),
+			filledSQL("profiles.twitter_handle"),
+			filledSQL("profiles.website"),
+		}, " OR ") + ")",
+	},
+}
+
+// DefaultCompletenessWeights es el peso de cada ítem del checklist. Suman 100, pero el
+// puntaje se normaliza así que no es obligatorio.
+var DefaultCompletenessWeights = map[string]int{
+	"avatar":         15,
+	"bio":            10,
+	"handle":         10,
+	"address":        10,
+	"location":       10,
+	"phone_number":   10,
+	"phone_verified": 20,
+	"whatsapp":       5,
+	"social":         10,
+}
+
+// CompletenessItem es un ítem del checklist evaluado para un perfil.
+type CompletenessItem struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Weight int    `json:"weight"`
+	Done   bool   `json:"done"`
+}
+
+// Completeness es el puntaje de completitud de un perfil (0 a 100) con el detalle de cada ítem.
+type Completeness struct {
+	Score int                `json:"score"`
+	Items []CompletenessItem `json:"items"`
+}
+
+// Missing devuelve los ítems pendientes, primero los que más suman al puntaje.
+func (c Completeness) Missing() []CompletenessItem {
+	missing := []CompletenessItem{}
+	for _, item := range c.Items {
+		if !item.Done {
+			missing = append(missing, item)
+		}
+	}
+	sort.SliceStable(missing, func(i, j int) bool { return missing[i].Weight > missing[j].Weight })
+	return missing
+}
+
+// ParseCompletenessWeights lee pesos con el formato "avatar=20,bio=5" y los aplica sobre
+// DefaultCompletenessWeights. Un peso 0 desactiva el ítem. Un valor vacío devuelve los
+// pesos por defecto.
+func ParseCompletenessWeights(raw string) (map[string]int, error) {
+	weights := make(map[string]int, len(DefaultCompletenessWeights))
+	for key, weight := range DefaultCompletenessWeights {
+		weights[key] = weight
+	}
+
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !found {
+			return nil, fmt.Errorf("invalid completeness weight %q, expected key=weight", entry)
+		}
+		if _, ok := DefaultCompletenessWeights[key]; !ok {
+			return nil, fmt.Errorf("unknown completeness item %q", key)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight for completeness item %q", key)
+		}
+		weights[key] = weight
+	}
+
+	if totalWeight(weights) == 0 {
+		return nil, fmt.Errorf("completeness weights cannot all be zero")
+	}
+	return weights, nil
+}
+
+// ComputeCompleteness evalúa el checklist sobre el perfil con los pesos indicados. Los
+// ítems con peso 0 no se incluyen. Un perfil nil se evalúa como vacío.
+func ComputeCompleteness(profile *Profile, weights map[string]int) Completeness {
+	if profile == nil {
+		profile = &Profile{}
+	}
+	result := Completeness{Items: []CompletenessItem{}}
+	done := 0
+	for _, rule := range completenessRules {
+		weight := weights[rule.Key]
+		if weight <= 0 {
+			continue
+		}
+		item := CompletenessItem{
+			Key:    rule.Key,
+			Label:  rule.Label,
+			Weight: weight,
+			Done:   rule.Done(profile),
+		}
+		if item.Done {
+			done += weight
+		}
+		result.Items = append(result.Items, item)
+	}
+	if total := totalWeight(weights); total > 0 {
+		result.Score = int(math.Round(float64(done) * 100 / float64(total)))
+	}
+	return result
+}
+
+// CompletenessScoreSQL devuelve una expresión SQL con el puntaje (0 a 100) de la fila de
+// profiles, calculado igual que ComputeCompleteness. Los pesos son enteros de la
+// configuración, nunca entrada del usuario.
+func CompletenessScoreSQL(weights map[string]int) string {
+	terms := []string{}
+	for _, rule := range completenessRules {
+		if weight := weights[rule.Key]; weight > 0 {
+			terms = append(terms, fmt.Sprintf("CASE WHEN %s THEN %d ELSE 0 END", rule.Condition, weight))
+		}
+	}
+	total := totalWeight(weights)
+	if len(terms) == 0 || total == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("ROUND((%s) * 100.0 / %d)", strings.Join(terms, " + "), total)
+}
+
+func totalWeight(weights map[string]int) int {
+	total := 0
+	for _, rule := range completenessRules {
+		if weight := weights[rule.Key]; weight > 0 {
+			total += weight
+		}
+	}
+	return total
+}
+
+// blank indica si un campo está vacío o tiene el valor por defecto de CopyNonNilFields.
+func blank(value string) bool {
+	value = strings.TrimSpace(value)
+	return value == "" || strings.EqualFold(value, "no information")
+}
+
+// filledSQL es la versión SQL de !blank para una columna de texto.
+func filledSQL(column string) string {
+	return fmt.Sprintf("LOWER(TRIM(COALESCE(%s, ''))) NOT IN ('', 'no information')", column)
+}
+
+// defaultAddressSQL es la condición de que el usuario del perfil tenga una dirección por
+// defecto que cumpla condition (sobre el alias a).
+func defaultAddressSQL(condition string) string {
+	return "EXISTS (SELECT 1 FROM addresses a WHERE a.user_id = profiles.user_id AND a.is_default AND a.deleted_at IS NULL AND " + condition + ")"
+}
@@ -0,0 +1,33 @@
+package profileModel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HandleRedirect mantiene un handle anterior apuntando al usuario durante el período de
+// gracia posterior a un cambio. Mientras está vigente nadie más puede reclamar ese handle.
+type HandleRedirect struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	OldHandle string    `gorm:"uniqueIndex;not null" json:"old_handle"`
+	UserId    uuid.UUID `gorm:"type:uuid;index;not null" json:"user_id"`
+	ExpiresAt time.Time `gorm:"index;not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReservedHandles son palabras que no se pueden usar como handle porque colisionan con
+// rutas de la API o pueden usarse para suplantar al equipo.
+var ReservedHandles = map[string]bool{
+	"admin": true, "administrator": true, "root": true, "system": true, "support": true,
+	"help": true, "staff": true, "moderator": true, "official": true, "security": true,
+	"api": true, "auth": true, "login": true, "logout": true, "register": true, "signup": true,
+	"me": true, "profile": true, "profiles": true, "user": true, "users": true, "settings": true,
+	"public": true, "nearby": true, "handle": true, "invitations": true, "media": true,
+	"null": true, "undefined": true, "anonymous": true, "everyone": true,
+	"piloto": true, "pilotodetormenta": true,
+}
+
+type ClaimHandleDTO struct {
+	Handle *string `json:"handle" binding:"required"`
+}
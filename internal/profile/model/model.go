@@ -1,6 +1,9 @@
 package profileModel
 
 import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/internal/address/model"
 	"github.com/aragornz325/piloto-api/pkg/model"
 	"github.com/google/uuid"
 )
@@ -8,34 +11,122 @@ import (
 type Profile struct {
 	baseModel.BaseModel
 	UserId uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	// Handle es el nombre público único del perfil, siempre en minúsculas; nil hasta que se reclama.
+	Handle          		*string    `gorm:"uniqueIndex" json:"handle"`
+	HandleChangedAt 		*time.Time `json:"handle_changed_at"`
 	Bio          			string `json:"bio"`
 	Avatar       			string `json:"avatar"`
+	AvatarThumbnails        map[string]string `gorm:"type:jsonb;serializer:json" json:"avatar_thumbnails,omitempty"`
+	// Los links sociales se guardan canónicos: el handle en minúsculas y la URL del perfil.
 	InstagramURL 			string `json:"instagram_url"`
+	InstagramHandle 		string `json:"instagram_handle"`
 	FacebookURL  			string `json:"facebook_url"`
+	FacebookHandle  		string `json:"facebook_handle"`
 	TwitterURL   			string `json:"twitter_url"`
-	Street       			string `json:"street"`
-	City         			string `json:"city"`
-	State        			string `json:"state"`
-	ZipCode      			string `json:"zip_code"`
-	Country      			string `json:"country"`
+	TwitterHandle   		string `json:"twitter_handle"`
+	// PhoneNumber y Whatsapp se guardan en formato E.164.
 	PhoneNumber  			string `json:"phone_number"`
+	// PhoneVerifiedAt se completa al confirmar el código enviado por SMS y se borra si cambia el número.
+	PhoneVerifiedAt 		*time.Time `json:"phone_verified_at"`
 	Website      			string `json:"website"`
 	Whatsapp     			string `json:"whatsapp"`
+	// Timezone es la zona horaria IANA del usuario (ej. "America/Argentina/Buenos_Aires"); vacía es UTC.
+	Timezone     			string `json:"timezone"`
+	// Privacy guarda la visibilidad elegida para cada campo configurable (ver DefaultPrivacy).
+	Privacy      			map[string]string `gorm:"type:jsonb;serializer:json" json:"privacy,omitempty"`
+	// Promedio y cantidad de calificaciones visibles recibidas como conductor y como pasajero.
+	// Los recalcula ratingService; no se editan con el perfil ni cambian su versión.
+	DriverRatingAverage 	float64 `gorm:"not null;default:0" json:"driver_rating_average"`
+	DriverRatingCount   	int64   `gorm:"not null;default:0" json:"driver_rating_count"`
+	RiderRatingAverage  	float64 `gorm:"not null;default:0" json:"rider_rating_average"`
+	RiderRatingCount    	int64   `gorm:"not null;default:0" json:"rider_rating_count"`
+	// Addresses son las direcciones del usuario; la ubicación del perfil es la por defecto
+	// (ver DefaultAddress). Solo se cargan con Preload y no se guardan con el perfil.
+	Addresses []*addressModel.Address `gorm:"foreignKey:UserId;references:UserId;constraint:-" json:"-"`
+}
+
+// DefaultAddress devuelve la dirección por defecto del usuario, que es la ubicación pública
+// del perfil, o nil si no tiene una o no se cargaron las direcciones.
+func (p *Profile) DefaultAddress() *addressModel.Address {
+	if p == nil {
+		return nil
+	}
+	for _, address := range p.Addresses {
+		if address.IsDefault {
+			return address
+		}
+	}
+	return nil
+}
+
+// TrackHistory hace que las altas, cambios y bajas de perfiles queden en entity_history.
+func (Profile) TrackHistory() bool {
+	return true
 }
 
+// UserProfileDTO son los datos editables del perfil. La dirección no se edita acá: es la
+// dirección por defecto de /users/{id}/addresses.
 type UserProfileDTO struct {
 	UserId       *uuid.UUID `json:"user_id" binding:"required"`
 	Bio          *string `json:"bio" binding:"omitempty"`
 	Avatar       *string `json:"avatar" binding:"omitempty"`
+	// Los links sociales aceptan un handle ("@usuario") o una URL de la red.
 	InstagramURL *string `json:"instagram_url" binding:"omitempty"`
 	FacebookURL  *string `json:"facebook_url" binding:"omitempty"`
 	TwitterURL   *string `json:"twitter_url" binding:"omitempty"`
-	Street       *string `json:"street" binding:"omitempty"`
-	City         *string `json:"city" binding:"omitempty"`
-	State        *string `json:"state" binding:"omitempty"`
-	ZipCode      *string `json:"zip_code" binding:"omitempty"`
-	Country      *string `json:"country" binding:"omitempty"`
 	PhoneNumber  *string `json:"phone_number" binding:"omitempty"`
 	Website      *string `json:"website" binding:"omitempty"`
 	Whatsapp     *string `json:"whatsapp" binding:"omitempty"`
+	Timezone     *string `json:"timezone" binding:"omitempty"`
 }
+
+// SelectableFields mapea los nombres que acepta ?fields= a columnas de la tabla profiles.
+// Los campos de la dirección salen de la dirección por defecto, que se carga por user_id.
+var SelectableFields = map[string]string{
+	"id":            "id",
+	"createdAt":     "created_at",
+	"updatedAt":     "updated_at",
+	"isActive":      "is_active",
+	"version":       "version",
+	"user_id":       "user_id",
+	"handle":        "handle",
+	"bio":           "bio",
+	"avatar":        "avatar",
+	"avatar_thumbnails": "avatar_thumbnails",
+	"instagram_url": "instagram_url",
+	"facebook_url":  "facebook_url",
+	"twitter_url":   "twitter_url",
+	"instagram_handle": "instagram_handle",
+	"facebook_handle":  "facebook_handle",
+	"twitter_handle":   "twitter_handle",
+	"street":        "user_id",
+	"city":          "user_id",
+	"state":         "user_id",
+	"zip_code":      "user_id",
+	"country":       "user_id",
+	"latitude":      "user_id",
+	"longitude":     "user_id",
+	"geocoded_at":   "user_id",
+	"phone_number":  "phone_number",
+	"phone_verified_at": "phone_verified_at",
+	"website":       "website",
+	"whatsapp":      "whatsapp",
+	"timezone":      "timezone",
+	"privacy":       "privacy",
+}
+
+// Location devuelve la zona horaria del perfil, o UTC si no tiene una válida.
+func (p *Profile) Location() *time.Location {
+	if p == nil || p.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Includes mapea los nombres que acepta ?include= a asociaciones de Profile.
+// Profile no tiene relaciones expandibles por ahora.
+var Includes = map[string]string{}
@@ -0,0 +1,32 @@
+package profileModel
+
+import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+const (
+	PhoneVerificationPending   = "pending"
+	PhoneVerificationVerified  = "verified"
+	PhoneVerificationCancelled = "cancelled"
+	PhoneVerificationExpired   = "expired"
+)
+
+// PhoneVerification guarda un código de verificación enviado por SMS al teléfono del perfil.
+// Solo se guarda el hash del código; Attempts cuenta los intentos fallidos.
+type PhoneVerification struct {
+	baseModel.BaseModel
+	UserId     uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	Phone      string     `gorm:"not null" json:"phone"`
+	Status     string     `gorm:"index;not null;default:pending" json:"status"`
+	CodeHash   string     `gorm:"not null" json:"-"`
+	Attempts   int        `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+type ConfirmPhoneVerificationDTO struct {
+	Code *string `json:"code" binding:"required,len=6,numeric"`
+}
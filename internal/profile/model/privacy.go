@@ -0,0 +1,59 @@
+package profileModel
+
+// Niveles de visibilidad de un campo del perfil. El dueño y los admins ven siempre todo.
+const (
+	VisibilityPublic        = "public"
+	VisibilityAuthenticated = "authenticated"
+	VisibilityPrivate       = "private"
+)
+
+// DefaultPrivacy es la visibilidad de cada campo configurable cuando el usuario no la eligió.
+// Las claves son también la lista de campos que acepta PUT /profile/{id}/privacy; algunas
+// agrupan varias propiedades de la respuesta (instagram cubre la URL y el handle, location
+// las coordenadas, phone_number la fecha de verificación).
+var DefaultPrivacy = map[string]string{
+	"bio":          VisibilityPublic,
+	"avatar":       VisibilityPublic,
+	"instagram":    VisibilityPublic,
+	"facebook":     VisibilityPublic,
+	"twitter":      VisibilityPublic,
+	"website":      VisibilityPublic,
+	"street":       VisibilityPrivate,
+	"city":         VisibilityPublic,
+	"state":        VisibilityPublic,
+	"zip_code":     VisibilityPrivate,
+	"country":      VisibilityPublic,
+	"location":     VisibilityAuthenticated,
+	"phone_number": VisibilityPrivate,
+	"whatsapp":     VisibilityAuthenticated,
+}
+
+// IsVisibility indica si value es un nivel de visibilidad válido.
+func IsVisibility(value string) bool {
+	return value == VisibilityPublic || value == VisibilityAuthenticated || value == VisibilityPrivate
+}
+
+// VisibilityOf devuelve la visibilidad configurada para field, o la de DefaultPrivacy.
+func (p *Profile) VisibilityOf(field string) string {
+	if visibility, ok := p.Privacy[field]; ok && IsVisibility(visibility) {
+		return visibility
+	}
+	if visibility, ok := DefaultPrivacy[field]; ok {
+		return visibility
+	}
+	return VisibilityPublic
+}
+
+// EffectivePrivacy devuelve la visibilidad de todos los campos configurables, completando
+// con los valores por defecto los que el usuario no eligió.
+func (p *Profile) EffectivePrivacy() map[string]string {
+	privacy := make(map[string]string, len(DefaultPrivacy))
+	for field := range DefaultPrivacy {
+		privacy[field] = p.VisibilityOf(field)
+	}
+	return privacy
+}
+
+type UpdatePrivacyDTO struct {
+	Privacy map[string]string `json:"privacy" binding:"required"`
+}
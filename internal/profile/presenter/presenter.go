@@ -0,0 +1,226 @@
+package profilePresenter
+
+import (
+	"time"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	"github.com/aragornz325/piloto-api/pkg/phone"
+	"github.com/aragornz325/piloto-api/pkg/presenter"
+	"github.com/google/uuid"
+)
+
+// ProfileResponse es la representación de un perfil que se devuelve a los clientes.
+// Los campos con privacidad configurable se omiten cuando el que consulta no puede verlos.
+type ProfileResponse struct {
+	ID               uuid.UUID         `json:"id"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+	Version          int64             `json:"version"`
+	UserId           uuid.UUID         `json:"user_id"`
+	Handle           string            `json:"handle,omitempty"`
+	Bio              string            `json:"bio,omitempty"`
+	Avatar           string            `json:"avatar,omitempty"`
+	AvatarThumbnails map[string]string `json:"avatar_thumbnails,omitempty"`
+	InstagramURL     string            `json:"instagram_url,omitempty"`
+	InstagramHandle  string            `json:"instagram_handle,omitempty"`
+	FacebookURL      string            `json:"facebook_url,omitempty"`
+	FacebookHandle   string            `json:"facebook_handle,omitempty"`
+	TwitterURL       string            `json:"twitter_url,omitempty"`
+	TwitterHandle    string            `json:"twitter_handle,omitempty"`
+	Street           string            `json:"street,omitempty"`
+	City             string            `json:"city,omitempty"`
+	State            string            `json:"state,omitempty"`
+	ZipCode          string            `json:"zip_code,omitempty"`
+	Country          string            `json:"country,omitempty"`
+	Latitude         *float64          `json:"latitude,omitempty"`
+	Longitude        *float64          `json:"longitude,omitempty"`
+	PhoneNumber      string            `json:"phone_number,omitempty"`
+	PhoneVerifiedAt  *time.Time        `json:"phone_verified_at,omitempty"`
+	Website          string            `json:"website,omitempty"`
+	Whatsapp         string            `json:"whatsapp,omitempty"`
+	DriverRating     RatingSummary     `json:"driver_rating"`
+	RiderRating      RatingSummary     `json:"rider_rating"`
+}
+
+// RatingSummary es el promedio (1 a 5, 0 sin calificaciones) y la cantidad de calificaciones
+// recibidas en un rol.
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int64   `json:"count"`
+}
+
+// ProfileOwnerResponse es lo que ve el dueño de su perfil: todos los campos y su configuración de privacidad.
+type ProfileOwnerResponse struct {
+	ProfileResponse
+	Timezone string            `json:"timezone,omitempty"`
+	Privacy  map[string]string `json:"privacy"`
+}
+
+// ProfileAdminResponse agrega los datos internos que solo ve un admin.
+type ProfileAdminResponse struct {
+	ProfileOwnerResponse
+	IsActive   bool       `json:"isActive"`
+	GeocodedAt *time.Time `json:"geocoded_at"`
+}
+
+// NearbyProfileResponse es un resultado de /profile/nearby: el perfil serializado para la
+// audiencia del que consulta y su distancia al punto buscado.
+type NearbyProfileResponse struct {
+	Profile    interface{} `json:"profile"`
+	DistanceKm float64     `json:"distance_km"`
+}
+
+// PhoneVerificationResponse describe un código de verificación enviado, sin exponer el código.
+type PhoneVerificationResponse struct {
+	Phone     string    `json:"phone"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PresentPhoneVerification serializa una verificación pendiente con el teléfono enmascarado.
+func PresentPhoneVerification(verification *profileModel.PhoneVerification) PhoneVerificationResponse {
+	return PhoneVerificationResponse{
+		Phone:     phone.Mask(verification.Phone),
+		ExpiresAt: verification.ExpiresAt,
+	}
+}
+
+// CanView indica si la audiencia puede ver un campo configurable del perfil (ver
+// profileModel.DefaultPrivacy). El dueño y los admins ven todos los campos.
+func CanView(profile *profileModel.Profile, field string, audience presenter.Audience) bool {
+	if audience == presenter.AudienceSelf || audience == presenter.AudienceAdmin {
+		return true
+	}
+	switch profile.VisibilityOf(field) {
+	case profileModel.VisibilityPublic:
+		return true
+	case profileModel.VisibilityAuthenticated:
+		return audience == presenter.AudienceAuthenticated
+	default:
+		return false
+	}
+}
+
+// Present serializa un perfil para la audiencia indicada, aplicando la privacidad de cada campo.
+func Present(profile *profileModel.Profile, audience presenter.Audience) interface{} {
+	if profile == nil {
+		return nil
+	}
+	response := ProfileResponse{
+		ID:        profile.ID,
+		CreatedAt: profile.CreatedAt,
+		UpdatedAt: profile.UpdatedAt,
+		Version:   profile.Version,
+		UserId:    profile.UserId,
+		DriverRating: RatingSummary{
+			Average: profile.DriverRatingAverage,
+			Count:   profile.DriverRatingCount,
+		},
+		RiderRating: RatingSummary{
+			Average: profile.RiderRatingAverage,
+			Count:   profile.RiderRatingCount,
+		},
+	}
+	if profile.Handle != nil {
+		response.Handle = *profile.Handle
+	}
+	visible := func(field string) bool {
+		return CanView(profile, field, audience)
+	}
+	if visible("bio") {
+		response.Bio = profile.Bio
+	}
+	if visible("avatar") {
+		response.Avatar = profile.Avatar
+		response.AvatarThumbnails = profile.AvatarThumbnails
+	}
+	if visible("instagram") {
+		response.InstagramURL = profile.InstagramURL
+		response.InstagramHandle = profile.InstagramHandle
+	}
+	if visible("facebook") {
+		response.FacebookURL = profile.FacebookURL
+		response.FacebookHandle = profile.FacebookHandle
+	}
+	if visible("twitter") {
+		response.TwitterURL = profile.TwitterURL
+		response.TwitterHandle = profile.TwitterHandle
+	}
+	if visible("website") {
+		response.Website = profile.Website
+	}
+	// La ubicación del perfil es la dirección por defecto del usuario
+	if address := profile.DefaultAddress(); address != nil {
+		if visible("street") {
+			response.Street = address.Street
+		}
+		if visible("city") {
+			response.City = address.City
+		}
+		if visible("state") {
+			response.State = address.State
+		}
+		if visible("zip_code") {
+			response.ZipCode = address.ZipCode
+		}
+		if visible("country") {
+			response.Country = address.Country
+		}
+		if visible("location") {
+			response.Latitude = address.Latitude
+			response.Longitude = address.Longitude
+		}
+	}
+	if visible("phone_number") {
+		response.PhoneNumber = profile.PhoneNumber
+		response.PhoneVerifiedAt = profile.PhoneVerifiedAt
+	}
+	if visible("whatsapp") {
+		response.Whatsapp = profile.Whatsapp
+	}
+
+	switch audience {
+	case presenter.AudienceAdmin:
+		return ProfileAdminResponse{
+			ProfileOwnerResponse: ProfileOwnerResponse{
+				ProfileResponse: response,
+				Timezone:        profile.Timezone,
+				Privacy:         profile.EffectivePrivacy(),
+			},
+			IsActive:   profile.IsActive,
+			GeocodedAt: geocodedAt(profile),
+		}
+	case presenter.AudienceSelf:
+		return ProfileOwnerResponse{
+			ProfileResponse: response,
+			Timezone:        profile.Timezone,
+			Privacy:         profile.EffectivePrivacy(),
+		}
+	default:
+		return response
+	}
+}
+
+// geocodedAt es cuándo se ubicó la dirección por defecto, o nil si no tiene.
+func geocodedAt(profile *profileModel.Profile) *time.Time {
+	if address := profile.DefaultAddress(); address != nil {
+		return address.GeocodedAt
+	}
+	return nil
+}
+
+// CompletenessResponse es el puntaje de completitud con el checklist de onboarding: todos
+// los ítems y, aparte, los pendientes ordenados por cuánto suman.
+type CompletenessResponse struct {
+	Score   int                             `json:"score"`
+	Items   []profileModel.CompletenessItem `json:"items"`
+	Missing []profileModel.CompletenessItem `json:"missing"`
+}
+
+// PresentCompleteness serializa el puntaje de completitud de un perfil.
+func PresentCompleteness(completeness *profileModel.Completeness) CompletenessResponse {
+	return CompletenessResponse{
+		Score:   completeness.Score,
+		Items:   completeness.Items,
+		Missing: completeness.Missing(),
+	}
+}
@@ -0,0 +1,170 @@
+package profilePresenter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	addressModel "github.com/aragornz325/piloto-api/internal/address/model"
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	"github.com/aragornz325/piloto-api/pkg/presenter"
+	"github.com/google/uuid"
+)
+
+var audiences = []presenter.Audience{
+	presenter.AudiencePublic,
+	presenter.AudienceAuthenticated,
+	presenter.AudienceSelf,
+	presenter.AudienceAdmin,
+}
+
+// Claves que ninguna respuesta puede tener, para ninguna audiencia.
+var forbiddenKeys = []string{"password", "code", "code_hash", "token", "token_hash", "otp"}
+
+func TestPresentNeverExposesSecrets(t *testing.T) {
+	profile := fullProfile()
+	for _, audience := range audiences {
+		t.Run(string(audience), func(t *testing.T) {
+			keys := marshalKeys(t, Present(profile, audience))
+			for _, key := range forbiddenKeys {
+				if keys[key] {
+					t.Errorf("response for %s has key %q", audience, key)
+				}
+			}
+		})
+	}
+}
+
+func TestPresentAppliesPrivacy(t *testing.T) {
+	profile := fullProfile()
+	tests := []struct {
+		audience presenter.Audience
+		present  []string
+		absent   []string
+	}{
+		{
+			audience: presenter.AudiencePublic,
+			present:  []string{"bio", "city"},
+			absent:   []string{"street", "zip_code", "phone_number", "phone_verified_at", "latitude", "whatsapp", "timezone", "privacy", "isActive", "geocoded_at"},
+		},
+		{
+			audience: presenter.AudienceAuthenticated,
+			present:  []string{"bio", "city", "latitude", "longitude", "whatsapp"},
+			absent:   []string{"street", "zip_code", "phone_number", "phone_verified_at", "timezone", "privacy", "isActive", "geocoded_at"},
+		},
+		{
+			audience: presenter.AudienceSelf,
+			present:  []string{"street", "zip_code", "phone_number", "phone_verified_at", "timezone", "privacy"},
+			absent:   []string{"isActive", "geocoded_at"},
+		},
+		{
+			audience: presenter.AudienceAdmin,
+			present:  []string{"street", "zip_code", "phone_number", "timezone", "privacy", "isActive", "geocoded_at"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.audience), func(t *testing.T) {
+			keys := marshalKeys(t, Present(profile, tt.audience))
+			for _, key := range tt.present {
+				if !keys[key] {
+					t.Errorf("response for %s is missing %q", tt.audience, key)
+				}
+			}
+			for _, key := range tt.absent {
+				if keys[key] {
+					t.Errorf("response for %s has %q", tt.audience, key)
+				}
+			}
+		})
+	}
+}
+
+func TestPresentPhoneVerificationHidesCode(t *testing.T) {
+	verification := &profileModel.PhoneVerification{
+		UserId:    uuid.New(),
+		Phone:     "+5491155551234",
+		Status:    profileModel.PhoneVerificationPending,
+		CodeHash:  "code-hash-secret",
+		ExpiresAt: time.Now().UTC(),
+	}
+	data, err := json.Marshal(PresentPhoneVerification(verification))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), verification.CodeHash) {
+		t.Errorf("response has the code hash: %s", data)
+	}
+	if strings.Contains(string(data), verification.Phone) {
+		t.Errorf("response has the unmasked phone: %s", data)
+	}
+	keys := marshalKeys(t, PresentPhoneVerification(verification))
+	for _, key := range forbiddenKeys {
+		if keys[key] {
+			t.Errorf("response has key %q", key)
+		}
+	}
+}
+
+func fullProfile() *profileModel.Profile {
+	now := time.Now().UTC()
+	latitude, longitude := -34.6, -58.4
+	handle := "piloto"
+	profile := &profileModel.Profile{
+		UserId:          uuid.New(),
+		Handle:          &handle,
+		Bio:             "bio",
+		Avatar:          "https://cdn.example.com/avatar.png",
+		InstagramURL:    "https://instagram.com/piloto",
+		InstagramHandle: "piloto",
+		Addresses: []*addressModel.Address{{
+			Street:     "Av. Siempre Viva 742",
+			City:       "Buenos Aires",
+			State:      "CABA",
+			ZipCode:    "1000",
+			Country:    "AR",
+			Latitude:   &latitude,
+			Longitude:  &longitude,
+			GeocodedAt: &now,
+			IsDefault:  true,
+		}},
+		PhoneNumber:     "+5491155551234",
+		PhoneVerifiedAt: &now,
+		Website:         "https://example.com",
+		Whatsapp:        "+5491155551234",
+		Timezone:        "America/Argentina/Buenos_Aires",
+	}
+	profile.ID = uuid.New()
+	profile.IsActive = true
+	return profile
+}
+
+// marshalKeys serializa value y devuelve todas las claves del JSON, a cualquier profundidad.
+func marshalKeys(t *testing.T, value interface{}) map[string]bool {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	keys := map[string]bool{}
+	collectKeys(decoded, keys)
+	return keys
+}
+
+func collectKeys(value interface{}, keys map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			keys[key] = true
+			collectKeys(child, keys)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectKeys(child, keys)
+		}
+	}
+}
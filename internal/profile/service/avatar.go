@@ -0,0 +1,158 @@
+package profileService
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/imaging"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/storage"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxAvatarBytes es el tamaño máximo aceptado para el archivo subido.
+	MaxAvatarBytes = 5 << 20
+	// avatarSize es el lado máximo de la imagen principal del avatar.
+	avatarSize = 512
+)
+
+// avatarThumbnailSizes son los lados de las miniaturas que se generan para cada avatar.
+var avatarThumbnailSizes = []int{256, 128, 64}
+
+// UploadAvatar validates and processes an uploaded avatar image, stores it together with
+// its thumbnails through the configured BlobStorage and sets Profile.Avatar to the URL of
+// the main image. The image is re-encoded, which strips EXIF and any other metadata. The
+// objects of the previous avatar are deleted once the new one is saved.
+//
+// Parameters:
+//   - opts: UploadAvatarFuncParams containing the context, the user ID and the raw file.
+//
+// Returns:
+//   - *profileModel.Profile: The updated profile.
+//   - error: A 400 if the file is not a supported image or is too large, or any other error.
+func (s *profileService) UploadAvatar(opts UploadAvatarFuncParams) (*profileModel.Profile, error) {
+	var profile *profileModel.Profile
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "UploadAvatar",
+		ServiceName: "profile",
+		Operation: func() error {
+			if len(opts.Data) == 0 || len(opts.Data) > MaxAvatarBytes {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: fmt.Sprintf("avatar must be between 1 byte and %d bytes", MaxAvatarBytes),
+				})
+			}
+
+			var err error
+			profile, err = s.GetUserProfile(GeProfileByUserIdFuncParams{
+				Ctx:    opts.Ctx,
+				UserId: opts.UserId,
+			})
+			if err != nil {
+				return err
+			}
+
+			main, thumbnails, err := imaging.Process(opts.Data, imaging.ProcessFuncParams{
+				MaxSize:    avatarSize,
+				Thumbnails: avatarThumbnailSizes,
+			})
+			if err != nil {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: "invalid avatar image",
+					Err:     err,
+				})
+			}
+
+			// Cada subida usa un prefijo nuevo para que los clientes no vean versiones cacheadas
+			prefix := fmt.Sprintf("avatars/%s/%s", opts.UserId, uuid.NewString())
+			avatarURL, err := s.Storage.Put(storage.PutObjectFuncParams{
+				Ctx:         opts.Ctx,
+				Key:         prefix + "." + main.Extension,
+				ContentType: main.ContentType,
+				Data:        main.Data,
+			})
+			if err != nil {
+				return fmt.Errorf("error storing avatar: %w", err)
+			}
+
+			thumbnailURLs := make(map[string]string, len(thumbnails))
+			for size, thumbnail := range thumbnails {
+				thumbnailURL, err := s.Storage.Put(storage.PutObjectFuncParams{
+					Ctx:         opts.Ctx,
+					Key:         fmt.Sprintf("%s_%d.%s", prefix, size, thumbnail.Extension),
+					ContentType: thumbnail.ContentType,
+					Data:        thumbnail.Data,
+				})
+				if err != nil {
+					return fmt.Errorf("error storing avatar thumbnail: %w", err)
+				}
+				thumbnailURLs[strconv.Itoa(size)] = thumbnailURL
+			}
+
+			previousAvatar, previousThumbnails := profile.Avatar, profile.AvatarThumbnails
+			expectedVersion := profile.Version
+			update := profileModel.Profile{
+				Avatar:           avatarURL,
+				AvatarThumbnails: thumbnailURLs,
+			}
+			update.Version = expectedVersion + 1
+			update.UpdatedAt = time.Now().UTC()
+			result := db.DB.WithContext(opts.Ctx).
+				Model(profile).
+				Where("version = ?", expectedVersion).
+				Updates(&update)
+			if result.Error != nil {
+				return fmt.Errorf("error updating avatar: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "profile was modified by another request",
+				})
+			}
+			profile.Avatar = update.Avatar
+			profile.AvatarThumbnails = update.AvatarThumbnails
+			profile.Version = update.Version
+			profile.UpdatedAt = update.UpdatedAt
+
+			s.deleteAvatarObjects(opts.Ctx, opts.UserId, previousAvatar, previousThumbnails)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// deleteAvatarObjects borra del storage la imagen y las miniaturas de un avatar reemplazado.
+// Solo toca objetos bajo avatars/<userId>/ (Avatar también puede ser una URL externa) y un
+// error no falla la subida: a lo sumo queda un archivo huérfano.
+func (s *profileService) deleteAvatarObjects(ctx context.Context, userId uuid.UUID, avatar string, thumbnails map[string]string) {
+	urls := make([]string, 0, len(thumbnails)+1)
+	urls = append(urls, avatar)
+	for _, thumbnailURL := range thumbnails {
+		urls = append(urls, thumbnailURL)
+	}
+
+	base := s.Storage.URL("")
+	prefix := fmt.Sprintf("avatars/%s/", userId)
+	for _, objectURL := range urls {
+		key, err := url.PathUnescape(strings.TrimPrefix(objectURL, base))
+		if err != nil || !strings.HasPrefix(objectURL, base) || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := s.Storage.Delete(storage.DeleteObjectFuncParams{Ctx: ctx, Key: key}); err != nil {
+			logger.Log.Warn("⚠️ Error al borrar el avatar anterior", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package profileService
+
+import (
+	"net/http"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+)
+
+// GetCompleteness computes the completeness score of the user's profile using the
+// configured weights, with the checklist of items done and missing. A user without a
+// profile gets a score of 0 and every item missing, so the checklist can guide onboarding.
+//
+// Parameters:
+//   - opts: GeProfileByUserIdFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - *profileModel.Completeness: The score and the evaluated checklist.
+//   - error: Error if the profile cannot be read, otherwise nil.
+func (s *profileService) GetCompleteness(opts GeProfileByUserIdFuncParams) (*profileModel.Completeness, error) {
+	var completeness profileModel.Completeness
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetCompleteness",
+		ServiceName: "profile",
+		Operation: func() error {
+			profile, err := s.GetUserProfile(GeProfileByUserIdFuncParams{
+				Ctx:    opts.Ctx,
+				UserId: opts.UserId,
+			})
+			if err != nil && errors.StatusCode(err, http.StatusInternalServerError) != http.StatusNotFound {
+				return err
+			}
+			completeness = profileModel.ComputeCompleteness(profile, s.CompletenessWeights)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &completeness, nil
+}
@@ -0,0 +1,278 @@
+package profileService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// HandleChangeCooldown es el tiempo mínimo entre dos cambios de handle de un mismo perfil.
+	HandleChangeCooldown = 14 * 24 * time.Hour
+	// HandleRedirectGracePeriod es cuánto tiempo un handle anterior sigue redirigiendo al
+	// perfil y queda reservado para su dueño.
+	HandleRedirectGracePeriod = 30 * 24 * time.Hour
+)
+
+// handlePattern admite de 3 a 30 letras, números, puntos y guiones bajos, empezando y
+// terminando con letra o número.
+var handlePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._]{1,28}[a-z0-9]$`)
+
+// ClaimHandle sets or changes the public handle of a profile. Handles are unique and
+// case-insensitive (stored in lowercase), reserved words are rejected and changes are
+// limited to one every HandleChangeCooldown. The previous handle keeps redirecting to the
+// profile, and stays reserved for its owner, during HandleRedirectGracePeriod.
+//
+// Parameters:
+//   - opts: ClaimHandleFuncParams containing the context, user ID, the requested handle and the expected version.
+//
+// Returns:
+//   - *profileModel.Profile: The profile with the new handle.
+//   - error: A 400 for invalid or reserved handles, 409 if it is taken, 412 on a version mismatch, 429 during the cooldown.
+func (s *profileService) ClaimHandle(opts ClaimHandleFuncParams) (*profileModel.Profile, error) {
+	var profile profileModel.Profile
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ClaimHandle",
+		ServiceName: "profile",
+		Operation: func() error {
+			handle, err := normalizeHandle(opts.Handle)
+			if err != nil {
+				return err
+			}
+
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Preload("Addresses", "is_default = ?", true).
+					Where("user_id = ?", opts.UserId).
+					First(&profile).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "profile not found",
+						})
+					}
+					return fmt.Errorf("error getting profile: %w", err)
+				}
+				if profile.Version != opts.ExpectedVersion {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "profile was modified by another request",
+					})
+				}
+				if profile.Handle != nil && *profile.Handle == handle {
+					return nil
+				}
+
+				now := time.Now().UTC()
+				if profile.Handle != nil && profile.HandleChangedAt != nil {
+					if next := profile.HandleChangedAt.Add(HandleChangeCooldown); now.Before(next) {
+						return errors.NewTooManyRequests(errors.SimpleErrorFuncOptions{
+							Message: fmt.Sprintf("handle can be changed again after %s", next.Format(time.RFC3339)),
+						})
+					}
+				}
+
+				if err := ensureHandleAvailable(tx, handle, opts.UserId, now); err != nil {
+					return err
+				}
+
+				if profile.Handle != nil {
+					if err := tx.Where("old_handle = ?", *profile.Handle).Delete(&profileModel.HandleRedirect{}).Error; err != nil {
+						return fmt.Errorf("error replacing handle redirect: %w", err)
+					}
+					redirect := profileModel.HandleRedirect{
+						ID:        uuid.New(),
+						OldHandle: *profile.Handle,
+						UserId:    opts.UserId,
+						ExpiresAt: now.Add(HandleRedirectGracePeriod),
+						CreatedAt: now,
+					}
+					if err := tx.Create(&redirect).Error; err != nil {
+						return fmt.Errorf("error creating handle redirect: %w", err)
+					}
+				}
+
+				if err := tx.Model(&profile).Updates(map[string]interface{}{
+					"handle":            handle,
+					"handle_changed_at": now,
+					"version":           profile.Version + 1,
+					"updated_at":        now,
+				}).Error; err != nil {
+					if db.IsUniqueViolation(err) {
+						return errors.NewConflict(errors.SimpleErrorFuncOptions{
+							Message: "handle is already taken",
+						})
+					}
+					return fmt.Errorf("error updating handle: %w", err)
+				}
+				profile.Handle = &handle
+				profile.HandleChangedAt = &now
+				profile.Version++
+				profile.UpdatedAt = now
+				return nil
+			})
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// ResolveHandle finds the public profile addressed by a handle. If the handle is a previous
+// handle still in its grace period, the user is not returned and redirectTo holds the
+// current handle instead.
+//
+// Parameters:
+//   - opts: ResolveHandleFuncParams containing the context and the handle.
+//
+// Returns:
+//   - *userModel.User: The user with its Profile, when the handle is current.
+//   - string: The current handle to redirect to, when the handle is a previous one.
+//   - error: A 404 if no profile uses or used the handle.
+func (s *profileService) ResolveHandle(opts ResolveHandleFuncParams) (*userModel.User, string, error) {
+	var user *userModel.User
+	var redirectTo string
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ResolveHandle",
+		ServiceName: "profile",
+		Operation: func() error {
+			handle := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(opts.Handle), "@"))
+			notFound := errors.NewNotFound(errors.SimpleErrorFuncOptions{
+				Message: "profile not found",
+			})
+
+			var profile profileModel.Profile
+			err := db.DB.WithContext(opts.Ctx).Where("handle = ?", handle).First(&profile).Error
+			if err == nil {
+				user, err = s.GetPublicProfile(GeProfileByUserIdFuncParams{
+					Ctx:    opts.Ctx,
+					UserId: profile.UserId,
+				})
+				return err
+			}
+			if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("error getting profile by handle: %w", err)
+			}
+
+			var redirect profileModel.HandleRedirect
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("old_handle = ? AND expires_at > ?", handle, time.Now().UTC()).
+				First(&redirect).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return notFound
+				}
+				return fmt.Errorf("error getting handle redirect: %w", err)
+			}
+			current, err := s.GetUserProfile(GeProfileByUserIdFuncParams{
+				Ctx:    opts.Ctx,
+				UserId: redirect.UserId,
+			})
+			if err != nil {
+				return err
+			}
+			if current.Handle == nil {
+				return notFound
+			}
+			redirectTo = *current.Handle
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return user, redirectTo, nil
+}
+
+// normalizeHandle pasa el handle a minúsculas, quita el "@" inicial y lo valida.
+func normalizeHandle(value string) (string, error) {
+	handle := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "@"))
+	var message string
+	switch {
+	case !handlePattern.MatchString(handle):
+		message = "must be 3 to 30 letters, numbers, dots or underscores, starting and ending with a letter or number"
+	case strings.Contains(handle, ".."):
+		message = "cannot contain consecutive dots"
+	case isNumeric(handle):
+		message = "must contain at least one letter"
+	case profileModel.ReservedHandles[handle]:
+		message = "is reserved"
+	}
+	if message != "" {
+		return "", errors.NewValidation(errors.ValidationErrorFuncOptions{
+			Message: "invalid handle",
+			Fields:  map[string]string{"handle": message},
+		})
+	}
+	return handle, nil
+}
+
+// ensureHandleAvailable devuelve un 409 si otro perfil usa el handle o lo tiene reservado
+// por una redirección vigente. Las redirecciones vencidas, o las del propio usuario que
+// vuelve a su handle anterior, se eliminan.
+func ensureHandleAvailable(tx *gorm.DB, handle string, userId uuid.UUID, now time.Time) error {
+	var count int64
+	if err := tx.Model(&profileModel.Profile{}).
+		Where("handle = ? AND user_id <> ?", handle, userId).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("error checking handle availability: %w", err)
+	}
+	if count > 0 {
+		return errors.NewConflict(errors.SimpleErrorFuncOptions{
+			Message: "handle is already taken",
+		})
+	}
+
+	var redirect profileModel.HandleRedirect
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("old_handle = ?", handle).First(&redirect).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error checking handle redirects: %w", err)
+	}
+	if redirect.UserId != userId && now.Before(redirect.ExpiresAt) {
+		return errors.NewConflict(errors.SimpleErrorFuncOptions{
+			Message: "handle is already taken",
+		})
+	}
+	if err := tx.Delete(&redirect).Error; err != nil {
+		return fmt.Errorf("error releasing handle redirect: %w", err)
+	}
+	return nil
+}
+
+func isNumeric(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// /------ structs ------///
+type ClaimHandleFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	Handle          string
+	ExpectedVersion int64
+}
+
+type ResolveHandleFuncParams struct {
+	Ctx    context.Context
+	Handle string
+}
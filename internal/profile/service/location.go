@@ -0,0 +1,108 @@
+package profileService
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+)
+
+const (
+	// MaxNearbyRadiusKm limita el radio de búsqueda de /profile/nearby.
+	MaxNearbyRadiusKm = 500.0
+	// DefaultNearbyLimit y MaxNearbyLimit acotan la cantidad de perfiles devueltos.
+	DefaultNearbyLimit = 50
+	MaxNearbyLimit     = 200
+)
+
+// NearbyProfile es un perfil encontrado por FindNearbyProfiles junto con su distancia al punto buscado.
+type NearbyProfile struct {
+	Profile    *profileModel.Profile
+	DistanceKm float64
+}
+
+// FindNearbyProfiles returns the profiles whose user's default address lies within RadiusKm
+// of the given point, closest first. Candidates are prefiltered in the database with a
+// latitude/longitude bounding box and the exact distance is computed with haversine.
+//
+// Parameters:
+//   - opts: FindNearbyProfilesFuncParams containing the context, center point, radius and limit.
+//
+// Returns:
+//   - []NearbyProfile: The matching profiles with their distance in kilometers.
+//   - error: A 400 for invalid coordinates or radius, or any other error.
+func (s *profileService) FindNearbyProfiles(opts FindNearbyProfilesFuncParams) ([]NearbyProfile, error) {
+	var nearby []NearbyProfile
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "FindNearbyProfiles",
+		ServiceName: "profile",
+		Operation: func() error {
+			if !opts.Center.Valid() {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: "lat must be between -90 and 90 and lng between -180 and 180",
+				})
+			}
+			if opts.RadiusKm <= 0 || opts.RadiusKm > MaxNearbyRadiusKm {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: fmt.Sprintf("radius must be greater than 0 and at most %g km", MaxNearbyRadiusKm),
+				})
+			}
+			limit := opts.Limit
+			if limit <= 0 {
+				limit = DefaultNearbyLimit
+			}
+			limit = min(limit, MaxNearbyLimit)
+
+			low, high := geo.BoundingBox(opts.Center, opts.RadiusKm)
+			var candidates []*profileModel.Profile
+			if err := db.DB.WithContext(opts.Ctx).
+				Preload("Addresses", "is_default = ?", true).
+				Joins("JOIN addresses a ON a.user_id = profiles.user_id AND a.is_default AND a.deleted_at IS NULL").
+				Where("a.latitude BETWEEN ? AND ?", low.Latitude, high.Latitude).
+				Where("a.longitude BETWEEN ? AND ?", low.Longitude, high.Longitude).
+				Find(&candidates).Error; err != nil {
+				return fmt.Errorf("error searching nearby profiles: %w", err)
+			}
+
+			nearby = make([]NearbyProfile, 0, len(candidates))
+			for _, profile := range candidates {
+				address := profile.DefaultAddress()
+				if address == nil || address.Latitude == nil || address.Longitude == nil {
+					continue
+				}
+				distance := geo.DistanceKm(opts.Center, geo.Coordinates{
+					Latitude:  *address.Latitude,
+					Longitude: *address.Longitude,
+				})
+				if distance <= opts.RadiusKm {
+					nearby = append(nearby, NearbyProfile{Profile: profile, DistanceKm: distance})
+				}
+			}
+			sort.SliceStable(nearby, func(i, j int) bool {
+				return nearby[i].DistanceKm < nearby[j].DistanceKm
+			})
+			if len(nearby) > limit {
+				nearby = nearby[:limit]
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nearby, nil
+}
+
+// /------ structs ------///
+type FindNearbyProfilesFuncParams struct {
+	Ctx      context.Context
+	Center   geo.Coordinates
+	RadiusKm float64
+	Limit    int
+}
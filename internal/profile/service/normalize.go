@@ -0,0 +1,119 @@
+package profileService
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	addressModel "github.com/aragornz325/piloto-api/internal/address/model"
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/social"
+	"github.com/google/uuid"
+)
+
+// prepareProfile valida y normaliza los datos enviados antes de guardarlos: teléfonos, links
+// sociales y zona horaria. current es el perfil guardado, o nil al crear.
+// Si algún campo es inválido devuelve un error de validación con el detalle de todos ellos.
+// En las actualizaciones devuelve además las columnas que hay que dejar en NULL, que
+// Updates(struct) no escribe.
+func (s *profileService) prepareProfile(ctx context.Context, profile *profileModel.Profile, current *profileModel.Profile) (map[string]interface{}, error) {
+	fields := map[string]string{}
+	country, err := defaultCountry(ctx, profile.UserId, current)
+	if err != nil {
+		return nil, err
+	}
+	phoneChanged := applyPhones(profile, current, country, fields)
+	applySocialLinks(profile, fields)
+	applyTimezone(profile, fields)
+	if len(fields) > 0 {
+		return nil, errors.NewValidation(errors.ValidationErrorFuncOptions{
+			Message: "invalid profile data",
+			Fields:  fields,
+		})
+	}
+
+	resets := map[string]interface{}{}
+	if phoneChanged {
+		resets["phone_verified_at"] = nil
+	}
+	return resets, nil
+}
+
+// defaultCountry devuelve el país de la dirección por defecto del usuario, con el que se
+// normalizan los teléfonos enviados sin código de país.
+func defaultCountry(ctx context.Context, userId uuid.UUID, current *profileModel.Profile) (string, error) {
+	if current != nil {
+		userId = current.UserId
+		if address := current.DefaultAddress(); address != nil {
+			return address.Country, nil
+		}
+	}
+	var countries []string
+	if err := db.DB.WithContext(ctx).
+		Model(&addressModel.Address{}).
+		Where("user_id = ? AND is_default", userId).
+		Limit(1).
+		Pluck("country", &countries).Error; err != nil {
+		return "", fmt.Errorf("error getting default address: %w", err)
+	}
+	if len(countries) == 0 {
+		return "", nil
+	}
+	return countries[0], nil
+}
+
+// applySocialLinks canonicaliza los links de redes sociales (guardando handle y URL) y el
+// sitio web. Los campos no enviados quedan vacíos para no pisar el valor actual.
+func applySocialLinks(profile *profileModel.Profile, fields map[string]string) {
+	networks := []struct {
+		field   string
+		network social.Network
+		url     *string
+		handle  *string
+	}{
+		{"instagram_url", social.Instagram, &profile.InstagramURL, &profile.InstagramHandle},
+		{"facebook_url", social.Facebook, &profile.FacebookURL, &profile.FacebookHandle},
+		{"twitter_url", social.Twitter, &profile.TwitterURL, &profile.TwitterHandle},
+	}
+	for _, n := range networks {
+		if isBlank(*n.url) {
+			*n.url, *n.handle = "", ""
+			continue
+		}
+		link, err := n.network.Normalize(*n.url)
+		if err != nil {
+			fields[n.field] = err.Error()
+			continue
+		}
+		*n.url, *n.handle = link.URL, link.Handle
+	}
+
+	if isBlank(profile.Website) {
+		profile.Website = ""
+		return
+	}
+	link, err := social.NormalizeWebsite(profile.Website)
+	if err != nil {
+		fields["website"] = err.Error()
+		return
+	}
+	profile.Website = link.URL
+}
+
+// applyTimezone valida la zona horaria contra la base IANA. Si no se envía queda vacía para
+// no pisar el valor actual.
+func applyTimezone(profile *profileModel.Profile, fields map[string]string) {
+	if isBlank(profile.Timezone) {
+		profile.Timezone = ""
+		return
+	}
+	location, err := time.LoadLocation(strings.TrimSpace(profile.Timezone))
+	if err != nil || location.String() == "Local" {
+		fields["timezone"] = "must be an IANA time zone such as America/Argentina/Buenos_Aires"
+		return
+	}
+	profile.Timezone = location.String()
+}
@@ -0,0 +1,289 @@
+package profileService
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	stderrors "errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/phone"
+	"github.com/aragornz325/piloto-api/pkg/sms"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// PhoneVerificationExpiration es la vigencia de un código enviado por SMS.
+	PhoneVerificationExpiration = 10 * time.Minute
+	// PhoneVerificationResendInterval es el tiempo mínimo entre dos envíos al mismo usuario.
+	PhoneVerificationResendInterval = time.Minute
+	// PhoneVerificationMaxAttempts es la cantidad de códigos incorrectos que invalida la verificación.
+	PhoneVerificationMaxAttempts = 5
+)
+
+// RequestPhoneVerification sends a 6-digit code by SMS to the phone number of the user's
+// profile. Any previous pending code is cancelled. Only the hash of the code is stored.
+//
+// Parameters:
+//   - opts: PhoneVerificationFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - *profileModel.PhoneVerification: The pending verification.
+//   - error: A 400 if the profile has no phone, 409 if it is already verified, 429 if a code was sent too recently.
+func (s *profileService) RequestPhoneVerification(opts PhoneVerificationFuncParams) (*profileModel.PhoneVerification, error) {
+	var verification profileModel.PhoneVerification
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "RequestPhoneVerification",
+		ServiceName: "profile",
+		Operation: func() error {
+			profile, err := s.GetUserProfile(GeProfileByUserIdFuncParams{
+				Ctx:    opts.Ctx,
+				UserId: opts.UserId,
+			})
+			if err != nil {
+				return err
+			}
+			if isBlank(profile.PhoneNumber) {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: "profile has no phone number",
+				})
+			}
+			if profile.PhoneVerifiedAt != nil {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "phone number is already verified",
+				})
+			}
+
+			var recent int64
+			if err := db.DB.WithContext(opts.Ctx).Model(&profileModel.PhoneVerification{}).
+				Where("user_id = ? AND created_at > ?", opts.UserId, time.Now().UTC().Add(-PhoneVerificationResendInterval)).
+				Count(&recent).Error; err != nil {
+				return fmt.Errorf("error checking recent phone verifications: %w", err)
+			}
+			if recent > 0 {
+				return errors.NewTooManyRequests(errors.SimpleErrorFuncOptions{
+					Message: "a verification code was sent recently, try again later",
+				})
+			}
+
+			code, err := generateOTP()
+			if err != nil {
+				return fmt.Errorf("error generating verification code: %w", err)
+			}
+
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Model(&profileModel.PhoneVerification{}).
+					Where("user_id = ? AND status = ?", opts.UserId, profileModel.PhoneVerificationPending).
+					Updates(map[string]interface{}{
+						"status":     profileModel.PhoneVerificationCancelled,
+						"version":    gorm.Expr("version + 1"),
+						"updated_at": time.Now().UTC(),
+					}).Error; err != nil {
+					return fmt.Errorf("error cancelling previous phone verifications: %w", err)
+				}
+
+				verification = profileModel.PhoneVerification{
+					UserId:    opts.UserId,
+					Phone:     profile.PhoneNumber,
+					Status:    profileModel.PhoneVerificationPending,
+					CodeHash:  hashOTP(opts.UserId, code),
+					ExpiresAt: time.Now().UTC().Add(PhoneVerificationExpiration),
+				}
+				verification.IsActive = true
+				if err := tx.Create(&verification).Error; err != nil {
+					return fmt.Errorf("error creating phone verification: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := s.SmsSender.Send(sms.SendSmsFuncParams{
+				Ctx:  opts.Ctx,
+				To:   verification.Phone,
+				Body: fmt.Sprintf("Your Piloto de Tormenta verification code is %s. It expires in %d minutes.", code, int(PhoneVerificationExpiration.Minutes())),
+			}); err != nil {
+				return fmt.Errorf("error sending verification code: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// ConfirmPhoneVerification checks the code sent by RequestPhoneVerification and marks the
+// profile phone as verified. Wrong codes count as failed attempts; after
+// PhoneVerificationMaxAttempts the verification can no longer be used.
+//
+// Parameters:
+//   - opts: ConfirmPhoneVerificationFuncParams containing the context, the user ID and the code.
+//
+// Returns:
+//   - *profileModel.Profile: The profile with PhoneVerifiedAt set.
+//   - error: A 404 if there is no pending verification, 400 for a wrong code, 410 if it expired, was exhausted or the phone changed.
+func (s *profileService) ConfirmPhoneVerification(opts ConfirmPhoneVerificationFuncParams) (*profileModel.Profile, error) {
+	var profile profileModel.Profile
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ConfirmPhoneVerification",
+		ServiceName: "profile",
+		Operation: func() error {
+			var wrongCode bool
+			err := db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				var verification profileModel.PhoneVerification
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("user_id = ? AND status = ?", opts.UserId, profileModel.PhoneVerificationPending).
+					Order("created_at DESC").
+					First(&verification).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "no pending phone verification",
+						})
+					}
+					return fmt.Errorf("error getting phone verification: %w", err)
+				}
+
+				now := time.Now().UTC()
+				if now.After(verification.ExpiresAt) || verification.Attempts >= PhoneVerificationMaxAttempts {
+					if err := tx.Model(&verification).Updates(map[string]interface{}{
+						"status":     profileModel.PhoneVerificationExpired,
+						"version":    verification.Version + 1,
+						"updated_at": now,
+					}).Error; err != nil {
+						return fmt.Errorf("error expiring phone verification: %w", err)
+					}
+					// Se confirma la transacción para persistir el cambio de estado
+					return nil
+				}
+
+				if subtle.ConstantTimeCompare([]byte(verification.CodeHash), []byte(hashOTP(opts.UserId, opts.Code))) != 1 {
+					wrongCode = true
+					return tx.Model(&verification).Updates(map[string]interface{}{
+						"attempts":   verification.Attempts + 1,
+						"version":    verification.Version + 1,
+						"updated_at": now,
+					}).Error
+				}
+
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Preload("Addresses", "is_default = ?", true).
+					Where("user_id = ?", opts.UserId).
+					First(&profile).Error; err != nil {
+					return fmt.Errorf("error getting profile: %w", err)
+				}
+				if profile.PhoneNumber != verification.Phone {
+					return errors.NewGone(errors.SimpleErrorFuncOptions{
+						Message: "phone number changed after the code was sent",
+					})
+				}
+
+				if err := tx.Model(&verification).Updates(map[string]interface{}{
+					"status":      profileModel.PhoneVerificationVerified,
+					"verified_at": now,
+					"version":     verification.Version + 1,
+					"updated_at":  now,
+				}).Error; err != nil {
+					return fmt.Errorf("error updating phone verification: %w", err)
+				}
+				if err := tx.Model(&profile).Updates(map[string]interface{}{
+					"phone_verified_at": now,
+					"version":           profile.Version + 1,
+					"updated_at":        now,
+				}).Error; err != nil {
+					return fmt.Errorf("error marking phone as verified: %w", err)
+				}
+				profile.PhoneVerifiedAt = &now
+				profile.Version++
+				profile.UpdatedAt = now
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if wrongCode {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: "invalid verification code",
+				})
+			}
+			if profile.PhoneVerifiedAt == nil {
+				return errors.NewGone(errors.SimpleErrorFuncOptions{
+					Message: "verification code expired, request a new one",
+				})
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// applyPhones normaliza PhoneNumber y Whatsapp a E.164 usando country, el país de la
+// dirección por defecto del usuario. Los campos no enviados quedan vacíos para no pisar el valor
+// actual y los errores de validación se agregan a fields. Devuelve true si cambió el
+// teléfono verificado y hay que borrar PhoneVerifiedAt.
+func applyPhones(profile *profileModel.Profile, current *profileModel.Profile, country string, fields map[string]string) bool {
+	normalize := func(field string, value *string) {
+		if isBlank(*value) {
+			*value = ""
+			return
+		}
+		normalized, err := phone.Normalize(*value, country)
+		if err != nil {
+			fields[field] = err.Error()
+			return
+		}
+		*value = normalized
+	}
+	normalize("phone_number", &profile.PhoneNumber)
+	normalize("whatsapp", &profile.Whatsapp)
+
+	return current != nil && current.PhoneVerifiedAt != nil &&
+		profile.PhoneNumber != "" && profile.PhoneNumber != current.PhoneNumber
+}
+
+// isBlank indica si un campo está vacío o tiene el valor por defecto de CopyNonNilFields.
+func isBlank(value string) bool {
+	value = strings.TrimSpace(value)
+	return value == "" || strings.EqualFold(value, "no information")
+}
+
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashOTP liga el código al usuario para que el mismo código no produzca el mismo hash en otra cuenta.
+func hashOTP(userId uuid.UUID, code string) string {
+	return utils.HashToken(userId.String() + ":" + code)
+}
+
+// /------ structs ------///
+type PhoneVerificationFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+}
+
+type ConfirmPhoneVerificationFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+	Code   string
+}
@@ -0,0 +1,153 @@
+package profileService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdatePrivacy changes the visibility of some profile fields. Fields not present in
+// opts.Privacy keep their current visibility. The write is conditioned on the expected
+// version like any other profile update.
+//
+// Parameters:
+//   - opts: UpdatePrivacyFuncParams containing the context, user ID, the visibility per field and the expected version.
+//
+// Returns:
+//   - *profileModel.Profile: The profile with the new privacy settings.
+//   - error: A 400 with the invalid fields, 404 if the profile does not exist or 412 on a version mismatch.
+func (s *profileService) UpdatePrivacy(opts UpdatePrivacyFuncParams) (*profileModel.Profile, error) {
+	var profile *profileModel.Profile
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "UpdatePrivacy",
+		ServiceName: "profile",
+		Operation: func() error {
+			fields := map[string]string{}
+			for field, visibility := range opts.Privacy {
+				if _, ok := profileModel.DefaultPrivacy[field]; !ok {
+					fields["privacy."+field] = "is not a configurable field"
+				} else if !profileModel.IsVisibility(visibility) {
+					fields["privacy."+field] = fmt.Sprintf("must be one of: %s", strings.Join([]string{
+						profileModel.VisibilityPublic, profileModel.VisibilityAuthenticated, profileModel.VisibilityPrivate,
+					}, ", "))
+				}
+			}
+			if len(fields) > 0 {
+				return errors.NewValidation(errors.ValidationErrorFuncOptions{
+					Message: "invalid privacy settings",
+					Fields:  fields,
+				})
+			}
+
+			var err error
+			profile, err = s.GetUserProfile(GeProfileByUserIdFuncParams{
+				Ctx:    opts.Ctx,
+				UserId: opts.UserId,
+			})
+			if err != nil {
+				return err
+			}
+			if profile.Version != opts.ExpectedVersion {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "profile was modified by another request",
+				})
+			}
+
+			privacy := make(map[string]string, len(profile.Privacy)+len(opts.Privacy))
+			for field, visibility := range profile.Privacy {
+				privacy[field] = visibility
+			}
+			for field, visibility := range opts.Privacy {
+				privacy[field] = visibility
+			}
+
+			update := profileModel.Profile{Privacy: privacy}
+			update.Version = opts.ExpectedVersion + 1
+			update.UpdatedAt = time.Now().UTC()
+			result := db.DB.WithContext(opts.Ctx).
+				Model(profile).
+				Where("version = ?", opts.ExpectedVersion).
+				Updates(&update)
+			if result.Error != nil {
+				return fmt.Errorf("error updating profile privacy: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "profile was modified by another request",
+				})
+			}
+			profile.Privacy = update.Privacy
+			profile.Version = update.Version
+			profile.UpdatedAt = update.UpdatedAt
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// GetPublicProfile returns the user owning the profile with the profile loaded, for the
+// public profile page. Accounts that cannot sign in (suspended, banned, deactivated) are
+// reported as not found. Field visibility is applied by the presenter.
+//
+// Parameters:
+//   - opts: GeProfileByUserIdFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - *userModel.User: The user with its Profile.
+//   - error: A 404 if the user or the profile do not exist or the account is not active.
+func (s *profileService) GetPublicProfile(opts GeProfileByUserIdFuncParams) (*userModel.User, error) {
+	var user userModel.User
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetPublicProfile",
+		ServiceName: "profile",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).First(&user, "id = ?", opts.UserId).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "profile not found",
+					})
+				}
+				return fmt.Errorf("error getting user: %w", err)
+			}
+			if !userModel.CanAuthenticate(user.Status) {
+				return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+					Message: "profile not found",
+				})
+			}
+
+			profile, err := s.GetUserProfile(opts)
+			if err != nil {
+				return err
+			}
+			user.Profile = profile
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// /------ structs ------///
+type UpdatePrivacyFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	Privacy         map[string]string
+	ExpectedVersion int64
+}
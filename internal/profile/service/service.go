@@ -2,12 +2,19 @@ package profileService
 
 import (
 	"context"
-	"fmt"
+	stderrors "errors"
 	"time"
 
 	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
 	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/sms"
+	"github.com/aragornz325/piloto-api/pkg/storage"
+	"github.com/aragornz325/piloto-api/pkg/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProfileService interface {
@@ -15,18 +22,38 @@ type ProfileService interface {
 	GetUserProfile(GeProfileByUserIdFuncParams) (*profileModel.Profile, error)
 	UpdateUserProfile(UpdateUserProfileFuncParams) (*profileModel.Profile, error)
 	SoftDeleteUserProfile(SoftDeleteUserProfileFuncParams) (*profileModel.Profile, error)
+	UploadAvatar(UploadAvatarFuncParams) (*profileModel.Profile, error)
+	FindNearbyProfiles(FindNearbyProfilesFuncParams) ([]NearbyProfile, error)
+	RequestPhoneVerification(PhoneVerificationFuncParams) (*profileModel.PhoneVerification, error)
+	ConfirmPhoneVerification(ConfirmPhoneVerificationFuncParams) (*profileModel.Profile, error)
+	UpdatePrivacy(UpdatePrivacyFuncParams) (*profileModel.Profile, error)
+	GetPublicProfile(GeProfileByUserIdFuncParams) (*userModel.User, error)
+	ClaimHandle(ClaimHandleFuncParams) (*profileModel.Profile, error)
+	ResolveHandle(ResolveHandleFuncParams) (*userModel.User, string, error)
+	GetCompleteness(GeProfileByUserIdFuncParams) (*profileModel.Completeness, error)
 }
 
-type profileService struct{}
+type profileService struct {
+	Storage   storage.BlobStorage
+	SmsSender sms.SmsSender
+	// CompletenessWeights son los pesos de cada ítem del puntaje de completitud.
+	CompletenessWeights map[string]int
+}
 
 // NewUserService devuelve una instancia de UserService
-func NewProfileService() ProfileService {
-	return &profileService{}
+func NewProfileService(storage storage.BlobStorage, smsSender sms.SmsSender, completenessWeights map[string]int) ProfileService {
+	return &profileService{
+		Storage:             storage,
+		SmsSender:           smsSender,
+		CompletenessWeights: completenessWeights,
+	}
 }
 
 // CreateProfile creates a new user profile in the database using the provided options.
 // It initializes a Profile model with the data from opts.Profile and saves it to the database
-// within the context specified by opts.Ctx. Returns the created Profile and any error encountered.
+// within the context specified by opts.Ctx. Phone numbers are normalized to E.164 with the
+// country of the user's default address and social links are canonicalized before saving.
+// Returns the created Profile and any error encountered.
 //
 // Parameters:
 //   - opts: CreateProfileFuncParams containing the context and profile data.
@@ -35,7 +62,10 @@ func NewProfileService() ProfileService {
 //   - *profileModel.Profile: Pointer to the newly created profile.
 //   - error: Error encountered during creation, or nil if successful.
 func (s *profileService) CreateProfile(opts CreateProfileFuncParams) (*profileModel.Profile, error) {
-	if err := db.DB.WithContext(opts.Ctx).Create(opts.Profile).Error; err != nil {
+	if _, err := s.prepareProfile(opts.Ctx, opts.Profile, nil); err != nil {
+		return nil, err
+	}
+	if err := db.DB.WithContext(opts.Ctx).Omit(clause.Associations).Create(opts.Profile).Error; err != nil {
 		return nil, err
 	}
 	opts.Profile.CreatedAt = time.Now().UTC()	
@@ -43,12 +73,21 @@ func (s *profileService) CreateProfile(opts CreateProfileFuncParams) (*profileMo
 }
 
 // GetUserProfile retrieves the user profile(s) associated with the given user ID from the database.
-// It accepts a GeProfileByUserIdFuncParams struct containing the context and user ID.
+// It accepts a GeProfileByUserIdFuncParams struct containing the context, user ID and
+// optional query options restricting the selected columns. The user's default address, which
+// is the profile's location, is loaded in Addresses.
 // Returns a pointer to a Profile model and an error if the operation fails.
 func (s *profileService) GetUserProfile(opts GeProfileByUserIdFuncParams) (*profileModel.Profile, error) {
-	fmt.Println(opts.Ctx, opts.UserId)
 	var profile profileModel.Profile
-	if err := db.DB.WithContext(opts.Ctx).Where("user_id = ?", opts.UserId).First(&profile).Error; err != nil {
+	if err := opts.Query.Apply(db.DB.WithContext(opts.Ctx), "id", "version", "user_id", "privacy").
+		Preload("Addresses", "is_default = ?", true).
+		Where("user_id = ?", opts.UserId).
+		First(&profile).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFound(errors.SimpleErrorFuncOptions{
+				Message: "profile not found",
+			})
+		}
 		return nil, err
 	}
 	return &profile, nil
@@ -57,24 +96,62 @@ func (s *profileService) GetUserProfile(opts GeProfileByUserIdFuncParams) (*prof
 // UpdateUserProfile updates the profile information of a user identified by UserId.
 // It retrieves the existing profile from the database, updates its fields with the values
 // provided in opts.Profile, and saves the changes back to the database.
+// The address is not part of the profile: it is the user's default address. Phone numbers
+// are normalized to E.164 and changing the phone number clears its verification. Social links are canonicalized.
+// Invalid fields are reported together in a validation error.
+// The write only succeeds if the stored version matches opts.ExpectedVersion; otherwise
+// a 412 Precondition Failed error is returned. On success the version is incremented.
 // Returns the updated profile on success, or an error if the operation fails.
 //
 // Parameters:
-//   - opts: UpdateUserProfileFuncParams containing the context, updated profile data, and user ID.
+//   - opts: UpdateUserProfileFuncParams containing the context, updated profile data, user ID and expected version.
 //
 // Returns:
 //   - *profileModel.Profile: Pointer to the updated profile.
 //   - error: Error if the update fails, otherwise nil.
 func (s *profileService) UpdateUserProfile(opts UpdateUserProfileFuncParams) (*profileModel.Profile, error) {
-	var profile profileModel.Profile
-	if err := db.DB.WithContext(opts.Ctx).First(&profile, opts.UserId).Error; err != nil {
+	profile, err := s.GetUserProfile(GeProfileByUserIdFuncParams{
+		Ctx:    opts.Ctx,
+		UserId: opts.UserId,
+	})
+	if err != nil {
 		return nil, err
 	}
+	if profile.Version != opts.ExpectedVersion {
+		return nil, errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+			Message: "profile was modified by another request",
+		})
+	}
 
-	if err := db.DB.WithContext(opts.Ctx).Save(&profile).Error; err != nil {
+	resets, err := s.prepareProfile(opts.Ctx, opts.Profile, profile)
+	if err != nil {
 		return nil, err
 	}
-	return &profile, nil
+
+	opts.Profile.UpdatedAt = time.Now().UTC()
+	opts.Profile.Version = opts.ExpectedVersion + 1
+	err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(profile).
+			Omit("user_id", clause.Associations).
+			Where("version = ?", opts.ExpectedVersion).
+			Updates(opts.Profile)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+				Message: "profile was modified by another request",
+			})
+		}
+		if len(resets) > 0 {
+			return tx.Model(profile).Updates(resets).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
 }
 
 // SoftDeleteUserProfile performs a soft delete operation on a user's profile identified by the provided parameters.
@@ -82,25 +159,48 @@ func (s *profileService) UpdateUserProfile(opts UpdateUserProfileFuncParams) (*p
 // without physically removing the record. Returns the deleted profile and any error encountered during the process.
 //
 // Parameters:
-//   - opts: SoftDeleteUserProfileFuncParams containing the context and user ID.
+//   - opts: SoftDeleteUserProfileFuncParams containing the context, user ID and expected version.
 //
 // Returns:
 //   - *profileModel.Profile: Pointer to the deleted profile.
 //   - error: Error encountered during retrieval or deletion, if any.
 func (s *profileService) SoftDeleteUserProfile(opts SoftDeleteUserProfileFuncParams) (*profileModel.Profile, error) {
-	profilePtr, err := s.GetUserProfile(GeProfileByUserIdFuncParams(opts))
+	profilePtr, err := s.GetUserProfile(GeProfileByUserIdFuncParams{
+		Ctx:    opts.Ctx,
+		UserId: opts.UserId,
+	})
 	if err != nil {
 		return nil, err
 	}
-	profilePtr.IsActive = false
-	profilePtr.DeletedAt.Time = time.Now().UTC()
-	if _, err := s.UpdateUserProfile(UpdateUserProfileFuncParams{
-		Ctx:     opts.Ctx,
-		Profile: profilePtr,
-		UserId:  opts.UserId,
-	}); err != nil {	
-		return nil, err
+	if profilePtr.Version != opts.ExpectedVersion {
+		return nil, errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+			Message: "profile was modified by another request",
+		})
+	}
+
+	// Se escribe solo el borrado: los datos del perfil no pasan de nuevo por la validación
+	now := time.Now().UTC()
+	result := db.DB.WithContext(opts.Ctx).
+		Model(profilePtr).
+		Where("version = ?", opts.ExpectedVersion).
+		Updates(map[string]interface{}{
+			"is_active":  false,
+			"deleted_at": now,
+			"version":    opts.ExpectedVersion + 1,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+			Message: "profile was modified by another request",
+		})
 	}
+	profilePtr.IsActive = false
+	profilePtr.DeletedAt.Scan(now)
+	profilePtr.Version = opts.ExpectedVersion + 1
+	profilePtr.UpdatedAt = now
 	return profilePtr, nil		
 }
 
@@ -113,13 +213,21 @@ type CreateProfileFuncParams struct {
 type GeProfileByUserIdFuncParams struct {
 	UserId uuid.UUID
 	Ctx    context.Context
+	Query  *utils.QueryOptions
 }
 type UpdateUserProfileFuncParams struct {
-	Profile *profileModel.Profile
-	Ctx     context.Context
-	UserId  uuid.UUID
+	Profile         *profileModel.Profile
+	Ctx             context.Context
+	UserId          uuid.UUID
+	ExpectedVersion int64
 }
-type SoftDeleteUserProfileFuncParams struct {
+type UploadAvatarFuncParams struct {
 	UserId uuid.UUID
 	Ctx    context.Context
+	Data   []byte
+}
+type SoftDeleteUserProfileFuncParams struct {
+	UserId          uuid.UUID
+	Ctx             context.Context
+	ExpectedVersion int64
 }
\ No newline at end of file
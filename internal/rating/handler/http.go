@@ -0,0 +1,269 @@
+package ratingHandler
+
+import (
+	"net/http"
+
+	m "github.com/aragornz325/piloto-api/internal/rating/model"
+	ratingService "github.com/aragornz325/piloto-api/internal/rating/service"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type RatingHandler struct {
+	RatingService ratingService.RatingService
+}
+
+func NewRatingHandler(ratingService ratingService.RatingService) *RatingHandler {
+	return &RatingHandler{
+		RatingService: ratingService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Rate trip
+// @Description Rate the other party of a completed trip from 1 to 5 with an optional comment: the rider rates the driver and the driver rates the rider. Each party rates a trip once and can edit the rating for 24 hours.
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Param input body ratingModel.CreateRatingDTO true "Rating"
+// @Success 201 {object} ratingModel.Rating
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /trips/{tripId}/ratings [post]
+func (h *RatingHandler) RateTripHandler(c *gin.Context) {
+	tripId, err := uuid.Parse(c.Param("tripId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid trip ID"})
+		return
+	}
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+
+	var payload m.CreateRatingDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.RatingService.RateTrip(ratingService.RateTripFuncParams{
+		Ctx:     c.Request.Context(),
+		TripId:  tripId,
+		RaterId: principal.UserId,
+		Rating:  payload,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Summary List trip ratings
+// @Description List the ratings of a trip. Visible to the trip parties and admins; hidden reviews are only listed for admins.
+// @Tags ratings
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {array} ratingModel.Rating
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /trips/{tripId}/ratings [get]
+func (h *RatingHandler) ListTripRatingsHandler(c *gin.Context) {
+	tripId, err := uuid.Parse(c.Param("tripId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid trip ID"})
+		return
+	}
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+
+	result, err := h.RatingService.ListTripRatings(ratingService.ListTripRatingsFuncParams{
+		Ctx:      c.Request.Context(),
+		TripId:   tripId,
+		ViewerId: principal.UserId,
+		Admin:    principal.Role == userModel.RoleAdmin,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary List user ratings
+// @Description List the ratings a user received, newest first. Hidden reviews are only listed for admins.
+// @Tags ratings
+// @Produce json
+// @Param id path string true "User ID"
+// @Param role query string false "Only ratings received in this role" Enums(driver, rider)
+// @Success 200 {array} ratingModel.Rating
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /users/{id}/ratings [get]
+func (h *RatingHandler) ListUserRatingsHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+	role := c.Query("role")
+	if role != "" && role != m.RoleDriver && role != m.RoleRider {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "role must be driver or rider", Fields: map[string]string{"role": "must be driver or rider"}})
+		return
+	}
+	admin := false
+	if principal, ok := requestctx.PrincipalFrom(c.Request.Context()); ok {
+		admin = principal.Role == userModel.RoleAdmin
+	}
+
+	result, err := h.RatingService.ListUserRatings(ratingService.ListUserRatingsFuncParams{
+		Ctx:           c.Request.Context(),
+		UserId:        userId,
+		Role:          role,
+		IncludeHidden: admin,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Update rating
+// @Description Change the score and/or comment of a rating. Only its author can edit it, during the 24 hours after rating and while it is not hidden.
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Param ratingId path string true "Rating ID"
+// @Param If-Match header string true "ETag of the rating being updated"
+// @Param input body ratingModel.UpdateRatingDTO true "Fields to update"
+// @Success 200 {object} ratingModel.Rating
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Router /ratings/{ratingId} [put]
+func (h *RatingHandler) UpdateRatingHandler(c *gin.Context) {
+	ratingId, err := uuid.Parse(c.Param("ratingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid rating ID"})
+		return
+	}
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var payload m.UpdateRatingDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.RatingService.UpdateRating(ratingService.UpdateRatingFuncParams{
+		Ctx:             c.Request.Context(),
+		RatingId:        ratingId,
+		RaterId:         principal.UserId,
+		Changes:         payload,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Hide rating
+// @Description Hide an abusive review. It stops being listed publicly and counting in the ratee's averages.
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Param ratingId path string true "Rating ID"
+// @Param input body ratingModel.HideRatingDTO true "Reason"
+// @Success 200 {object} ratingModel.Rating
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ratings/{ratingId}/hide [post]
+func (h *RatingHandler) HideRatingHandler(c *gin.Context) {
+	var payload m.HideRatingDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+	h.moderateRating(c, true, *payload.Reason)
+}
+
+// @Summary Unhide rating
+// @Description Show again a review that was hidden
+// @Tags ratings
+// @Produce json
+// @Param ratingId path string true "Rating ID"
+// @Success 200 {object} ratingModel.Rating
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /ratings/{ratingId}/unhide [post]
+func (h *RatingHandler) UnhideRatingHandler(c *gin.Context) {
+	h.moderateRating(c, false, "")
+}
+
+func (h *RatingHandler) moderateRating(c *gin.Context, hide bool, reason string) {
+	ratingId, err := uuid.Parse(c.Param("ratingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid rating ID"})
+		return
+	}
+	var moderatorId *uuid.UUID
+	if principal, ok := requestctx.PrincipalFrom(c.Request.Context()); ok {
+		moderatorId = &principal.UserId
+	}
+
+	result, err := h.RatingService.ModerateRating(ratingService.ModerateRatingFuncParams{
+		Ctx:         c.Request.Context(),
+		RatingId:    ratingId,
+		Hide:        hide,
+		Reason:      reason,
+		ModeratorId: moderatorId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
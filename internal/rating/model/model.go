@@ -0,0 +1,57 @@
+package ratingModel
+
+import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+// Rol del calificado en el viaje: el conductor califica al pasajero (rider) y viceversa.
+const (
+	RoleRider  = "rider"
+	RoleDriver = "driver"
+)
+
+// Rating es la calificación que una de las partes de un viaje completado le da a la otra.
+// Cada parte califica una sola vez por viaje y puede editarla hasta EditableUntil.
+type Rating struct {
+	baseModel.BaseModel
+	TripId  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_ratings_trip_rater" json:"trip_id"`
+	RaterId uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_ratings_trip_rater" json:"rater_id"`
+	RateeId uuid.UUID `gorm:"type:uuid;not null;index:idx_ratings_ratee" json:"ratee_id"`
+	// RateeRole es el rol del calificado en el viaje; los promedios se calculan por rol.
+	RateeRole     string    `gorm:"not null;index:idx_ratings_ratee" json:"ratee_role"`
+	Score         int       `gorm:"not null" json:"score"`
+	Comment       string    `json:"comment,omitempty"`
+	EditableUntil time.Time `gorm:"not null" json:"editable_until"`
+	// HiddenAt se completa cuando un admin oculta la reseña por abusiva: deja de mostrarse y
+	// de contar en los promedios.
+	HiddenAt     *time.Time `json:"hidden_at,omitempty"`
+	HiddenBy     *uuid.UUID `gorm:"type:uuid" json:"hidden_by,omitempty"`
+	HiddenReason string     `json:"hidden_reason,omitempty"`
+}
+
+// Hidden indica si la reseña fue ocultada por un admin.
+func (r *Rating) Hidden() bool {
+	return r.HiddenAt != nil
+}
+
+// Editable indica si el autor todavía puede editar la calificación en now.
+func (r *Rating) Editable(now time.Time) bool {
+	return now.Before(r.EditableUntil) && !r.Hidden()
+}
+
+type CreateRatingDTO struct {
+	Score   *int    `json:"score" binding:"required,min=1,max=5"`
+	Comment *string `json:"comment" binding:"omitempty,max=1000"`
+}
+
+type UpdateRatingDTO struct {
+	Score   *int    `json:"score" binding:"omitempty,min=1,max=5"`
+	Comment *string `json:"comment" binding:"omitempty,max=1000"`
+}
+
+type HideRatingDTO struct {
+	Reason *string `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,435 @@
+package ratingService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	ratingModel "github.com/aragornz325/piloto-api/internal/rating/model"
+	tripModel "github.com/aragornz325/piloto-api/internal/trip/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// EditWindow es cuánto tiempo después de calificar el autor puede editar la calificación.
+	EditWindow = 24 * time.Hour
+	// MaxListedRatings acota la cantidad de reseñas devueltas por ListUserRatings.
+	MaxListedRatings = 100
+)
+
+type RatingService interface {
+	RateTrip(RateTripFuncParams) (*ratingModel.Rating, error)
+	ListTripRatings(ListTripRatingsFuncParams) ([]*ratingModel.Rating, error)
+	ListUserRatings(ListUserRatingsFuncParams) ([]*ratingModel.Rating, error)
+	UpdateRating(UpdateRatingFuncParams) (*ratingModel.Rating, error)
+	ModerateRating(ModerateRatingFuncParams) (*ratingModel.Rating, error)
+}
+
+type ratingService struct{}
+
+func NewRatingService() RatingService {
+	return &ratingService{}
+}
+
+// RateTrip lets one party of a completed trip rate the other: the rider rates the driver and
+// the driver rates the rider. Each party can rate a trip once; the rating can be edited
+// during EditWindow. The averages shown on the ratee's profile are refreshed.
+//
+// Parameters:
+//   - opts: RateTripFuncParams containing the context, the trip ID, the rater and the rating.
+//
+// Returns:
+//   - *ratingModel.Rating: The created rating.
+//   - error: A 404 if the rater was not part of the trip, 409 if the trip is not completed or was already rated by the rater.
+func (s *ratingService) RateTrip(opts RateTripFuncParams) (*ratingModel.Rating, error) {
+	var rating ratingModel.Rating
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "RateTrip",
+		ServiceName: "rating",
+		Operation: func() error {
+			var trip tripModel.Trip
+			if err := db.DB.WithContext(opts.Ctx).First(&trip, "id = ?", opts.TripId).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errTripNotFound()
+				}
+				return fmt.Errorf("error getting trip: %w", err)
+			}
+
+			var rateeId uuid.UUID
+			var rateeRole string
+			switch {
+			case opts.RaterId == trip.RiderId && trip.DriverId != nil:
+				rateeId, rateeRole = *trip.DriverId, ratingModel.RoleDriver
+			case trip.DriverId != nil && opts.RaterId == *trip.DriverId:
+				rateeId, rateeRole = trip.RiderId, ratingModel.RoleRider
+			default:
+				return errTripNotFound()
+			}
+			if trip.Status != tripModel.StatusCompleted {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "only completed trips can be rated",
+				})
+			}
+
+			now := time.Now().UTC()
+			rating = ratingModel.Rating{
+				TripId:        trip.ID,
+				RaterId:       opts.RaterId,
+				RateeId:       rateeId,
+				RateeRole:     rateeRole,
+				Score:         *opts.Rating.Score,
+				EditableUntil: now.Add(EditWindow),
+			}
+			if opts.Rating.Comment != nil {
+				rating.Comment = strings.TrimSpace(*opts.Rating.Comment)
+			}
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Create(&rating).Error; err != nil {
+					if db.IsUniqueViolation(err) {
+						return errors.NewConflict(errors.SimpleErrorFuncOptions{
+							Message: "you already rated this trip",
+						})
+					}
+					return fmt.Errorf("error creating rating: %w", err)
+				}
+				return refreshSummary(tx, rateeId)
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// ListTripRatings returns the ratings of a trip. Visible to the trip parties and admins;
+// hidden reviews are only listed for admins.
+//
+// Parameters:
+//   - opts: ListTripRatingsFuncParams containing the context, the trip ID and the viewer.
+//
+// Returns:
+//   - []*ratingModel.Rating: The ratings, at most one per party.
+//   - error: A 404 if the trip is not visible to the viewer.
+func (s *ratingService) ListTripRatings(opts ListTripRatingsFuncParams) ([]*ratingModel.Rating, error) {
+	ratings := []*ratingModel.Rating{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListTripRatings",
+		ServiceName: "rating",
+		Operation: func() error {
+			var trip tripModel.Trip
+			if err := db.DB.WithContext(opts.Ctx).First(&trip, "id = ?", opts.TripId).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errTripNotFound()
+				}
+				return fmt.Errorf("error getting trip: %w", err)
+			}
+			isParty := opts.ViewerId == trip.RiderId || (trip.DriverId != nil && *trip.DriverId == opts.ViewerId)
+			if !isParty && !opts.Admin {
+				return errTripNotFound()
+			}
+
+			query := db.DB.WithContext(opts.Ctx).Where("trip_id = ?", trip.ID).Order("created_at ASC")
+			if !opts.Admin {
+				query = query.Where("hidden_at IS NULL")
+			}
+			if err := query.Find(&ratings).Error; err != nil {
+				return fmt.Errorf("error listing ratings: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// ListUserRatings returns the ratings received by a user, newest first, optionally only
+// those received in one role. Hidden reviews are left out unless IncludeHidden is set.
+//
+// Parameters:
+//   - opts: ListUserRatingsFuncParams containing the context, the user ID, the role and whether to include hidden reviews.
+//
+// Returns:
+//   - []*ratingModel.Rating: The ratings, at most MaxListedRatings.
+//   - error: An error if the query fails.
+func (s *ratingService) ListUserRatings(opts ListUserRatingsFuncParams) ([]*ratingModel.Rating, error) {
+	ratings := []*ratingModel.Rating{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListUserRatings",
+		ServiceName: "rating",
+		Operation: func() error {
+			query := db.DB.WithContext(opts.Ctx).
+				Where("ratee_id = ?", opts.UserId).
+				Order("created_at DESC").
+				Limit(MaxListedRatings)
+			if opts.Role != "" {
+				query = query.Where("ratee_role = ?", opts.Role)
+			}
+			if !opts.IncludeHidden {
+				query = query.Where("hidden_at IS NULL")
+			}
+			if err := query.Find(&ratings).Error; err != nil {
+				return fmt.Errorf("error listing ratings: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// UpdateRating changes the score and/or comment of a rating. Only its author can edit it,
+// before EditableUntil and while it is not hidden.
+//
+// Parameters:
+//   - opts: UpdateRatingFuncParams containing the context, the rating ID, the rater, the changes and the expected version.
+//
+// Returns:
+//   - *ratingModel.Rating: The updated rating.
+//   - error: A 404 if the rating is not the rater's, 409 if it can no longer be edited or 412 on a version mismatch.
+func (s *ratingService) UpdateRating(opts UpdateRatingFuncParams) (*ratingModel.Rating, error) {
+	var rating ratingModel.Rating
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "UpdateRating",
+		ServiceName: "rating",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("id = ? AND rater_id = ?", opts.RatingId, opts.RaterId).
+				First(&rating).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errRatingNotFound()
+				}
+				return fmt.Errorf("error getting rating: %w", err)
+			}
+			if rating.Version != opts.ExpectedVersion {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "rating was modified by another request",
+				})
+			}
+			now := time.Now().UTC()
+			if rating.Hidden() {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "hidden ratings can not be edited",
+				})
+			}
+			if !rating.Editable(now) {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "the edit window of this rating is over",
+				})
+			}
+
+			if opts.Changes.Score != nil {
+				rating.Score = *opts.Changes.Score
+			}
+			if opts.Changes.Comment != nil {
+				rating.Comment = strings.TrimSpace(*opts.Changes.Comment)
+			}
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(&rating).
+					Where("version = ?", opts.ExpectedVersion).
+					Updates(map[string]interface{}{
+						"score":      rating.Score,
+						"comment":    rating.Comment,
+						"version":    opts.ExpectedVersion + 1,
+						"updated_at": now,
+					})
+				if result.Error != nil {
+					return fmt.Errorf("error updating rating: %w", result.Error)
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "rating was modified by another request",
+					})
+				}
+				rating.Version = opts.ExpectedVersion + 1
+				rating.UpdatedAt = now
+				return refreshSummary(tx, rating.RateeId)
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// ModerateRating hides an abusive review, or shows it again. Hidden reviews are not listed
+// publicly and do not count in the averages of the ratee.
+//
+// Parameters:
+//   - opts: ModerateRatingFuncParams containing the context, the rating ID, whether to hide it, the reason and the moderator.
+//
+// Returns:
+//   - *ratingModel.Rating: The moderated rating.
+//   - error: A 400 if hiding without a reason, 404 if the rating does not exist or 409 if it is already in that state.
+func (s *ratingService) ModerateRating(opts ModerateRatingFuncParams) (*ratingModel.Rating, error) {
+	var rating ratingModel.Rating
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ModerateRating",
+		ServiceName: "rating",
+		Operation: func() error {
+			reason := strings.TrimSpace(opts.Reason)
+			if opts.Hide && reason == "" {
+				return errors.NewValidation(errors.ValidationErrorFuncOptions{
+					Message: "invalid moderation",
+					Fields:  map[string]string{"reason": "is required to hide a review"},
+				})
+			}
+			if err := db.DB.WithContext(opts.Ctx).First(&rating, "id = ?", opts.RatingId).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errRatingNotFound()
+				}
+				return fmt.Errorf("error getting rating: %w", err)
+			}
+			if rating.Hidden() == opts.Hide {
+				state := "visible"
+				if opts.Hide {
+					state = "hidden"
+				}
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "rating is already " + state,
+				})
+			}
+
+			now := time.Now().UTC()
+			var hiddenAt *time.Time
+			hiddenBy := opts.ModeratorId
+			if opts.Hide {
+				hiddenAt = &now
+			} else {
+				hiddenBy = nil
+				reason = ""
+			}
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(&rating).
+					Where("version = ?", rating.Version).
+					Updates(map[string]interface{}{
+						"hidden_at":     hiddenAt,
+						"hidden_by":     hiddenBy,
+						"hidden_reason": reason,
+						"version":       rating.Version + 1,
+						"updated_at":    now,
+					})
+				if result.Error != nil {
+					return fmt.Errorf("error moderating rating: %w", result.Error)
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "rating was modified while it was being moderated",
+					})
+				}
+				rating.HiddenAt = hiddenAt
+				rating.HiddenBy = hiddenBy
+				rating.HiddenReason = reason
+				rating.Version++
+				rating.UpdatedAt = now
+				return refreshSummary(tx, rating.RateeId)
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// refreshSummary recalcula en el perfil del usuario el promedio y la cantidad de
+// calificaciones visibles que recibió en cada rol. Primero bloquea el perfil: así dos
+// calificaciones simultáneas no se pisan y la última en recalcular ve las dos. No cambia la
+// versión del perfil porque son datos derivados, no una edición del dueño.
+func refreshSummary(tx *gorm.DB, userId uuid.UUID) error {
+	var profileIds []uuid.UUID
+	if err := tx.Model(&profileModel.Profile{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userId).
+		Pluck("id", &profileIds).Error; err != nil {
+		return fmt.Errorf("error locking profile: %w", err)
+	}
+	if len(profileIds) == 0 {
+		return nil
+	}
+
+	summary := func(role string) string {
+		return `FROM ratings WHERE ratee_id = @user AND ratee_role = '` + role + `'
+			AND hidden_at IS NULL AND deleted_at IS NULL`
+	}
+	if err := tx.Exec(`
+		UPDATE profiles SET
+			driver_rating_average = COALESCE((SELECT ROUND(AVG(score)::numeric, 2) `+summary(ratingModel.RoleDriver)+`), 0),
+			driver_rating_count = (SELECT COUNT(*) `+summary(ratingModel.RoleDriver)+`),
+			rider_rating_average = COALESCE((SELECT ROUND(AVG(score)::numeric, 2) `+summary(ratingModel.RoleRider)+`), 0),
+			rider_rating_count = (SELECT COUNT(*) `+summary(ratingModel.RoleRider)+`)
+		WHERE user_id = @user`,
+		map[string]interface{}{"user": userId}).Error; err != nil {
+		return fmt.Errorf("error refreshing rating summary: %w", err)
+	}
+	return nil
+}
+
+func errTripNotFound() error {
+	return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+		Message: "trip not found",
+	})
+}
+
+func errRatingNotFound() error {
+	return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+		Message: "rating not found",
+	})
+}
+
+// /------ structs ------///
+
+type RateTripFuncParams struct {
+	Ctx     context.Context
+	TripId  uuid.UUID
+	RaterId uuid.UUID
+	Rating  ratingModel.CreateRatingDTO
+}
+
+type ListTripRatingsFuncParams struct {
+	Ctx      context.Context
+	TripId   uuid.UUID
+	ViewerId uuid.UUID
+	Admin    bool
+}
+
+type ListUserRatingsFuncParams struct {
+	Ctx           context.Context
+	UserId        uuid.UUID
+	Role          string
+	IncludeHidden bool
+}
+
+type UpdateRatingFuncParams struct {
+	Ctx             context.Context
+	RatingId        uuid.UUID
+	RaterId         uuid.UUID
+	Changes         ratingModel.UpdateRatingDTO
+	ExpectedVersion int64
+}
+
+type ModerateRatingFuncParams struct {
+	Ctx         context.Context
+	RatingId    uuid.UUID
+	Hide        bool
+	Reason      string
+	ModeratorId *uuid.UUID
+}
@@ -0,0 +1,414 @@
+package tripHandler
+
+import (
+	"net/http"
+	"strconv"
+
+	m "github.com/aragornz325/piloto-api/internal/trip/model"
+	tripService "github.com/aragornz325/piloto-api/internal/trip/service"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type TripHandler struct {
+	TripService tripService.TripService
+}
+
+func NewTripHandler(tripService tripService.TripService) *TripHandler {
+	return &TripHandler{
+		TripService: tripService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary Request trip
+// @Description Request a trip as the authenticated rider. The trip is offered right away to the nearest available drivers; if none is found it stays requested and can be dispatched again. Expired offers are offered again to the next drivers automatically, and a trip no driver accepts within 5 minutes is cancelled.
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param input body tripModel.CreateTripDTO true "Pickup and drop off"
+// @Success 201 {object} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /trips [post]
+func (h *TripHandler) RequestTripHandler(c *gin.Context) {
+	actor, ok := actorFrom(c)
+	if !ok {
+		return
+	}
+
+	var payload m.CreateTripDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.TripService.RequestTrip(tripService.RequestTripFuncParams{
+		Ctx:   c.Request.Context(),
+		Actor: actor,
+		Trip:  payload,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Summary List trips
+// @Description List the trips of the authenticated user as rider or driver, newest first. Admins can list every trip with all=true.
+// @Tags trips
+// @Produce json
+// @Param status query string false "Filter by status" Enums(requested, offered, accepted, en_route, in_progress, completed, cancelled)
+// @Param all query bool false "List every trip (admin only)"
+// @Success 200 {array} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /trips [get]
+func (h *TripHandler) ListTripsHandler(c *gin.Context) {
+	actor, ok := actorFrom(c)
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && !m.ValidStatus(status) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid status", Fields: map[string]string{"status": "invalid status"}})
+		return
+	}
+	all := false
+	if raw := c.Query("all"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "all must be a boolean", Fields: map[string]string{"all": "must be a boolean"}})
+			return
+		}
+		all = parsed
+	}
+
+	result, err := h.TripService.ListTrips(tripService.ListTripsFuncParams{
+		Ctx:    c.Request.Context(),
+		Actor:  actor,
+		Status: status,
+		All:    all,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Get trip
+// @Description Get a trip. Visible to its rider, its driver, the drivers it was offered to and admins.
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} tripModel.Trip
+// @Header 200 {string} ETag "Current version of the trip"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /trips/{tripId} [get]
+func (h *TripHandler) GetTripHandler(c *gin.Context) {
+	opts, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.TripService.GetTrip(opts)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Dispatch trip
+// @Description Offer a requested trip again to the nearest available drivers that were not offered it yet. Only the rider or an admin can dispatch it.
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /trips/{tripId}/dispatch [post]
+func (h *TripHandler) DispatchTripHandler(c *gin.Context) {
+	opts, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.TripService.DispatchTrip(opts)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Accept trip
+// @Description Accept a trip offered to the authenticated driver, who must be an approved driver with license and insurance in force, on duty and with a selected vehicle. When several drivers accept at the same time only the first one gets the trip; the others get a 409.
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /trips/{tripId}/accept [post]
+func (h *TripHandler) AcceptTripHandler(c *gin.Context) {
+	opts, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.TripService.AcceptTrip(opts)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Decline trip
+// @Description Decline a trip offered to the authenticated driver
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} tripModel.TripOffer
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /trips/{tripId}/decline [post]
+func (h *TripHandler) DeclineTripHandler(c *gin.Context) {
+	opts, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.TripService.DeclineTrip(opts)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Head to pickup
+// @Description Mark an accepted trip as en route to the pickup. Only the assigned driver can do it.
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /trips/{tripId}/en-route [post]
+func (h *TripHandler) EnRouteTripHandler(c *gin.Context) {
+	h.advance(c, m.StatusEnRoute)
+}
+
+// @Summary Start trip
+// @Description Mark the rider as picked up. Only the assigned driver can do it.
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /trips/{tripId}/start [post]
+func (h *TripHandler) StartTripHandler(c *gin.Context) {
+	h.advance(c, m.StatusInProgress)
+}
+
+// @Summary Complete trip
+// @Description Mark a trip in progress as completed. The assigned driver or an admin can do it.
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {object} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /trips/{tripId}/complete [post]
+func (h *TripHandler) CompleteTripHandler(c *gin.Context) {
+	h.advance(c, m.StatusCompleted)
+}
+
+// @Summary Cancel trip
+// @Description Cancel a trip. The rider can cancel it until it starts, the assigned driver once accepted and until it starts, and admins at any point before it finishes.
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Param input body tripModel.CancelTripDTO false "Cancellation reason"
+// @Success 200 {object} tripModel.Trip
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /trips/{tripId}/cancel [post]
+func (h *TripHandler) CancelTripHandler(c *gin.Context) {
+	opts, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	var payload m.CancelTripDTO
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+			return
+		}
+	}
+	reason := ""
+	if payload.Reason != nil {
+		reason = *payload.Reason
+	}
+
+	result, err := h.TripService.CancelTrip(tripService.CancelTripFuncParams{
+		Ctx:    opts.Ctx,
+		TripId: opts.TripId,
+		Actor:  opts.Actor,
+		Reason: reason,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary List trip transitions
+// @Description List the status changes of a trip with who triggered each one, oldest first
+// @Tags trips
+// @Produce json
+// @Param tripId path string true "Trip ID"
+// @Success 200 {array} tripModel.TripTransition
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /trips/{tripId}/transitions [get]
+func (h *TripHandler) ListTransitionsHandler(c *gin.Context) {
+	opts, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.TripService.ListTransitions(opts)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary List my trip offers
+// @Description List the trip offers of the authenticated driver that can still be accepted, newest first
+// @Tags trips
+// @Produce json
+// @Success 200 {array} tripModel.TripOffer
+// @Failure 401 {object} ErrorResponse
+// @Router /me/trip-offers [get]
+func (h *TripHandler) ListMyOffersHandler(c *gin.Context) {
+	actor, ok := actorFrom(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.TripService.ListOffers(tripService.ListOffersFuncParams{
+		Ctx:      c.Request.Context(),
+		DriverId: actor.UserId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// advance lleva el viaje del path al estado indicado.
+func (h *TripHandler) advance(c *gin.Context, to string) {
+	opts, ok := tripParams(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.TripService.AdvanceTrip(tripService.AdvanceTripFuncParams{
+		Ctx:    opts.Ctx,
+		TripId: opts.TripId,
+		Actor:  opts.Actor,
+		To:     to,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// actorFrom arma el actor con el usuario autenticado; si no hay, responde 401.
+func actorFrom(c *gin.Context) (tripService.Actor, bool) {
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return tripService.Actor{}, false
+	}
+	return tripService.Actor{
+		UserId: principal.UserId,
+		Admin:  principal.Role == userModel.RoleAdmin,
+	}, true
+}
+
+// tripParams lee el viaje del path y el usuario autenticado; si falta alguno ya respondió el error.
+func tripParams(c *gin.Context) (tripService.GetTripFuncParams, bool) {
+	tripId, err := uuid.Parse(c.Param("tripId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid trip ID"})
+		return tripService.GetTripFuncParams{}, false
+	}
+	actor, ok := actorFrom(c)
+	if !ok {
+		return tripService.GetTripFuncParams{}, false
+	}
+	return tripService.GetTripFuncParams{
+		Ctx:    c.Request.Context(),
+		TripId: tripId,
+		Actor:  actor,
+	}, true
+}
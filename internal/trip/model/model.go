@@ -0,0 +1,178 @@
+package tripModel
+
+import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+const (
+	StatusRequested  = "requested"
+	StatusOffered    = "offered"
+	StatusAccepted   = "accepted"
+	StatusEnRoute    = "en_route"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+)
+
+// ActiveDriverStatuses son los estados en los que el viaje ocupa al conductor asignado.
+var ActiveDriverStatuses = []string{StatusAccepted, StatusEnRoute, StatusInProgress}
+
+// Actores que pueden disparar una transición.
+const (
+	ActorRider  = "rider"
+	ActorDriver = "driver"
+	ActorAdmin  = "admin"
+	// ActorSystem son las transiciones automáticas (ofrecer el viaje, volver a requested
+	// cuando nadie lo acepta, cancelarlo si nadie lo acepta en MaxDispatchWait).
+	ActorSystem = "system"
+)
+
+// tripTransitions define, para cada estado, a qué estados puede pasar un viaje y qué
+// actores pueden disparar cada transición.
+var tripTransitions = map[string]map[string][]string{
+	StatusRequested: {
+		StatusOffered:   {ActorSystem},
+		StatusCancelled: {ActorRider, ActorAdmin, ActorSystem},
+	},
+	StatusOffered: {
+		StatusRequested: {ActorSystem},
+		StatusAccepted:  {ActorDriver},
+		StatusCancelled: {ActorRider, ActorAdmin, ActorSystem},
+	},
+	StatusAccepted: {
+		StatusEnRoute:   {ActorDriver},
+		StatusCancelled: {ActorRider, ActorDriver, ActorAdmin},
+	},
+	StatusEnRoute: {
+		StatusInProgress: {ActorDriver},
+		StatusCancelled:  {ActorRider, ActorDriver, ActorAdmin},
+	},
+	StatusInProgress: {
+		StatusCompleted: {ActorDriver, ActorAdmin},
+		StatusCancelled: {ActorAdmin},
+	},
+}
+
+// CanTransition indica si el viaje puede pasar de from a to.
+func CanTransition(from, to string) bool {
+	_, ok := tripTransitions[from][to]
+	return ok
+}
+
+// CanTrigger indica si el actor puede disparar la transición de from a to.
+func CanTrigger(from, to, actor string) bool {
+	for _, allowed := range tripTransitions[from][to] {
+		if allowed == actor {
+			return true
+		}
+	}
+	return false
+}
+
+// Trip es un viaje pedido por un pasajero (Rider) y asignado al conductor que acepta la oferta.
+// Cada estado alcanzado guarda su timestamp.
+type Trip struct {
+	baseModel.BaseModel
+	RiderId        uuid.UUID  `gorm:"type:uuid;not null;index" json:"rider_id"`
+	DriverId       *uuid.UUID `gorm:"type:uuid;index" json:"driver_id,omitempty"`
+	VehicleId      *uuid.UUID `gorm:"type:uuid" json:"vehicle_id,omitempty"`
+	Status         string     `gorm:"index;not null;default:requested" json:"status"`
+	PickupLat      float64    `gorm:"not null" json:"pickup_lat"`
+	PickupLng      float64    `gorm:"not null" json:"pickup_lng"`
+	PickupAddress  string     `json:"pickup_address,omitempty"`
+	DropoffLat     float64    `gorm:"not null" json:"dropoff_lat"`
+	DropoffLng     float64    `gorm:"not null" json:"dropoff_lng"`
+	DropoffAddress string     `json:"dropoff_address,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+	RequestedAt    time.Time  `gorm:"not null" json:"requested_at"`
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	EnRouteAt      *time.Time `json:"en_route_at,omitempty"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy    *uuid.UUID `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CancelReason   string     `json:"cancel_reason,omitempty"`
+}
+
+// ValidStatus indica si status es un estado de viaje.
+func ValidStatus(status string) bool {
+	return TimestampColumn(status) != ""
+}
+
+// TimestampColumn devuelve la columna que guarda cuándo el viaje llegó al estado.
+func TimestampColumn(status string) string {
+	switch status {
+	case StatusRequested:
+		return "requested_at"
+	case StatusOffered:
+		return "offered_at"
+	case StatusAccepted:
+		return "accepted_at"
+	case StatusEnRoute:
+		return "en_route_at"
+	case StatusInProgress:
+		return "started_at"
+	case StatusCompleted:
+		return "completed_at"
+	case StatusCancelled:
+		return "cancelled_at"
+	}
+	return ""
+}
+
+const (
+	OfferPending   = "pending"
+	OfferAccepted  = "accepted"
+	OfferDeclined  = "declined"
+	OfferExpired   = "expired"
+	OfferWithdrawn = "withdrawn"
+)
+
+// TripOffer es la oferta de un viaje a un conductor cercano. El primero que acepta se queda
+// con el viaje; las demás ofertas pendientes se retiran.
+type TripOffer struct {
+	baseModel.BaseModel
+	TripId      uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_trip_offers_trip_driver" json:"trip_id"`
+	DriverId    uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_trip_offers_trip_driver" json:"driver_id"`
+	Status      string     `gorm:"index;not null;default:pending" json:"status"`
+	DistanceKm  float64    `json:"distance_km"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	Trip        *Trip      `gorm:"foreignKey:TripId" json:"trip,omitempty"`
+}
+
+// Expired indica si la oferta venció en now.
+func (o *TripOffer) Expired(now time.Time) bool {
+	return !now.Before(o.ExpiresAt)
+}
+
+// TripTransition registra cada cambio de estado de un viaje con quién lo disparó.
+type TripTransition struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	TripId     uuid.UUID  `gorm:"type:uuid;index;not null" json:"trip_id"`
+	FromStatus string     `gorm:"not null" json:"from_status"`
+	ToStatus   string     `gorm:"not null" json:"to_status"`
+	Actor      string     `gorm:"not null" json:"actor"`
+	ActorId    *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateTripDTO es el pedido de un viaje; las coordenadas son grados decimales.
+type CreateTripDTO struct {
+	PickupLat      *float64 `json:"pickup_lat" binding:"required,min=-90,max=90"`
+	PickupLng      *float64 `json:"pickup_lng" binding:"required,min=-180,max=180"`
+	PickupAddress  *string  `json:"pickup_address" binding:"omitempty,max=200"`
+	DropoffLat     *float64 `json:"dropoff_lat" binding:"required,min=-90,max=90"`
+	DropoffLng     *float64 `json:"dropoff_lng" binding:"required,min=-180,max=180"`
+	DropoffAddress *string  `json:"dropoff_address" binding:"omitempty,max=200"`
+	Notes          *string  `json:"notes" binding:"omitempty,max=500"`
+}
+
+type CancelTripDTO struct {
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
@@ -0,0 +1,990 @@
+package tripService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	driverModel "github.com/aragornz325/piloto-api/internal/driver/model"
+	locationService "github.com/aragornz325/piloto-api/internal/location/service"
+	tripModel "github.com/aragornz325/piloto-api/internal/trip/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/geo"
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// OfferTTL es cuánto tiempo tiene un conductor para aceptar una oferta.
+	OfferTTL = 30 * time.Second
+	// DispatchRadiusKm es el radio en el que se buscan conductores para ofrecer un viaje.
+	DispatchRadiusKm = 10.0
+	// MaxOffersPerDispatch es la cantidad de conductores a los que se ofrece el viaje a la vez.
+	MaxOffersPerDispatch = 5
+	// MaxListedTrips acota la cantidad de viajes devueltos por ListTrips.
+	MaxListedTrips = 100
+	// SweepInterval es cada cuánto se revisan los viajes que esperan conductor.
+	SweepInterval = 15 * time.Second
+	// MaxDispatchWait es cuánto espera un viaje sin conductor antes de cancelarse: hasta
+	// entonces el pasajero no puede pedir otro.
+	MaxDispatchWait = 5 * time.Minute
+)
+
+type TripService interface {
+	RequestTrip(RequestTripFuncParams) (*tripModel.Trip, error)
+	GetTrip(GetTripFuncParams) (*tripModel.Trip, error)
+	ListTrips(ListTripsFuncParams) ([]*tripModel.Trip, error)
+	DispatchTrip(GetTripFuncParams) (*tripModel.Trip, error)
+	ListOffers(ListOffersFuncParams) ([]*tripModel.TripOffer, error)
+	AcceptTrip(GetTripFuncParams) (*tripModel.Trip, error)
+	DeclineTrip(GetTripFuncParams) (*tripModel.TripOffer, error)
+	AdvanceTrip(AdvanceTripFuncParams) (*tripModel.Trip, error)
+	CancelTrip(CancelTripFuncParams) (*tripModel.Trip, error)
+	ListTransitions(GetTripFuncParams) ([]*tripModel.TripTransition, error)
+	SweepTrips(ctx context.Context) (*SweepResult, error)
+	Run(ctx context.Context)
+}
+
+type tripService struct {
+	Location locationService.LocationService
+}
+
+func NewTripService(location locationService.LocationService) TripService {
+	return &tripService{
+		Location: location,
+	}
+}
+
+// RequestTrip creates a trip requested by the actor and offers it to the nearest available
+// drivers. If no driver is found the trip stays requested and can be dispatched again.
+//
+// Parameters:
+//   - opts: RequestTripFuncParams containing the context, the rider and the trip data.
+//
+// Returns:
+//   - *tripModel.Trip: The created trip.
+//   - error: A 409 if the rider already has an active trip, or any other error.
+func (s *tripService) RequestTrip(opts RequestTripFuncParams) (*tripModel.Trip, error) {
+	now := time.Now().UTC()
+	trip := tripModel.Trip{
+		RiderId:     opts.Actor.UserId,
+		Status:      tripModel.StatusRequested,
+		PickupLat:   *opts.Trip.PickupLat,
+		PickupLng:   *opts.Trip.PickupLng,
+		DropoffLat:  *opts.Trip.DropoffLat,
+		DropoffLng:  *opts.Trip.DropoffLng,
+		RequestedAt: now,
+	}
+	if opts.Trip.PickupAddress != nil {
+		trip.PickupAddress = strings.TrimSpace(*opts.Trip.PickupAddress)
+	}
+	if opts.Trip.DropoffAddress != nil {
+		trip.DropoffAddress = strings.TrimSpace(*opts.Trip.DropoffAddress)
+	}
+	if opts.Trip.Notes != nil {
+		trip.Notes = strings.TrimSpace(*opts.Trip.Notes)
+	}
+
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "RequestTrip",
+		ServiceName: "trip",
+		Operation: func() error {
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Create(&trip).Error; err != nil {
+					if db.IsUniqueViolation(err) {
+						return errors.NewConflict(errors.SimpleErrorFuncOptions{
+							Message: "rider already has an active trip",
+						})
+					}
+					return fmt.Errorf("error creating trip: %w", err)
+				}
+				return recordTransition(tx, trip.ID, "", tripModel.StatusRequested, tripModel.ActorRider, &opts.Actor.UserId, "", now)
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dispatched, err := s.dispatch(opts.Ctx, trip.ID)
+	if err != nil {
+		// El viaje ya está creado: queda en requested y se puede volver a despachar
+		logger.Log.Warn("No se pudo ofrecer el viaje", zap.String("trip_id", trip.ID.String()), zap.Error(err))
+		return &trip, nil
+	}
+	return dispatched, nil
+}
+
+// GetTrip returns a trip visible to the actor: its rider, its driver, a driver it was
+// offered to or an admin.
+//
+// Parameters:
+//   - opts: GetTripFuncParams containing the context, the trip ID and the actor.
+//
+// Returns:
+//   - *tripModel.Trip: The trip.
+//   - error: A 404 if the trip does not exist or is not visible to the actor.
+func (s *tripService) GetTrip(opts GetTripFuncParams) (*tripModel.Trip, error) {
+	var trip *tripModel.Trip
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetTrip",
+		ServiceName: "trip",
+		Operation: func() error {
+			var err error
+			trip, err = loadVisibleTrip(db.DB.WithContext(opts.Ctx), opts.TripId, opts.Actor)
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+// ListTrips returns the trips of the actor as rider or driver, newest first. Admins can
+// list every trip with opts.All.
+//
+// Parameters:
+//   - opts: ListTripsFuncParams containing the context, the actor, the optional status and whether to list every trip.
+//
+// Returns:
+//   - []*tripModel.Trip: The trips, at most MaxListedTrips.
+//   - error: A 403 if a non admin asks for every trip, or any other error.
+func (s *tripService) ListTrips(opts ListTripsFuncParams) ([]*tripModel.Trip, error) {
+	trips := []*tripModel.Trip{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListTrips",
+		ServiceName: "trip",
+		Operation: func() error {
+			query := db.DB.WithContext(opts.Ctx).Order("created_at DESC").Limit(MaxListedTrips)
+			if opts.All {
+				if !opts.Actor.Admin {
+					return errors.NewForbidden(errors.ErrorFuncOptions{
+						Message: "only admins can list every trip",
+					})
+				}
+			} else {
+				query = query.Where("rider_id = ? OR driver_id = ?", opts.Actor.UserId, opts.Actor.UserId)
+			}
+			if opts.Status != "" {
+				query = query.Where("status = ?", opts.Status)
+			}
+			if err := query.Find(&trips).Error; err != nil {
+				return fmt.Errorf("error listing trips: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trips, nil
+}
+
+// DispatchTrip offers a requested trip, or one whose offers were not accepted, to the
+// nearest available drivers that were not offered it yet. Only the rider or an admin can
+// dispatch a trip.
+//
+// Parameters:
+//   - opts: GetTripFuncParams containing the context, the trip ID and the actor.
+//
+// Returns:
+//   - *tripModel.Trip: The trip, offered if some driver was found.
+//   - error: A 404 if not visible, 403 if the actor can not dispatch it or 409 if the trip was already accepted or finished.
+func (s *tripService) DispatchTrip(opts GetTripFuncParams) (*tripModel.Trip, error) {
+	var trip *tripModel.Trip
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "DispatchTrip",
+		ServiceName: "trip",
+		Operation: func() error {
+			current, err := loadVisibleTrip(db.DB.WithContext(opts.Ctx), opts.TripId, opts.Actor)
+			if err != nil {
+				return err
+			}
+			if current.RiderId != opts.Actor.UserId && !opts.Actor.Admin {
+				return errors.NewForbidden(errors.ErrorFuncOptions{
+					Message: "only the rider can dispatch the trip",
+				})
+			}
+			trip, err = s.dispatch(opts.Ctx, current.ID)
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+// ListOffers returns the pending offers of the actor as a driver, with their trip.
+//
+// Parameters:
+//   - opts: ListOffersFuncParams containing the context and the driver.
+//
+// Returns:
+//   - []*tripModel.TripOffer: The offers that can still be accepted, newest first.
+//   - error: An error if the query fails.
+func (s *tripService) ListOffers(opts ListOffersFuncParams) ([]*tripModel.TripOffer, error) {
+	offers := []*tripModel.TripOffer{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListOffers",
+		ServiceName: "trip",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Preload("Trip").
+				Where("driver_id = ? AND status = ? AND expires_at > ?", opts.DriverId, tripModel.OfferPending, time.Now().UTC()).
+				Order("created_at DESC").
+				Find(&offers).Error; err != nil {
+				return fmt.Errorf("error listing offers: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// AcceptTrip assigns an offered trip to the actor, who must hold a pending offer, be an
+// approved driver in good standing (license and insurance not expired), be on duty and have
+// a selected vehicle. The driver row is locked too, so these checks hold until the trip is
+// assigned even if the driver goes off duty or is flagged at the same time. The trip row is locked and the assignment is
+// conditioned on the trip still being unassigned, so when two drivers accept at the same
+// time only the first one gets the trip and the other gets a 409.
+//
+// Parameters:
+//   - opts: GetTripFuncParams containing the context, the trip ID and the driver.
+//
+// Returns:
+//   - *tripModel.Trip: The accepted trip.
+//   - error: A 404 if the driver has no offer, 410 if the offer expired or 409 if the trip was taken or the driver can not take it.
+func (s *tripService) AcceptTrip(opts GetTripFuncParams) (*tripModel.Trip, error) {
+	var trip tripModel.Trip
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "AcceptTrip",
+		ServiceName: "trip",
+		Operation: func() error {
+			now := time.Now().UTC()
+			expired := false
+			err := db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := lockTrip(tx, opts.TripId, &trip); err != nil {
+					return err
+				}
+				offer, err := pendingOffer(tx, trip.ID, opts.Actor.UserId)
+				if err != nil {
+					return err
+				}
+				if offer.Expired(now) {
+					// Se confirma la oferta vencida y se responde 410 fuera de la transacción
+					expired = true
+					return respondOffer(tx, offer, tripModel.OfferExpired, now)
+				}
+				if trip.Status != tripModel.StatusOffered || trip.DriverId != nil {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "trip was already taken by another driver or is no longer available",
+					})
+				}
+
+				var driver driverModel.Driver
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("user_id = ?", opts.Actor.UserId).
+					First(&driver).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewForbidden(errors.ErrorFuncOptions{
+							Message: "only drivers can accept trips",
+						})
+					}
+					return fmt.Errorf("error getting driver: %w", err)
+				}
+				if driver.Status != driverModel.StatusApproved {
+					return errors.NewForbidden(errors.ErrorFuncOptions{
+						Message: "only approved drivers can accept trips",
+					})
+				}
+				if !driver.Compliant {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "driver license or insurance expired",
+					})
+				}
+				if !driver.OnDuty {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "driver must be on duty to accept trips",
+					})
+				}
+				if driver.ActiveVehicleId == nil {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "driver must select a vehicle to accept trips",
+					})
+				}
+
+				result := tx.Model(&trip).
+					Where("status = ? AND driver_id IS NULL", tripModel.StatusOffered).
+					Updates(map[string]interface{}{
+						"status":      tripModel.StatusAccepted,
+						"driver_id":   opts.Actor.UserId,
+						"vehicle_id":  *driver.ActiveVehicleId,
+						"accepted_at": now,
+						"version":     trip.Version + 1,
+						"updated_at":  now,
+					})
+				if result.Error != nil {
+					if db.IsUniqueViolation(result.Error) {
+						return errors.NewConflict(errors.SimpleErrorFuncOptions{
+							Message: "driver already has an active trip",
+						})
+					}
+					return fmt.Errorf("error accepting trip: %w", result.Error)
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "trip was already taken by another driver",
+					})
+				}
+
+				if err := respondOffer(tx, offer, tripModel.OfferAccepted, now); err != nil {
+					return err
+				}
+				if err := withdrawOffers(tx, trip.ID, now); err != nil {
+					return err
+				}
+				if err := recordTransition(tx, trip.ID, tripModel.StatusOffered, tripModel.StatusAccepted, tripModel.ActorDriver, &opts.Actor.UserId, "", now); err != nil {
+					return err
+				}
+				return tx.First(&trip, "id = ?", trip.ID).Error
+			})
+			if err == nil && expired {
+				return errors.NewGone(errors.SimpleErrorFuncOptions{
+					Message: "trip offer expired",
+				})
+			}
+			return err
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// DeclineTrip declines the pending offer of the actor. When no offer of the trip is left
+// pending, the trip goes back to requested so it can be dispatched again.
+//
+// Parameters:
+//   - opts: GetTripFuncParams containing the context, the trip ID and the driver.
+//
+// Returns:
+//   - *tripModel.TripOffer: The declined offer.
+//   - error: A 404 if the driver has no pending offer for the trip.
+func (s *tripService) DeclineTrip(opts GetTripFuncParams) (*tripModel.TripOffer, error) {
+	var offer *tripModel.TripOffer
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "DeclineTrip",
+		ServiceName: "trip",
+		Operation: func() error {
+			now := time.Now().UTC()
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				var trip tripModel.Trip
+				if err := lockTrip(tx, opts.TripId, &trip); err != nil {
+					return err
+				}
+				var err error
+				offer, err = pendingOffer(tx, trip.ID, opts.Actor.UserId)
+				if err != nil {
+					return err
+				}
+				if err := respondOffer(tx, offer, tripModel.OfferDeclined, now); err != nil {
+					return err
+				}
+				if trip.Status != tripModel.StatusOffered {
+					return nil
+				}
+				return requeueIfUnanswered(tx, &trip, now)
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// AdvanceTrip moves a trip forward: en_route when the driver heads to the pickup,
+// in_progress when the rider is picked up and completed at the drop off. Only the assigned
+// driver can advance the trip (admins can also complete it).
+//
+// Parameters:
+//   - opts: AdvanceTripFuncParams containing the context, the trip ID, the actor and the target status.
+//
+// Returns:
+//   - *tripModel.Trip: The trip in the new status.
+//   - error: A 404 if not visible, 403 if the actor can not trigger the transition or 409 if the transition is not allowed.
+func (s *tripService) AdvanceTrip(opts AdvanceTripFuncParams) (*tripModel.Trip, error) {
+	var trip tripModel.Trip
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "AdvanceTrip",
+		ServiceName: "trip",
+		Operation: func() error {
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := lockTrip(tx, opts.TripId, &trip); err != nil {
+					return err
+				}
+				return transition(tx, &trip, opts.To, opts.Actor, "", nil)
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// CancelTrip cancels a trip. The rider can cancel it until it starts, the assigned driver
+// once accepted and until it starts, and admins at any point before it finishes. Pending
+// offers are withdrawn.
+//
+// Parameters:
+//   - opts: CancelTripFuncParams containing the context, the trip ID, the actor and the reason.
+//
+// Returns:
+//   - *tripModel.Trip: The cancelled trip.
+//   - error: A 404 if not visible, 403 if the actor can not cancel it or 409 if it already finished.
+func (s *tripService) CancelTrip(opts CancelTripFuncParams) (*tripModel.Trip, error) {
+	var trip tripModel.Trip
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "CancelTrip",
+		ServiceName: "trip",
+		Operation: func() error {
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := lockTrip(tx, opts.TripId, &trip); err != nil {
+					return err
+				}
+				reason := strings.TrimSpace(opts.Reason)
+				err := transition(tx, &trip, tripModel.StatusCancelled, opts.Actor, reason, map[string]interface{}{
+					"cancelled_by":  opts.Actor.UserId,
+					"cancel_reason": reason,
+				})
+				if err != nil {
+					return err
+				}
+				return withdrawOffers(tx, trip.ID, time.Now().UTC())
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// ListTransitions returns the status changes of a trip, oldest first.
+//
+// Parameters:
+//   - opts: GetTripFuncParams containing the context, the trip ID and the actor.
+//
+// Returns:
+//   - []*tripModel.TripTransition: The recorded transitions.
+//   - error: A 404 if the trip is not visible to the actor.
+func (s *tripService) ListTransitions(opts GetTripFuncParams) ([]*tripModel.TripTransition, error) {
+	transitions := []*tripModel.TripTransition{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListTransitions",
+		ServiceName: "trip",
+		Operation: func() error {
+			tx := db.DB.WithContext(opts.Ctx)
+			if _, err := loadVisibleTrip(tx, opts.TripId, opts.Actor); err != nil {
+				return err
+			}
+			if err := tx.Where("trip_id = ?", opts.TripId).
+				Order("created_at ASC").
+				Find(&transitions).Error; err != nil {
+				return fmt.Errorf("error listing trip transitions: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// SweepTrips handles the trips still waiting for a driver: those waiting longer than
+// MaxDispatchWait are cancelled, so the rider can request another one, and the rest whose
+// offers all expired are offered again to the next nearest drivers (or go back to
+// requested if none is found). Running it from several instances is safe: each trip is
+// handled with its row locked.
+//
+// Parameters:
+//   - ctx: Context of the operation.
+//
+// Returns:
+//   - *SweepResult: How many trips were cancelled and dispatched again.
+//   - error: An error if listing the trips fails.
+func (s *tripService) SweepTrips(ctx context.Context) (*SweepResult, error) {
+	result := SweepResult{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         ctx,
+		Name:        "SweepTrips",
+		ServiceName: "trip",
+		Operation: func() error {
+			now := time.Now().UTC()
+			var stale []uuid.UUID
+			if err := db.DB.WithContext(ctx).Model(&tripModel.Trip{}).
+				Where("status IN ? AND requested_at <= ?",
+					[]string{tripModel.StatusRequested, tripModel.StatusOffered}, now.Add(-MaxDispatchWait)).
+				Pluck("id", &stale).Error; err != nil {
+				return fmt.Errorf("error listing stale trips: %w", err)
+			}
+			for _, tripId := range stale {
+				cancelled, err := cancelUnassigned(ctx, tripId)
+				if err != nil {
+					logger.Log.Warn("No se pudo cancelar el viaje sin conductor", zap.String("trip_id", tripId.String()), zap.Error(err))
+					continue
+				}
+				if cancelled {
+					result.Cancelled++
+				}
+			}
+
+			var waiting []uuid.UUID
+			if err := db.DB.WithContext(ctx).Model(&tripModel.Trip{}).
+				Where("status IN ? AND requested_at > ?",
+					[]string{tripModel.StatusRequested, tripModel.StatusOffered}, now.Add(-MaxDispatchWait)).
+				Where("NOT EXISTS (SELECT 1 FROM trip_offers o WHERE o.trip_id = trips.id AND o.status = ? AND o.expires_at > ?)",
+					tripModel.OfferPending, now).
+				Pluck("id", &waiting).Error; err != nil {
+				return fmt.Errorf("error listing trips waiting for a driver: %w", err)
+			}
+			for _, tripId := range waiting {
+				trip, err := s.dispatch(ctx, tripId)
+				if err != nil {
+					if errors.StatusCode(err, 0) != http.StatusConflict {
+						logger.Log.Warn("No se pudo volver a ofrecer el viaje", zap.String("trip_id", tripId.String()), zap.Error(err))
+					}
+					continue
+				}
+				if trip.Status == tripModel.StatusOffered {
+					result.Dispatched++
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Run executes SweepTrips right away and then every SweepInterval until ctx is cancelled.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the loop.
+func (s *tripService) Run(ctx context.Context) {
+	ticker := time.NewTicker(SweepInterval)
+	defer ticker.Stop()
+	for {
+		if result, err := s.SweepTrips(ctx); err != nil {
+			logger.Log.Error("💥 Error al revisar los viajes sin conductor", zap.Error(err))
+		} else if result.Cancelled > 0 || result.Dispatched > 0 {
+			logger.Log.Info("🚕 Viajes sin conductor revisados",
+				zap.Int("cancelled", result.Cancelled),
+				zap.Int("dispatched", result.Dispatched),
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelUnassigned cancela como sistema un viaje que sigue sin conductor y retira sus
+// ofertas. Devuelve false si mientras tanto el viaje fue aceptado o cancelado.
+func cancelUnassigned(ctx context.Context, tripId uuid.UUID) (bool, error) {
+	cancelled := false
+	err := db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var trip tripModel.Trip
+		if err := lockTrip(tx, tripId, &trip); err != nil {
+			return err
+		}
+		if trip.Status != tripModel.StatusRequested && trip.Status != tripModel.StatusOffered {
+			return nil
+		}
+		reason := "no driver accepted the trip"
+		err := transition(tx, &trip, tripModel.StatusCancelled, Actor{}, reason, map[string]interface{}{
+			"cancel_reason": reason,
+		})
+		if err != nil {
+			return err
+		}
+		cancelled = true
+		return withdrawOffers(tx, trip.ID, time.Now().UTC())
+	})
+	return cancelled, err
+}
+
+// dispatch ofrece el viaje a los conductores disponibles más cercanos al punto de partida
+// que todavía no lo recibieron. Sin candidatos, un viaje ofrecido sin ofertas vigentes
+// vuelve a requested.
+func (s *tripService) dispatch(ctx context.Context, tripId uuid.UUID) (*tripModel.Trip, error) {
+	var trip tripModel.Trip
+	if err := db.DB.WithContext(ctx).First(&trip, "id = ?", tripId).Error; err != nil {
+		return nil, fmt.Errorf("error getting trip: %w", err)
+	}
+	nearest, err := s.Location.FindNearestDrivers(locationService.FindNearestDriversFuncParams{
+		Ctx:      ctx,
+		Center:   geo.Coordinates{Latitude: trip.PickupLat, Longitude: trip.PickupLng},
+		RadiusKm: DispatchRadiusKm,
+		Limit:    locationService.MaxNearestLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTrip(tx, tripId, &trip); err != nil {
+			return err
+		}
+		if trip.Status != tripModel.StatusRequested && trip.Status != tripModel.StatusOffered {
+			return errors.NewConflict(errors.SimpleErrorFuncOptions{
+				Message: fmt.Sprintf("trip is %s and can not be dispatched", trip.Status),
+			})
+		}
+		if err := tx.Model(&tripModel.TripOffer{}).
+			Where("trip_id = ? AND status = ? AND expires_at <= ?", trip.ID, tripModel.OfferPending, now).
+			Updates(map[string]interface{}{
+				"status":       tripModel.OfferExpired,
+				"responded_at": now,
+				"version":      gorm.Expr("version + 1"),
+				"updated_at":   now,
+			}).Error; err != nil {
+			return fmt.Errorf("error expiring offers: %w", err)
+		}
+
+		// Se descartan los conductores que ya recibieron este viaje o están en otro
+		var offered []uuid.UUID
+		if err := tx.Model(&tripModel.TripOffer{}).Where("trip_id = ?", trip.ID).Pluck("driver_id", &offered).Error; err != nil {
+			return fmt.Errorf("error listing offers: %w", err)
+		}
+		candidates := make([]uuid.UUID, len(nearest))
+		for i, candidate := range nearest {
+			candidates[i] = candidate.UserId
+		}
+		var busy []uuid.UUID
+		if err := tx.Model(&tripModel.Trip{}).
+			Where("driver_id IN ? AND status IN ?", candidates, tripModel.ActiveDriverStatuses).
+			Pluck("driver_id", &busy).Error; err != nil {
+			return fmt.Errorf("error listing busy drivers: %w", err)
+		}
+		skip := map[uuid.UUID]bool{trip.RiderId: true}
+		for _, id := range append(offered, busy...) {
+			skip[id] = true
+		}
+
+		offers := []*tripModel.TripOffer{}
+		for _, candidate := range nearest {
+			if skip[candidate.UserId] || candidate.ActiveVehicleId == nil {
+				continue
+			}
+			offers = append(offers, &tripModel.TripOffer{
+				TripId:     trip.ID,
+				DriverId:   candidate.UserId,
+				Status:     tripModel.OfferPending,
+				DistanceKm: candidate.DistanceKm,
+				ExpiresAt:  now.Add(OfferTTL),
+			})
+			if len(offers) == MaxOffersPerDispatch {
+				break
+			}
+		}
+		if len(offers) == 0 {
+			if trip.Status == tripModel.StatusOffered {
+				return requeueIfUnanswered(tx, &trip, now)
+			}
+			return nil
+		}
+		if err := tx.Create(&offers).Error; err != nil {
+			return fmt.Errorf("error creating offers: %w", err)
+		}
+		if trip.Status == tripModel.StatusRequested {
+			return transition(tx, &trip, tripModel.StatusOffered, Actor{}, "", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// transition valida y aplica un cambio de estado: que la máquina de estados lo permita y
+// que el actor tenga un rol habilitado para dispararlo. Un Actor vacío es el sistema.
+// Guarda el timestamp del nuevo estado, los cambios extra y el registro de la transición,
+// y recarga el viaje.
+func transition(tx *gorm.DB, trip *tripModel.Trip, to string, actor Actor, reason string, extra map[string]interface{}) error {
+	role, err := roleFor(trip, actor, to)
+	if err != nil {
+		return err
+	}
+
+	from := trip.Status
+	now := time.Now().UTC()
+	changes := map[string]interface{}{
+		"status":                      to,
+		tripModel.TimestampColumn(to): now,
+		"version":                     trip.Version + 1,
+		"updated_at":                  now,
+	}
+	for column, value := range extra {
+		changes[column] = value
+	}
+	result := tx.Model(trip).Where("status = ?", from).Updates(changes)
+	if result.Error != nil {
+		return fmt.Errorf("error changing trip status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewConflict(errors.SimpleErrorFuncOptions{
+			Message: "trip was modified by another request",
+		})
+	}
+
+	var actorId *uuid.UUID
+	if role != tripModel.ActorSystem {
+		actorId = &actor.UserId
+	}
+	if err := recordTransition(tx, trip.ID, from, to, role, actorId, reason, now); err != nil {
+		return err
+	}
+	return tx.First(trip, "id = ?", trip.ID).Error
+}
+
+// roleFor devuelve con qué rol el actor puede llevar el viaje al estado to. Responde 404 si
+// el actor no tiene relación con el viaje, 409 si la transición no existe y 403 si ninguno
+// de sus roles la puede disparar.
+func roleFor(trip *tripModel.Trip, actor Actor, to string) (string, error) {
+	if !tripModel.CanTransition(trip.Status, to) {
+		return "", errors.NewConflict(errors.SimpleErrorFuncOptions{
+			Message: fmt.Sprintf("cannot change trip status from %s to %s", trip.Status, to),
+		})
+	}
+	var roles []string
+	if actor.UserId == uuid.Nil {
+		roles = []string{tripModel.ActorSystem}
+	} else {
+		if actor.UserId == trip.RiderId {
+			roles = append(roles, tripModel.ActorRider)
+		}
+		if trip.DriverId != nil && actor.UserId == *trip.DriverId {
+			roles = append(roles, tripModel.ActorDriver)
+		}
+		if actor.Admin {
+			roles = append(roles, tripModel.ActorAdmin)
+		}
+		if len(roles) == 0 {
+			return "", errTripNotFound()
+		}
+	}
+	for _, role := range roles {
+		if tripModel.CanTrigger(trip.Status, to, role) {
+			return role, nil
+		}
+	}
+	return "", errors.NewForbidden(errors.ErrorFuncOptions{
+		Message: fmt.Sprintf("you can not change this trip from %s to %s", trip.Status, to),
+	})
+}
+
+// requeueIfUnanswered devuelve un viaje ofrecido a requested si no le quedan ofertas vigentes.
+func requeueIfUnanswered(tx *gorm.DB, trip *tripModel.Trip, now time.Time) error {
+	var pending int64
+	if err := tx.Model(&tripModel.TripOffer{}).
+		Where("trip_id = ? AND status = ? AND expires_at > ?", trip.ID, tripModel.OfferPending, now).
+		Count(&pending).Error; err != nil {
+		return fmt.Errorf("error counting offers: %w", err)
+	}
+	if pending > 0 {
+		return nil
+	}
+	return transition(tx, trip, tripModel.StatusRequested, Actor{}, "no driver accepted the trip", nil)
+}
+
+// loadVisibleTrip busca un viaje que el actor puede ver; si no puede, responde 404 para no
+// revelar que existe.
+func loadVisibleTrip(tx *gorm.DB, tripId uuid.UUID, actor Actor) (*tripModel.Trip, error) {
+	var trip tripModel.Trip
+	if err := tx.First(&trip, "id = ?", tripId).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errTripNotFound()
+		}
+		return nil, fmt.Errorf("error getting trip: %w", err)
+	}
+	if actor.Admin || actor.UserId == trip.RiderId || (trip.DriverId != nil && *trip.DriverId == actor.UserId) {
+		return &trip, nil
+	}
+	var offers int64
+	if err := tx.Model(&tripModel.TripOffer{}).
+		Where("trip_id = ? AND driver_id = ?", trip.ID, actor.UserId).
+		Count(&offers).Error; err != nil {
+		return nil, fmt.Errorf("error getting offers: %w", err)
+	}
+	if offers == 0 {
+		return nil, errTripNotFound()
+	}
+	return &trip, nil
+}
+
+// lockTrip lee el viaje con un lock de fila: serializa las transiciones del mismo viaje.
+func lockTrip(tx *gorm.DB, tripId uuid.UUID, trip *tripModel.Trip) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(trip, "id = ?", tripId).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errTripNotFound()
+		}
+		return fmt.Errorf("error getting trip: %w", err)
+	}
+	return nil
+}
+
+// pendingOffer busca la oferta pendiente del conductor para el viaje, con lock de fila.
+func pendingOffer(tx *gorm.DB, tripId uuid.UUID, driverId uuid.UUID) (*tripModel.TripOffer, error) {
+	var offer tripModel.TripOffer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("trip_id = ? AND driver_id = ? AND status = ?", tripId, driverId, tripModel.OfferPending).
+		First(&offer).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFound(errors.SimpleErrorFuncOptions{
+				Message: "no pending offer for this trip",
+			})
+		}
+		return nil, fmt.Errorf("error getting offer: %w", err)
+	}
+	return &offer, nil
+}
+
+// respondOffer cierra una oferta con el estado indicado.
+func respondOffer(tx *gorm.DB, offer *tripModel.TripOffer, status string, now time.Time) error {
+	if err := tx.Model(offer).Updates(map[string]interface{}{
+		"status":       status,
+		"responded_at": now,
+		"version":      offer.Version + 1,
+		"updated_at":   now,
+	}).Error; err != nil {
+		return fmt.Errorf("error updating offer: %w", err)
+	}
+	offer.Status = status
+	offer.RespondedAt = &now
+	offer.Version++
+	offer.UpdatedAt = now
+	return nil
+}
+
+// withdrawOffers retira las ofertas pendientes de un viaje que ya no se puede aceptar.
+func withdrawOffers(tx *gorm.DB, tripId uuid.UUID, now time.Time) error {
+	if err := tx.Model(&tripModel.TripOffer{}).
+		Where("trip_id = ? AND status = ?", tripId, tripModel.OfferPending).
+		Updates(map[string]interface{}{
+			"status":       tripModel.OfferWithdrawn,
+			"responded_at": now,
+			"version":      gorm.Expr("version + 1"),
+			"updated_at":   now,
+		}).Error; err != nil {
+		return fmt.Errorf("error withdrawing offers: %w", err)
+	}
+	return nil
+}
+
+// recordTransition guarda en el historial del viaje el cambio de from a to.
+func recordTransition(tx *gorm.DB, tripId uuid.UUID, from, to string, actor string, actorId *uuid.UUID, reason string, now time.Time) error {
+	if err := tx.Create(&tripModel.TripTransition{
+		ID:         uuid.New(),
+		TripId:     tripId,
+		FromStatus: from,
+		ToStatus:   to,
+		Actor:      actor,
+		ActorId:    actorId,
+		Reason:     reason,
+		CreatedAt:  now,
+	}).Error; err != nil {
+		return fmt.Errorf("error recording trip transition: %w", err)
+	}
+	return nil
+}
+
+func errTripNotFound() error {
+	return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+		Message: "trip not found",
+	})
+}
+
+// /------ structs ------///
+
+// SweepResult resume una corrida de SweepTrips.
+type SweepResult struct {
+	Cancelled  int `json:"cancelled"`
+	Dispatched int `json:"dispatched"`
+}
+
+// Actor es el usuario que opera sobre un viaje. Admin indica si tiene el rol de admin.
+type Actor struct {
+	UserId uuid.UUID
+	Admin  bool
+}
+
+type RequestTripFuncParams struct {
+	Ctx   context.Context
+	Actor Actor
+	Trip  tripModel.CreateTripDTO
+}
+
+type GetTripFuncParams struct {
+	Ctx    context.Context
+	TripId uuid.UUID
+	Actor  Actor
+}
+
+type ListTripsFuncParams struct {
+	Ctx    context.Context
+	Actor  Actor
+	Status string
+	All    bool
+}
+
+type ListOffersFuncParams struct {
+	Ctx      context.Context
+	DriverId uuid.UUID
+}
+
+type AdvanceTripFuncParams struct {
+	Ctx    context.Context
+	TripId uuid.UUID
+	Actor  Actor
+	To     string
+}
+
+type CancelTripFuncParams struct {
+	Ctx    context.Context
+	TripId uuid.UUID
+	Actor  Actor
+	Reason string
+}
@@ -0,0 +1,104 @@
+package userHandler
+
+import (
+	"net/http"
+
+	m "github.com/aragornz325/piloto-api/internal/user/model"
+	userPresenter "github.com/aragornz325/piloto-api/internal/user/presenter"
+	userService "github.com/aragornz325/piloto-api/internal/user/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Request an email change
+// @Description Store a pending email change and send a confirmation link to the new address and a notice to the current one. The email is not changed until confirmed.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body userModel.RequestEmailChangeDTO true "New email"
+// @Success 202 {object} userModel.EmailChangeRequest
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/email-change [post]
+func (h *UserHandler) RequestEmailChangeHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.RequestEmailChangeDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	request, err := h.EmailChangeService.RequestEmailChange(userService.RequestEmailChangeFuncParams{
+		Ctx:      c.Request.Context(),
+		UserId:   userId,
+		NewEmail: *payload.NewEmail,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, request)
+}
+
+// @Summary Confirm an email change
+// @Description Apply a pending email change using the token of the link sent to the new address. Clients that read the token from the link post it here; following the link itself calls GET /users/email-change/confirm.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param input body userModel.ConfirmEmailChangeDTO true "Confirmation token"
+// @Success 200 {object} userPresenter.UserSelfResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /users/email-change/confirm [post]
+func (h *UserHandler) ConfirmEmailChangeHandler(c *gin.Context) {
+	var payload m.ConfirmEmailChangeDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.confirmEmailChange(c, *payload.Token)
+}
+
+// @Summary Confirm an email change from the emailed link
+// @Description Apply a pending email change when the link sent to the new address is opened. Only whoever received the email has the token, so opening the link is the proof that the new address belongs to the user.
+// @Tags users
+// @Produce json
+// @Param token query string true "Confirmation token"
+// @Success 200 {object} userPresenter.UserSelfResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /users/email-change/confirm [get]
+func (h *UserHandler) ConfirmEmailChangeLinkHandler(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "token is required"})
+		return
+	}
+	h.confirmEmailChange(c, token)
+}
+
+func (h *UserHandler) confirmEmailChange(c *gin.Context, token string) {
+	user, err := h.EmailChangeService.ConfirmEmailChange(userService.ConfirmEmailChangeFuncParams{
+		Ctx:   c.Request.Context(),
+		Token: token,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, userPresenter.PresentForOwner(c.Request.Context(), user))
+}
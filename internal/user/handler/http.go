@@ -1,15 +1,20 @@
 package userHandler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	m "github.com/aragornz325/piloto-api/internal/user/model"
+	userPresenter "github.com/aragornz325/piloto-api/internal/user/presenter"
 	userService "github.com/aragornz325/piloto-api/internal/user/service"
 	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
 	"github.com/aragornz325/piloto-api/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type ErrorResponse struct {
@@ -17,26 +22,28 @@ type ErrorResponse struct {
 }
 
 type UserHandler struct {
-	UserService userService.UserService
+	UserService        userService.UserService
+	EmailChangeService userService.EmailChangeService
 }
 
 var validate = validator.New()
 
-func NewUserHandler(userService userService.UserService) *UserHandler {
+func NewUserHandler(userService userService.UserService, emailChangeService userService.EmailChangeService) *UserHandler {
 	return &UserHandler{
-		UserService: userService,
+		UserService:        userService,
+		EmailChangeService: emailChangeService,
 	}
 }
 
 //----------------------------------------------------
 
 // @Summary Create a new user
-// @Description Create and register a new user on the system
+// @Description Create a new user on the system. Admin only: users sign up through /auth/register or an invitation.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param input body userModel.CreateUserInput true "Data to create a new user"
-// @Success 201 {object} userModel.User
+// @Success 201 {object} userPresenter.UserSelfResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /users [post]
 func (h *UserHandler) CreateUserHandler(c *gin.Context) {
@@ -71,6 +78,13 @@ func (h *UserHandler) CreateUserHandler(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
+	// Igual que en /auth/register, la contraseña se guarda hasheada
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "error hashing password"})
+		return
+	}
+	user.Password = string(hashedPassword)
 
 	// create user
 	createdUser, err := h.UserService.
@@ -85,32 +99,75 @@ func (h *UserHandler) CreateUserHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdUser)
+	c.JSON(http.StatusCreated, userPresenter.PresentForOwner(c.Request.Context(), createdUser))
 }
 
 // @Summary Get all users
-// @Description Get all users in the system
+// @Description Get all users in the system. Admins can filter by the completeness score (0 to 100) of the user's profile.
 // @Tags users
+// @Param fields query string false "Comma separated list of fields to return (e.g. first_name,email)"
+// @Param include query string false "Comma separated list of relations to expand (profile)"
+// @Param min_completeness query int false "Minimum profile completeness score (admin only)"
+// @Param max_completeness query int false "Maximum profile completeness score (admin only)"
 // @Produce json
-// @Success 200 {array} userModel.User
+// @Success 200 {array} userPresenter.UserPublicResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Router /users [get]
 func (h *UserHandler) GetAllUsersHandler(c *gin.Context) {
-	users, err := h.UserService.GetAllUsers(c.Request.Context())
+	query, err := utils.ParseQueryOptions(c, utils.ParseQueryOptionsFuncParams{
+		AllowedFields:   m.SelectableFields,
+		AllowedIncludes: m.Includes,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	minCompleteness, err := parseCompletenessParam(c, "min_completeness")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	maxCompleteness, err := parseCompletenessParam(c, "max_completeness")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if (minCompleteness != nil || maxCompleteness != nil) && !isAdmin(c) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "only admins can filter by completeness"})
+		return
+	}
+
+	users, err := h.UserService.GetAllUsers(userService.GetAllUsersFuncParams{
+		Ctx:             c.Request.Context(),
+		Query:           query,
+		MinCompleteness: minCompleteness,
+		MaxCompleteness: maxCompleteness,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	response, err := query.Project(userPresenter.PresentListForViewer(c.Request.Context(), users))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Get user by ID
 // @Description Get a user by their ID
 // @Tags users
 // @Param id path string true "User ID"
+// @Param fields query string false "Comma separated list of fields to return (e.g. first_name,email)"
+// @Param include query string false "Comma separated list of relations to expand (profile)"
 // @Produce json
-// @Success 200 {object} userModel.User
+// @Success 200 {object} userPresenter.UserSelfResponse
+// @Header 200 {string} ETag "Current version of the user"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /users/{id} [get]
@@ -121,29 +178,48 @@ func (h *UserHandler) GetUserByIdHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
 		return
 	}
+	query, err := utils.ParseQueryOptions(c, utils.ParseQueryOptionsFuncParams{
+		AllowedFields:   m.SelectableFields,
+		AllowedIncludes: m.Includes,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
 
 	user, err := h.UserService.GetUserById(userService.GetUserByIdFuncParams{
 		Ctx:    c.Request.Context(),
 		UserId: userId,
+		Query:  query,
 	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := query.Project(userPresenter.PresentForViewer(c.Request.Context(), user))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	utils.SetETag(c, user.Version)
+	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Update user
-// @Description Update an existing user
+// @Description Update an existing user. Only the user or an admin can do it, and only an admin can change the role and status.
 // @Tags users
 // @Accept json
 // @Param id path string true "User ID"
-// @Param input body userModel.CreateUserInput true "Data to update the user"
+// @Param If-Match header string true "ETag of the user being updated"
+// @Param input body userModel.UpdateUserInput true "Data to update the user"
 // @Produce json
-// @Success 200 {object} userModel.User
+// @Success 200 {object} userPresenter.UserSelfResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUserHandler(c *gin.Context) {
 	idParan := c.Param("id")
@@ -152,7 +228,12 @@ func (h *UserHandler) UpdateUserHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
 		return
 	}
-	var payload m.CreateUserInput
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+	var payload m.UpdateUserInput
 	var user m.User
 
 	// Bind JSON
@@ -176,27 +257,33 @@ func (h *UserHandler) UpdateUserHandler(c *gin.Context) {
 	}
 
 	result, err := h.UserService.UpdateUser(userService.UpdateUserFuncParams{
-		Ctx:    c.Request.Context(),
-		UserId: userId,
-		User:   &user,
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		User:            &user,
+		ExpectedVersion: expectedVersion,
+		AsAdmin:         isAdmin(c),
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, userPresenter.PresentForViewer(c.Request.Context(), result))
 }
 
 // @Summary Soft delete user
-// @Description Soft delete a user by their ID
+// @Description Soft delete a user by their ID. Only the user or an admin can do it.
 // @Tags users
 // @Accept json
 // @Param id path string true "User ID"
+// @Param If-Match header string true "ETag of the user being deleted"
 // @Produce json
-// @Success 200 {object} userModel.User
+// @Success 200 {object} userPresenter.UserSelfResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Router /users/{id} [delete]
 func (h *UserHandler) SoftDeleteUserHandler(c *gin.Context) {
 	idParan := c.Param("id")
@@ -205,14 +292,39 @@ func (h *UserHandler) SoftDeleteUserHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
 		return
 	}
-	user, err := h.UserService.SoftDeleteUser(userService.GetUserByIdFuncParams{
-		Ctx:    c.Request.Context(),
-		UserId: userId,
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+	user, err := h.UserService.SoftDeleteUser(userService.SoftDeleteUserFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		ExpectedVersion: expectedVersion,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, userPresenter.PresentForViewer(c.Request.Context(), user))
+}
+
+// parseCompletenessParam lee un puntaje de completitud opcional (0 a 100) de la query.
+func parseCompletenessParam(c *gin.Context, name string) (*int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 || value > 100 {
+		return nil, fmt.Errorf("%s must be an integer between 0 and 100", name)
+	}
+	return &value, nil
+}
+
+// isAdmin indica si el usuario autenticado de la request es admin.
+func isAdmin(c *gin.Context) bool {
+	principal, ok := requestctx.PrincipalFrom(c.Request.Context())
+	return ok && principal.Role == m.RoleAdmin
 }
@@ -0,0 +1,155 @@
+package userHandler
+
+import (
+	"net/http"
+
+	m "github.com/aragornz325/piloto-api/internal/user/model"
+	userPresenter "github.com/aragornz325/piloto-api/internal/user/presenter"
+	userService "github.com/aragornz325/piloto-api/internal/user/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Suspend user
+// @Description Suspend a user account. Suspended users cannot log in nor use their tokens.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body userModel.ChangeUserStatusDTO true "Reason"
+// @Success 200 {object} userPresenter.UserAdminResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/suspend [post]
+func (h *UserHandler) SuspendUserHandler(c *gin.Context) {
+	h.changeUserStatus(c, m.StatusSuspended)
+}
+
+// @Summary Reinstate user
+// @Description Reinstate a suspended, banned or deactivated user account
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body userModel.ChangeUserStatusDTO true "Reason"
+// @Success 200 {object} userPresenter.UserAdminResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/reinstate [post]
+func (h *UserHandler) ReinstateUserHandler(c *gin.Context) {
+	h.changeUserStatus(c, m.StatusActive)
+}
+
+// @Summary Ban user
+// @Description Ban a user account
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body userModel.ChangeUserStatusDTO true "Reason"
+// @Success 200 {object} userPresenter.UserAdminResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/ban [post]
+func (h *UserHandler) BanUserHandler(c *gin.Context) {
+	h.changeUserStatus(c, m.StatusBanned)
+}
+
+// @Summary Deactivate own account
+// @Description Deactivate the account of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body userModel.DeactivateUserDTO false "Optional reason"
+// @Success 200 {object} userPresenter.UserAdminResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUserHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.DeactivateUserDTO
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	reason := "deactivated by user"
+	if payload.Reason != nil && *payload.Reason != "" {
+		reason = *payload.Reason
+	}
+
+	h.applyUserStatus(c, userId, m.StatusDeactivated, reason)
+}
+
+// @Summary List user status transitions
+// @Description List the account status changes of a user, newest first
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} userModel.UserStatusTransition
+// @Failure 400 {object} ErrorResponse
+// @Router /users/{id}/status-transitions [get]
+func (h *UserHandler) ListUserStatusTransitionsHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	transitions, err := h.UserService.ListUserStatusTransitions(userService.GetUserByIdFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transitions)
+}
+
+func (h *UserHandler) changeUserStatus(c *gin.Context, to string) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.ChangeUserStatusDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.applyUserStatus(c, userId, to, *payload.Reason)
+}
+
+func (h *UserHandler) applyUserStatus(c *gin.Context, userId uuid.UUID, to string, reason string) {
+	var actorId *uuid.UUID
+	if principal, ok := requestctx.PrincipalFrom(c.Request.Context()); ok {
+		actorId = &principal.UserId
+	}
+
+	user, err := h.UserService.ChangeUserStatus(userService.ChangeUserStatusFuncParams{
+		Ctx:     c.Request.Context(),
+		UserId:  userId,
+		To:      to,
+		Reason:  reason,
+		ActorId: actorId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, userPresenter.PresentForViewer(c.Request.Context(), user))
+}
@@ -0,0 +1,35 @@
+package userModel
+
+import (
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+const (
+	EmailChangePending   = "pending"
+	EmailChangeConfirmed = "confirmed"
+	EmailChangeCancelled = "cancelled"
+)
+
+// EmailChangeRequest guarda un cambio de email pendiente de confirmación.
+// El email del usuario no cambia hasta que se confirma con el token enviado a la nueva dirección.
+type EmailChangeRequest struct {
+	baseModel.BaseModel
+	UserId      uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	OldEmail    string     `json:"old_email"`
+	NewEmail    string     `gorm:"not null" json:"new_email"`
+	Status      string     `gorm:"index;not null;default:pending" json:"status"`
+	TokenHash   string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+}
+
+type RequestEmailChangeDTO struct {
+	NewEmail *string `json:"new_email" binding:"required,email"`
+}
+
+type ConfirmEmailChangeDTO struct {
+	Token *string `json:"token" binding:"required"`
+}
@@ -1,26 +1,72 @@
 package userModel
 
 import (
+	"time"
+
 	"github.com/aragornz325/piloto-api/internal/profile/model" 
 	"github.com/aragornz325/piloto-api/pkg/model"
 )
 
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
 type User struct {
 	baseModel.BaseModel
 	FirstName string                  `json:"first_name"`
 	LastName  string                  `json:"last_name"`
 	Email     string                  `gorm:"uniqueIndex" json:"email"`
+	// Driver indica si el usuario tiene el alta de conductor aprobada; lo mantiene
+	// driverService y no se puede asignar desde la API de usuarios.
 	Driver    bool                    `json:"driver"`
-	Password  string                  `json:"password,omitempty"`
+	Password  string                  `json:"-"`
 	Role 	  []string 				  `gorm:"type:json" json:"role,omitempty"`
+	Status          string            `gorm:"index;not null;default:active" json:"status"`
+	StatusReason    string            `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time        `json:"status_changed_at,omitempty"`
 	Profile   *profileModel.Profile  `gorm:"foreignKey:UserId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"profile,omitempty"`
 }
 
+// TrackHistory hace que las altas, cambios y bajas de usuarios queden en entity_history.
+func (User) TrackHistory() bool {
+	return true
+}
+
 type CreateUserInput struct {
 	Password  *string `json:"password" binding:"required"`
 	FirstName *string `json:"first_name" binding:"required"`
 	LastName  *string `json:"last_name" binding:"required"`
 	Email     *string `json:"email" binding:"required,email"`
 	Role      *[]string `json:"role" binding:"required"`
-	Driver    *bool   `json:"driver"`
+}
+
+// UpdateUserInput son los datos que se pueden cambiar con PUT /users/:id. La contraseña no
+// se cambia por acá.
+type UpdateUserInput struct {
+	FirstName *string   `json:"first_name"`
+	LastName  *string   `json:"last_name"`
+	Email     *string   `json:"email" binding:"omitempty,email"`
+	Role      *[]string `json:"role"`
+}
+
+// SelectableFields mapea los nombres que acepta ?fields= a columnas de la tabla users.
+// Password no se expone nunca.
+var SelectableFields = map[string]string{
+	"id":         "id",
+	"createdAt":  "created_at",
+	"updatedAt":  "updated_at",
+	"isActive":   "is_active",
+	"version":    "version",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"email":      "email",
+	"driver":     "driver",
+	"role":       "role",
+	"status":     "status",
+}
+
+// Includes mapea los nombres que acepta ?include= a asociaciones de User.
+var Includes = map[string]string{
+	"profile": "Profile.Addresses",
 }
@@ -0,0 +1,57 @@
+package userModel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados de la cuenta. El registro no tiene verificación de email: las cuentas nacen activas.
+const (
+	StatusActive      = "active"
+	StatusSuspended   = "suspended"
+	StatusBanned      = "banned"
+	StatusDeactivated = "deactivated"
+)
+
+// statusTransitions define a qué estados se puede pasar desde cada estado de la cuenta.
+var statusTransitions = map[string][]string{
+	StatusActive:      {StatusSuspended, StatusBanned, StatusDeactivated},
+	StatusSuspended:   {StatusActive, StatusBanned},
+	StatusBanned:      {StatusActive},
+	StatusDeactivated: {StatusActive},
+}
+
+// CanTransition indica si la cuenta puede pasar del estado from al estado to.
+func CanTransition(from, to string) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAuthenticate indica si una cuenta en el estado dado puede loguearse y usar sus tokens.
+func CanAuthenticate(status string) bool {
+	return status == StatusActive || status == ""
+}
+
+// UserStatusTransition registra cada cambio de estado de una cuenta con su motivo y autor.
+type UserStatusTransition struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserId     uuid.UUID  `gorm:"type:uuid;index;not null" json:"user_id"`
+	FromStatus string     `gorm:"not null" json:"from_status"`
+	ToStatus   string     `gorm:"not null" json:"to_status"`
+	Reason     string     `json:"reason"`
+	ActorId    *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ChangeUserStatusDTO struct {
+	Reason *string `json:"reason" binding:"required,min=3"`
+}
+
+type DeactivateUserDTO struct {
+	Reason *string `json:"reason"`
+}
@@ -0,0 +1,133 @@
+package userPresenter
+
+import (
+	"context"
+	"time"
+
+	profilePresenter "github.com/aragornz325/piloto-api/internal/profile/presenter"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/pkg/presenter"
+	"github.com/google/uuid"
+)
+
+// Los tipos de respuesta de usuario nunca incluyen el hash de la contraseña ni otros
+// secretos: los handlers serializan estos tipos y no la entidad de GORM.
+
+// UserPublicResponse es lo que ve cualquier usuario de otro usuario.
+type UserPublicResponse struct {
+	ID        uuid.UUID   `json:"id"`
+	FirstName string      `json:"first_name"`
+	LastName  string      `json:"last_name"`
+	Driver    bool        `json:"driver"`
+	Profile   interface{} `json:"profile,omitempty"`
+}
+
+// UserSelfResponse es lo que ve un usuario de su propia cuenta.
+type UserSelfResponse struct {
+	ID        uuid.UUID   `json:"id"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+	Version   int64       `json:"version"`
+	FirstName string      `json:"first_name"`
+	LastName  string      `json:"last_name"`
+	Email     string      `json:"email"`
+	Driver    bool        `json:"driver"`
+	Role      []string    `json:"role,omitempty"`
+	Status    string      `json:"status"`
+	Profile   interface{} `json:"profile,omitempty"`
+}
+
+// UserAdminResponse agrega los datos de gestión de la cuenta que solo ve un admin.
+type UserAdminResponse struct {
+	UserSelfResponse
+	IsActive        bool       `json:"isActive"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+}
+
+// Present serializa un usuario para la audiencia indicada.
+func Present(user *userModel.User, audience presenter.Audience) interface{} {
+	if user == nil {
+		return nil
+	}
+	var profile interface{}
+	if user.Profile != nil {
+		profile = profilePresenter.Present(user.Profile, audience)
+	}
+
+	switch audience {
+	case presenter.AudienceAdmin:
+		return UserAdminResponse{
+			UserSelfResponse: self(user, profile),
+			IsActive:         user.IsActive,
+			StatusReason:     user.StatusReason,
+			StatusChangedAt:  user.StatusChangedAt,
+		}
+	case presenter.AudienceSelf:
+		return self(user, profile)
+	default:
+		return UserPublicResponse{
+			ID:        user.ID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Driver:    user.Driver,
+			Profile:   profile,
+		}
+	}
+}
+
+// PresentForViewer serializa un usuario resolviendo la audiencia a partir del usuario
+// autenticado en ctx.
+func PresentForViewer(ctx context.Context, user *userModel.User) interface{} {
+	if user == nil {
+		return nil
+	}
+	return Present(user, AudienceFor(ctx, user.ID))
+}
+
+// PresentForOwner serializa un usuario para quien es dueño de la cuenta (registro,
+// aceptación de invitación, confirmaciones); un admin sigue viendo la vista de admin.
+func PresentForOwner(ctx context.Context, user *userModel.User) interface{} {
+	if user == nil {
+		return nil
+	}
+	audience := AudienceFor(ctx, user.ID)
+	if audience != presenter.AudienceAdmin {
+		audience = presenter.AudienceSelf
+	}
+	return Present(user, audience)
+}
+
+// PresentListForViewer serializa una lista de usuarios resolviendo la audiencia de cada uno.
+func PresentListForViewer(ctx context.Context, users []*userModel.User) []interface{} {
+	responses := make([]interface{}, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, PresentForViewer(ctx, user))
+	}
+	return responses
+}
+
+// AudienceFor devuelve la audiencia del usuario autenticado frente a la cuenta ownerId.
+func AudienceFor(ctx context.Context, ownerId uuid.UUID) presenter.Audience {
+	return presenter.AudienceFor(presenter.AudienceForFuncParams{
+		Ctx:        ctx,
+		OwnerId:    ownerId,
+		AdminRoles: []string{userModel.RoleAdmin},
+	})
+}
+
+func self(user *userModel.User, profile interface{}) UserSelfResponse {
+	return UserSelfResponse{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Version:   user.Version,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Driver:    user.Driver,
+		Role:      user.Role,
+		Status:    user.Status,
+		Profile:   profile,
+	}
+}
@@ -0,0 +1,178 @@
+package userPresenter
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/pkg/presenter"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/google/uuid"
+)
+
+const passwordHash = "$2a$10$secretpasswordhashsecretpasswordhash"
+
+var audiences = []presenter.Audience{
+	presenter.AudiencePublic,
+	presenter.AudienceAuthenticated,
+	presenter.AudienceSelf,
+	presenter.AudienceAdmin,
+}
+
+// Claves que ninguna respuesta puede tener, para ninguna audiencia.
+var forbiddenKeys = []string{"password", "code", "code_hash", "token", "token_hash", "otp"}
+
+func TestPresentNeverExposesSecrets(t *testing.T) {
+	user := fullUser()
+	for _, audience := range audiences {
+		t.Run(string(audience), func(t *testing.T) {
+			data, keys := marshal(t, Present(user, audience))
+			if strings.Contains(data, passwordHash) {
+				t.Errorf("response for %s has the password hash: %s", audience, data)
+			}
+			for _, key := range forbiddenKeys {
+				if keys[key] {
+					t.Errorf("response for %s has key %q", audience, key)
+				}
+			}
+		})
+	}
+}
+
+func TestPresentFieldsByAudience(t *testing.T) {
+	user := fullUser()
+	tests := []struct {
+		audience presenter.Audience
+		present  []string
+		absent   []string
+	}{
+		{
+			audience: presenter.AudiencePublic,
+			present:  []string{"id", "first_name", "last_name", "driver"},
+			absent:   []string{"email", "role", "status", "isActive", "status_reason"},
+		},
+		{
+			audience: presenter.AudienceAuthenticated,
+			present:  []string{"id", "first_name", "last_name", "driver"},
+			absent:   []string{"email", "role", "status", "isActive", "status_reason"},
+		},
+		{
+			audience: presenter.AudienceSelf,
+			present:  []string{"email", "role", "status"},
+			absent:   []string{"isActive", "status_reason", "status_changed_at"},
+		},
+		{
+			audience: presenter.AudienceAdmin,
+			present:  []string{"email", "role", "status", "isActive", "status_reason", "status_changed_at"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.audience), func(t *testing.T) {
+			_, keys := marshal(t, Present(user, tt.audience))
+			for _, key := range tt.present {
+				if !keys[key] {
+					t.Errorf("response for %s is missing %q", tt.audience, key)
+				}
+			}
+			for _, key := range tt.absent {
+				if keys[key] {
+					t.Errorf("response for %s has %q", tt.audience, key)
+				}
+			}
+		})
+	}
+}
+
+func TestPresentForViewerNeverExposesSecrets(t *testing.T) {
+	user := fullUser()
+	viewers := map[string]context.Context{
+		"anonymous": context.Background(),
+		"other user": requestctx.WithPrincipal(context.Background(), &requestctx.Principal{
+			UserId: uuid.New(),
+			Role:   userModel.RoleUser,
+		}),
+		"owner": requestctx.WithPrincipal(context.Background(), &requestctx.Principal{
+			UserId: user.ID,
+			Role:   userModel.RoleUser,
+		}),
+		"admin": requestctx.WithPrincipal(context.Background(), &requestctx.Principal{
+			UserId: uuid.New(),
+			Role:   userModel.RoleAdmin,
+		}),
+	}
+	for name, ctx := range viewers {
+		t.Run(name, func(t *testing.T) {
+			for _, response := range []interface{}{
+				PresentForViewer(ctx, user),
+				PresentForOwner(ctx, user),
+				PresentListForViewer(ctx, []*userModel.User{user}),
+			} {
+				data, keys := marshal(t, response)
+				if strings.Contains(data, passwordHash) {
+					t.Errorf("response for %s has the password hash: %s", name, data)
+				}
+				for _, key := range forbiddenKeys {
+					if keys[key] {
+						t.Errorf("response for %s has key %q", name, key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func fullUser() *userModel.User {
+	now := time.Now().UTC()
+	user := &userModel.User{
+		FirstName:       "Ana",
+		LastName:        "Piloto",
+		Email:           "ana@example.com",
+		Password:        passwordHash,
+		Role:            []string{userModel.RoleUser},
+		Status:          "suspended",
+		StatusReason:    "reason",
+		StatusChangedAt: &now,
+	}
+	user.ID = uuid.New()
+	user.IsActive = true
+	user.Profile = &profileModel.Profile{
+		UserId:      user.ID,
+		PhoneNumber: "+5491155551234",
+	}
+	user.Profile.ID = uuid.New()
+	return user
+}
+
+// marshal serializa value y devuelve el JSON y todas sus claves, a cualquier profundidad.
+func marshal(t *testing.T, value interface{}) (string, map[string]bool) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	keys := map[string]bool{}
+	collectKeys(decoded, keys)
+	return string(data), keys
+}
+
+func collectKeys(value interface{}, keys map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			keys[key] = true
+			collectKeys(child, keys)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectKeys(child, keys)
+		}
+	}
+}
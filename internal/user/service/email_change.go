@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/mailer"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailChangeExpiration es la vigencia del link de confirmación de un cambio de email.
+const EmailChangeExpiration = 24 * time.Hour
+
+// EmailChangeService implementa el cambio de email verificado: el nuevo email se guarda
+// como pendiente y solo se aplica cuando el usuario confirma desde la nueva dirección.
+type EmailChangeService interface {
+	RequestEmailChange(RequestEmailChangeFuncParams) (*userModel.EmailChangeRequest, error)
+	ConfirmEmailChange(ConfirmEmailChangeFuncParams) (*userModel.User, error)
+}
+
+type emailChangeService struct {
+	UserService UserService
+	Mailer      mailer.Mailer
+	// BaseURL es la URL pública de la API con la que se arma el link de confirmación.
+	BaseURL string
+}
+
+func NewEmailChangeService(userService UserService, mailer mailer.Mailer, baseURL string) EmailChangeService {
+	return &emailChangeService{
+		UserService: userService,
+		Mailer:      mailer,
+		BaseURL:     baseURL,
+	}
+}
+
+// RequestEmailChange stores a pending email change for the user, cancels any previous
+// pending request, emails a confirmation link to the new address and a notice to the old one.
+//
+// Parameters:
+//   - opts: RequestEmailChangeFuncParams containing the context, the user ID and the new email.
+//
+// Returns:
+//   - *userModel.EmailChangeRequest: The pending request.
+//   - error: A 409 if the new email is already in use, or any other error.
+func (s *emailChangeService) RequestEmailChange(opts RequestEmailChangeFuncParams) (*userModel.EmailChangeRequest, error) {
+	var request userModel.EmailChangeRequest
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "RequestEmailChange",
+		ServiceName: "email change",
+		Operation: func() error {
+			newEmail := strings.ToLower(strings.TrimSpace(opts.NewEmail))
+
+			user, err := s.UserService.GetUserById(GetUserByIdFuncParams{
+				Ctx:    opts.Ctx,
+				UserId: opts.UserId,
+			})
+			if err != nil {
+				return err
+			}
+			if strings.EqualFold(user.Email, newEmail) {
+				return errors.NewBadRequest(errors.ErrorFuncOptions{
+					Message: "new email is the same as the current one",
+				})
+			}
+			if err := ensureEmailAvailable(db.DB.WithContext(opts.Ctx), newEmail); err != nil {
+				return err
+			}
+
+			token, hash, err := utils.GenerateToken()
+			if err != nil {
+				return fmt.Errorf("error generating email change token: %w", err)
+			}
+
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				if err := tx.Model(&userModel.EmailChangeRequest{}).
+					Where("user_id = ? AND status = ?", user.ID, userModel.EmailChangePending).
+					Updates(map[string]interface{}{
+						"status":     userModel.EmailChangeCancelled,
+						"version":    gorm.Expr("version + 1"),
+						"updated_at": time.Now().UTC(),
+					}).Error; err != nil {
+					return fmt.Errorf("error cancelling previous email change requests: %w", err)
+				}
+
+				request = userModel.EmailChangeRequest{
+					UserId:    user.ID,
+					OldEmail:  user.Email,
+					NewEmail:  newEmail,
+					Status:    userModel.EmailChangePending,
+					TokenHash: hash,
+					ExpiresAt: time.Now().UTC().Add(EmailChangeExpiration),
+				}
+				request.IsActive = true
+				if err := tx.Create(&request).Error; err != nil {
+					return fmt.Errorf("error creating email change request: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			return s.notify(opts.Ctx, &request, token)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// ConfirmEmailChange applies a pending email change identified by the token sent to the
+// new address. The uniqueness of the new email is checked again at this point, inside the
+// same transaction that updates the user.
+//
+// Parameters:
+//   - opts: ConfirmEmailChangeFuncParams containing the context and the confirmation token.
+//
+// Returns:
+//   - *userModel.User: The user with the new email.
+//   - error: A 404 for unknown tokens, 410 for expired or used ones, 409 if the email was taken meanwhile.
+func (s *emailChangeService) ConfirmEmailChange(opts ConfirmEmailChangeFuncParams) (*userModel.User, error) {
+	var user userModel.User
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ConfirmEmailChange",
+		ServiceName: "email change",
+		Operation: func() error {
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				var request userModel.EmailChangeRequest
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("token_hash = ?", utils.HashToken(opts.Token)).
+					First(&request).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "email change request not found",
+						})
+					}
+					return fmt.Errorf("error getting email change request: %w", err)
+				}
+
+				now := time.Now().UTC()
+				if request.Status != userModel.EmailChangePending || now.After(request.ExpiresAt) {
+					return errors.NewGone(errors.SimpleErrorFuncOptions{
+						Message: "email change request is no longer valid",
+					})
+				}
+				if err := ensureEmailAvailable(tx, request.NewEmail); err != nil {
+					return err
+				}
+
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					First(&user, request.UserId).Error; err != nil {
+					return fmt.Errorf("error getting user: %w", err)
+				}
+				if err := tx.Model(&user).Updates(map[string]interface{}{
+					"email":      request.NewEmail,
+					"version":    user.Version + 1,
+					"updated_at": now,
+				}).Error; err != nil {
+					return fmt.Errorf("error updating user email: %w", err)
+				}
+
+				return tx.Model(&request).Updates(map[string]interface{}{
+					"status":       userModel.EmailChangeConfirmed,
+					"confirmed_at": now,
+					"version":      request.Version + 1,
+					"updated_at":   now,
+				}).Error
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ensureEmailAvailable devuelve un 409 si algún usuario ya usa el email.
+func ensureEmailAvailable(tx *gorm.DB, email string) error {
+	var count int64
+	if err := tx.Model(&userModel.User{}).
+		Where("LOWER(email) = ?", strings.ToLower(email)).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("error checking email availability: %w", err)
+	}
+	if count > 0 {
+		return errors.NewConflict(errors.SimpleErrorFuncOptions{
+			Message: "email is already in use",
+		})
+	}
+	return nil
+}
+
+func (s *emailChangeService) notify(ctx context.Context, request *userModel.EmailChangeRequest, token string) error {
+	// El link es GET /users/email-change/confirm, que confirma el cambio al abrirlo
+	link := fmt.Sprintf("%s/api/v1/users/email-change/confirm?token=%s", strings.TrimRight(s.BaseURL, "/"), token)
+
+	if err := s.Mailer.Send(mailer.SendMailFuncParams{
+		Ctx:     ctx,
+		To:      request.NewEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Open this link to confirm that you want to use this address for your Piloto de Tormenta account:\n%s\n\nThis link expires on %s.",
+			link, request.ExpiresAt.Format(time.RFC1123)),
+	}); err != nil {
+		return fmt.Errorf("error sending email change confirmation: %w", err)
+	}
+
+	if err := s.Mailer.Send(mailer.SendMailFuncParams{
+		Ctx:     ctx,
+		To:      request.OldEmail,
+		Subject: "Email change requested on your account",
+		Body: fmt.Sprintf("A change of the email of your Piloto de Tormenta account to %s was requested.\nThe change will only be applied if it is confirmed from the new address. If you did not request it, contact support.",
+			request.NewEmail),
+	}); err != nil {
+		return fmt.Errorf("error sending email change notice: %w", err)
+	}
+	return nil
+}
+
+// /-------- structs ---------///
+type RequestEmailChangeFuncParams struct {
+	Ctx      context.Context
+	UserId   uuid.UUID
+	NewEmail string
+}
+
+type ConfirmEmailChangeFuncParams struct {
+	Ctx   context.Context
+	Token string
+}
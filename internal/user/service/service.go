@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"strings"
 	"time"
 
+	profileModel "github.com/aragornz325/piloto-api/internal/profile/model"
 	userModel "github.com/aragornz325/piloto-api/internal/user/model"
 	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
 
 	"github.com/aragornz325/piloto-api/pkg/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Package service contiene la lógica de negocio para el manejo de usuarios
@@ -18,17 +24,24 @@ import (
 // que puede ser implementada por diferentes versiones (mock, prod, etc).
 type UserService interface {
 	CreateUser(CreateUserFuncParams) (*userModel.User, error)
-	GetAllUsers(ctx context.Context) ([]*userModel.User, error)
+	GetAllUsers(GetAllUsersFuncParams) ([]*userModel.User, error)
 	GetUserById(GetUserByIdFuncParams) (*userModel.User, error)
 	UpdateUser(UpdateUserFuncParams) (*userModel.User, error)
-	SoftDeleteUser(GetUserByIdFuncParams) (*userModel.User, error)
+	SoftDeleteUser(SoftDeleteUserFuncParams) (*userModel.User, error)
 	GetUserByEmail(GetUserByEmailFuncParams) (*userModel.User, error)
+	ChangeUserStatus(ChangeUserStatusFuncParams) (*userModel.User, error)
+	ListUserStatusTransitions(GetUserByIdFuncParams) ([]*userModel.UserStatusTransition, error)
 }
 
-type userService struct{}
+type userService struct {
+	// CompletenessWeights son los pesos del puntaje de completitud del perfil, usados para filtrar.
+	CompletenessWeights map[string]int
+}
 
-func NewUserService() UserService {
-	return &userService{}
+func NewUserService(completenessWeights map[string]int) UserService {
+	return &userService{
+		CompletenessWeights: completenessWeights,
+	}
 }
 
 
@@ -50,6 +63,11 @@ func NewUserService() UserService {
 func (s *userService) CreateUser(opts CreateUserFuncParams) (*userModel.User, error) {
 	opts.User.CreatedAt = time.Now().UTC()
 	opts.User.IsActive = true
+	// Solo un alta de conductor aprobada convierte al usuario en conductor
+	opts.User.Driver = false
+	if opts.User.Status == "" {
+		opts.User.Status = userModel.StatusActive
+	}
 	 err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
 		Ctx:  opts.Ctx,
 		Name: "CreateUser",
@@ -71,24 +89,39 @@ func (s *userService) CreateUser(opts CreateUserFuncParams) (*userModel.User, er
 
 // GetAllUsers retrieves all active users from the database.
 // It executes the operation within the provided context and returns a slice of user models.
+// When opts.Query is set, only the requested columns are selected and the requested
+// relations are preloaded in a single extra query per relation.
+// MinCompleteness and MaxCompleteness filter users by the completeness score of their profile
+// (users without a profile score 0).
 // If an error occurs during the database operation, it returns the error.
 // Parameters:
-//   - ctx: The context for the operation.
+//   - opts: GetAllUsersFuncParams containing the context, the optional query options and completeness bounds.
 // Returns:
 //   - []*userModel.User: A slice of pointers to user models.
 //   - error: An error if the operation fails, otherwise nil.
 // This function uses a service operation wrapper to handle the database interaction
 // and error handling. It queries the database for all users where is_active is true.
-func (s *userService) GetAllUsers(ctx context.Context) ([]*userModel.User, error) {
+func (s *userService) GetAllUsers(opts GetAllUsersFuncParams) ([]*userModel.User, error) {
 	var users []*userModel.User
 	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
-		Ctx:  ctx,
+		Ctx:  opts.Ctx,
 		Name: "GetAllUsers",
+		ServiceName: "user",
 		Operation: func() error {
-			if err := db.DB.
-			WithContext(ctx).
-			Where("is_active = ?", true).
-			Find(&users).Error; err != nil {
+			tx := opts.Query.Apply(db.DB.WithContext(opts.Ctx), "id", "version").
+				Where("is_active = ?", true)
+			if opts.MinCompleteness != nil || opts.MaxCompleteness != nil {
+				// El puntaje se calcula en una subconsulta para no ambiguar las columnas seleccionadas
+				score := fmt.Sprintf("COALESCE((SELECT %s FROM profiles WHERE profiles.user_id = users.id AND profiles.deleted_at IS NULL), 0)",
+					profileModel.CompletenessScoreSQL(s.CompletenessWeights))
+				if opts.MinCompleteness != nil {
+					tx = tx.Where(score+" >= ?", *opts.MinCompleteness)
+				}
+				if opts.MaxCompleteness != nil {
+					tx = tx.Where(score+" <= ?", *opts.MaxCompleteness)
+				}
+			}
+			if err := tx.Find(&users).Error; err != nil {
 				return fmt.Errorf("error getting all users: %w", err)
 			}
 			return nil
@@ -105,8 +138,17 @@ func (s *userService) GetAllUsers(ctx context.Context) ([]*userModel.User, error
 // and persists the changes using a service operation wrapper. Returns the updated user
 // or an error if the operation fails.
 //
+// The email cannot be changed here: it goes through the verified email change flow
+// (EmailChangeService) and a different email is rejected with a 400; an email that only
+// differs in case is ignored. The password is never written here. The roles and the
+// status are only written when opts.AsAdmin is set; otherwise they are ignored.
+//
+// The write is conditioned on opts.ExpectedVersion: if the stored version differs
+// (another client updated the user in the meantime) a 412 Precondition Failed error
+// is returned and nothing is written. On success the version is incremented.
+//
 // Parameters:
-//   - opts: UpdateUserFuncParams containing the context, user ID, and updated user data.
+//   - opts: UpdateUserFuncParams containing the context, user ID, expected version and updated user data.
 //
 // Returns:
 //   - *userModel.User: The updated user model.
@@ -119,17 +161,43 @@ func (s *userService) UpdateUser(opts UpdateUserFuncParams) (*userModel.User, er
 	if err != nil {
 		return nil, err
 	}
+	if userDb.Version != opts.ExpectedVersion {
+		return nil, errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+			Message: "user was modified by another request",
+		})
+	}
+	if opts.User.Email != "" && !strings.EqualFold(opts.User.Email, userDb.Email) {
+		return nil, errors.NewBadRequest(errors.ErrorFuncOptions{
+			Message: "email changes must be confirmed through the email change flow",
+		})
+	}
+	// Un email que solo cambia en mayúsculas tampoco se escribe
+	opts.User.Email = ""
 	opts.User.UpdatedAt = time.Now().UTC()
+	opts.User.Version = opts.ExpectedVersion + 1
+	// La contraseña nunca se cambia acá; los roles y el estado solo los cambia un admin
+	omit := []string{clause.Associations, "driver", "password"}
+	if !opts.AsAdmin {
+		omit = append(omit, "role", "status")
+	}
 	err = utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
 		Ctx:  opts.Ctx,
 		Name: "UpdateUser",
 		ServiceName: "user",
 		Operation: func() error {
-			if err := db.DB.
+			result := db.DB.
 			WithContext(opts.Ctx).
 			Model(userDb).
-			Updates(opts.User).Error; err != nil {
-				return fmt.Errorf("error updating user: %w", err)
+			Omit(omit...).
+			Where("version = ?", opts.ExpectedVersion).
+			Updates(opts.User)
+			if result.Error != nil {
+				return fmt.Errorf("error updating user: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "user was modified by another request",
+				})
 			}
 			return nil
 		},
@@ -144,9 +212,10 @@ func (s *userService) UpdateUser(opts UpdateUserFuncParams) (*userModel.User, er
 // GetUserById retrieves a user by their unique ID if the user is active.
 // It performs the operation within the provided context and returns the user model
 // or an error if the user could not be found or another error occurs during the operation.
+// Relations (e.g. Profile) are only loaded when requested through opts.Query.
 //
 // Parameters:
-//   - opts: GetUserByIdFuncParams containing the context and user ID.
+//   - opts: GetUserByIdFuncParams containing the context, user ID and optional query options.
 //
 // Returns:
 //   - *userModel.User: Pointer to the retrieved user model.
@@ -158,10 +227,14 @@ func (s *userService) GetUserById(opts GetUserByIdFuncParams) (*userModel.User,
 		Name: "GetUserById",
 		ServiceName: "user",
 		Operation: func() error {
-			if err := db.DB.WithContext(opts.Ctx).
-				Preload("Profile").
+			if err := opts.Query.Apply(db.DB.WithContext(opts.Ctx), "id", "version").
 				Where("is_active = ?", true).
 				First(&user, opts.UserId).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "user not found",
+					})
+				}
 				return fmt.Errorf("error getting user by ID: %w", err)
 			}
 			return nil
@@ -174,16 +247,17 @@ func (s *userService) GetUserById(opts GetUserByIdFuncParams) (*userModel.User,
 }
 
 // SoftDeleteUser performs a soft delete on a user by setting the DeletedAt timestamp to the current time
-// and marking the user as inactive. It retrieves the user by ID, updates the relevant fields, and persists
-// the changes using the UpdateUser method. Returns the updated user or an error if the operation fails.
+// and marking the user as inactive. It retrieves the user by ID and writes only those columns, with the
+// version check, so IsActive=false is stored even though it is a zero value. Returns the updated user or
+// an error if the operation fails.
 //
 // Parameters:
-//   - opts: GetUserByIdFuncParams containing the context and user ID.
+//   - opts: SoftDeleteUserFuncParams containing the context, user ID and expected version.
 //
 // Returns:
 //   - *userModel.User: Pointer to the updated user model.
 //   - error: Error if the operation fails, otherwise nil.
-func (s *userService) SoftDeleteUser(opts GetUserByIdFuncParams) (*userModel.User, error) {
+func (s *userService) SoftDeleteUser(opts SoftDeleteUserFuncParams) (*userModel.User, error) {
 	now := time.Now().UTC()
 	userPtr, err := s.GetUserById(GetUserByIdFuncParams{
 		Ctx:    opts.Ctx,
@@ -192,22 +266,34 @@ func (s *userService) SoftDeleteUser(opts GetUserByIdFuncParams) (*userModel.Use
 	if err != nil {
 		return nil, fmt.Errorf("error obteniendo el usuario: %w", err)
 	}
-
-	userPtr.DeletedAt.Scan(now)
-	userPtr.IsActive = false
+	if userPtr.Version != opts.ExpectedVersion {
+		return nil, errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+			Message: "user was modified by another request",
+		})
+	}
 
 	err = utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
 		Ctx:  opts.Ctx,
 		Name: "SoftDeleteUser",
 		ServiceName: "user",
 		Operation: func() error {
-			_, err := s.UpdateUser(UpdateUserFuncParams{
-				User:   userPtr,
-				Ctx:    opts.Ctx,
-				UserId: opts.UserId,
-			})
-			if err != nil {
-				return fmt.Errorf("error soft deleting user: %w", err)
+			// Updates(struct) no escribe is_active = false: se escribe con un map
+			result := db.DB.WithContext(opts.Ctx).
+				Model(userPtr).
+				Where("version = ?", opts.ExpectedVersion).
+				Updates(map[string]interface{}{
+					"is_active":  false,
+					"deleted_at": now,
+					"version":    opts.ExpectedVersion + 1,
+					"updated_at": now,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("error soft deleting user: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "user was modified by another request",
+				})
 			}
 			return nil
 		},
@@ -216,6 +302,10 @@ func (s *userService) SoftDeleteUser(opts GetUserByIdFuncParams) (*userModel.Use
 		return nil, err
 	}
 
+	userPtr.IsActive = false
+	userPtr.DeletedAt.Scan(now)
+	userPtr.Version = opts.ExpectedVersion + 1
+	userPtr.UpdatedAt = now
 	return userPtr, nil
 }
 
@@ -255,14 +345,31 @@ type CreateUserFuncParams struct {
 }
 
 type UpdateUserFuncParams struct {
-	User   *userModel.User
-	Ctx    context.Context
-	UserId uuid.UUID
+	User            *userModel.User
+	Ctx             context.Context
+	UserId          uuid.UUID
+	ExpectedVersion int64
+	// AsAdmin permite cambiar los roles y el estado; si no, se ignoran.
+	AsAdmin bool
 }
 
 type GetUserByIdFuncParams struct {
 	Ctx    context.Context
 	UserId uuid.UUID
+	Query  *utils.QueryOptions
+}
+
+type GetAllUsersFuncParams struct {
+	Ctx             context.Context
+	Query           *utils.QueryOptions
+	MinCompleteness *int
+	MaxCompleteness *int
+}
+
+type SoftDeleteUserFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	ExpectedVersion int64
 }
 type GetUserByEmailFuncParams struct {
 	Ctx   context.Context
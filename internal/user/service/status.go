@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	userModel "github.com/aragornz325/piloto-api/internal/user/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChangeUserStatus moves a user account to a new lifecycle status (active, suspended,
+// banned, deactivated...) if the transition is allowed by the state machine defined in
+// userModel. The change and a transition record with the reason and the actor are written
+// in the same transaction, conditioned on the current status so concurrent changes fail.
+//
+// Parameters:
+//   - opts: ChangeUserStatusFuncParams containing the context, user ID, target status, reason and actor.
+//
+// Returns:
+//   - *userModel.User: The user with the new status.
+//   - error: A 409 if the transition is not allowed, or any other error.
+func (s *userService) ChangeUserStatus(opts ChangeUserStatusFuncParams) (*userModel.User, error) {
+	var user *userModel.User
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ChangeUserStatus",
+		ServiceName: "user",
+		Operation: func() error {
+			var err error
+			user, err = s.GetUserById(GetUserByIdFuncParams{
+				Ctx:    opts.Ctx,
+				UserId: opts.UserId,
+			})
+			if err != nil {
+				return err
+			}
+
+			from := user.Status
+			if from == "" {
+				from = userModel.StatusActive
+			}
+			if !userModel.CanTransition(from, opts.To) {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: fmt.Sprintf("cannot change account status from %s to %s", from, opts.To),
+				})
+			}
+
+			now := time.Now().UTC()
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(user).
+					Where("status = ? AND version = ?", user.Status, user.Version).
+					Updates(map[string]interface{}{
+						"status":            opts.To,
+						"status_reason":     opts.Reason,
+						"status_changed_at": now,
+						"version":           user.Version + 1,
+						"updated_at":        now,
+					})
+				if result.Error != nil {
+					return fmt.Errorf("error changing user status: %w", result.Error)
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "user was modified by another request",
+					})
+				}
+
+				transition := userModel.UserStatusTransition{
+					ID:         uuid.New(),
+					UserId:     user.ID,
+					FromStatus: from,
+					ToStatus:   opts.To,
+					Reason:     opts.Reason,
+					ActorId:    opts.ActorId,
+					CreatedAt:  now,
+				}
+				if err := tx.Create(&transition).Error; err != nil {
+					return fmt.Errorf("error recording status transition: %w", err)
+				}
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListUserStatusTransitions returns the status transitions of a user, newest first.
+//
+// Parameters:
+//   - opts: GetUserByIdFuncParams containing the context and user ID.
+//
+// Returns:
+//   - []*userModel.UserStatusTransition: The recorded transitions.
+//   - error: An error if the query fails.
+func (s *userService) ListUserStatusTransitions(opts GetUserByIdFuncParams) ([]*userModel.UserStatusTransition, error) {
+	var transitions []*userModel.UserStatusTransition
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListUserStatusTransitions",
+		ServiceName: "user",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("user_id = ?", opts.UserId).
+				Order("created_at DESC").
+				Find(&transitions).Error; err != nil {
+				return fmt.Errorf("error listing status transitions: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// /-------- structs ---------///
+type ChangeUserStatusFuncParams struct {
+	Ctx     context.Context
+	UserId  uuid.UUID
+	To      string
+	Reason  string
+	ActorId *uuid.UUID
+}
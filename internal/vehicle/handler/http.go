@@ -0,0 +1,340 @@
+package vehicleHandler
+
+import (
+	"net/http"
+
+	m "github.com/aragornz325/piloto-api/internal/vehicle/model"
+	vehicleService "github.com/aragornz325/piloto-api/internal/vehicle/service"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type VehicleHandler struct {
+	VehicleService vehicleService.VehicleService
+}
+
+func NewVehicleHandler(vehicleService vehicleService.VehicleService) *VehicleHandler {
+	return &VehicleHandler{
+		VehicleService: vehicleService,
+	}
+}
+
+//----------------------------------------------------
+
+// @Summary List vehicles
+// @Description List the vehicles of a driver, oldest first. The vehicle the driver is operating with has selected=true.
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} vehicleModel.Vehicle
+// @Failure 400 {object} ErrorResponse
+// @Router /users/{id}/vehicles [get]
+func (h *VehicleHandler) ListVehiclesHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	result, err := h.VehicleService.ListVehicles(vehicleService.ListVehiclesFuncParams{
+		Ctx:    c.Request.Context(),
+		UserId: userId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Get vehicle
+// @Description Get a vehicle of a driver
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "User ID"
+// @Param vehicleId path string true "Vehicle ID"
+// @Success 200 {object} vehicleModel.Vehicle
+// @Header 200 {string} ETag "Current version of the vehicle"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/vehicles/{vehicleId} [get]
+func (h *VehicleHandler) GetVehicleHandler(c *gin.Context) {
+	userId, vehicleId, ok := parseVehiclePath(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.VehicleService.GetVehicle(vehicleService.GetVehicleFuncParams{
+		Ctx:       c.Request.Context(),
+		UserId:    userId,
+		VehicleId: vehicleId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Create vehicle
+// @Description Add a vehicle to a registered driver. The plate must be unique and the vehicle stays pending until an admin verifies it.
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body vehicleModel.CreateVehicleDTO true "Vehicle data"
+// @Success 201 {object} vehicleModel.Vehicle
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/vehicles [post]
+func (h *VehicleHandler) CreateVehicleHandler(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return
+	}
+
+	var payload m.CreateVehicleDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.VehicleService.CreateVehicle(vehicleService.CreateVehicleFuncParams{
+		Ctx:     c.Request.Context(),
+		UserId:  userId,
+		Vehicle: payload,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusCreated, result)
+}
+
+// @Summary Update vehicle
+// @Description Update the fields sent. Changing the plate, make, model or year sends the vehicle back to verification and deselects it.
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param vehicleId path string true "Vehicle ID"
+// @Param If-Match header string true "ETag of the vehicle being updated"
+// @Param input body vehicleModel.UpdateVehicleDTO true "Fields to update"
+// @Success 200 {object} vehicleModel.Vehicle
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Router /users/{id}/vehicles/{vehicleId} [put]
+func (h *VehicleHandler) UpdateVehicleHandler(c *gin.Context) {
+	userId, vehicleId, ok := parseVehiclePath(c)
+	if !ok {
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var payload m.UpdateVehicleDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+
+	result, err := h.VehicleService.UpdateVehicle(vehicleService.UpdateVehicleFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		VehicleId:       vehicleId,
+		Changes:         payload,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	utils.SetETag(c, result.Version)
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Delete vehicle
+// @Description Soft delete a vehicle, releasing its plate. If it was the selected vehicle the driver is left without one.
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "User ID"
+// @Param vehicleId path string true "Vehicle ID"
+// @Param If-Match header string true "ETag of the vehicle being deleted"
+// @Success 200 {object} vehicleModel.Vehicle
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
+// @Router /users/{id}/vehicles/{vehicleId} [delete]
+func (h *VehicleHandler) DeleteVehicleHandler(c *gin.Context) {
+	userId, vehicleId, ok := parseVehiclePath(c)
+	if !ok {
+		return
+	}
+	expectedVersion, err := utils.RequireIfMatch(c)
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusPreconditionFailed), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := h.VehicleService.DeleteVehicle(vehicleService.DeleteVehicleFuncParams{
+		Ctx:             c.Request.Context(),
+		UserId:          userId,
+		VehicleId:       vehicleId,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Select vehicle
+// @Description Set the vehicle the driver operates with. Only verified vehicles with valid insurance can be selected.
+// @Tags vehicles
+// @Produce json
+// @Param id path string true "User ID"
+// @Param vehicleId path string true "Vehicle ID"
+// @Success 200 {object} vehicleModel.Vehicle
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/{id}/vehicles/{vehicleId}/select [post]
+func (h *VehicleHandler) SelectVehicleHandler(c *gin.Context) {
+	userId, vehicleId, ok := parseVehiclePath(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.VehicleService.SelectVehicle(vehicleService.GetVehicleFuncParams{
+		Ctx:       c.Request.Context(),
+		UserId:    userId,
+		VehicleId: vehicleId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary List vehicles for review
+// @Description List the vehicles with the given verification status, oldest first. Defaults to the vehicles pending verification.
+// @Tags vehicles
+// @Produce json
+// @Param status query string false "Verification status (pending, verified, rejected)"
+// @Success 200 {array} vehicleModel.Vehicle
+// @Router /vehicles [get]
+func (h *VehicleHandler) ListVehiclesForReviewHandler(c *gin.Context) {
+	result, err := h.VehicleService.ListVehiclesForReview(vehicleService.ListVehiclesForReviewFuncParams{
+		Ctx:    c.Request.Context(),
+		Status: c.Query("status"),
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Verify vehicle
+// @Description Mark a pending vehicle as verified so the driver can select it
+// @Tags vehicles
+// @Produce json
+// @Param vehicleId path string true "Vehicle ID"
+// @Success 200 {object} vehicleModel.Vehicle
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /vehicles/{vehicleId}/verify [post]
+func (h *VehicleHandler) VerifyVehicleHandler(c *gin.Context) {
+	h.reviewVehicle(c, true, "")
+}
+
+// @Summary Reject vehicle
+// @Description Reject a pending vehicle with a reason shown to the driver, who can fix the data to send it to verification again.
+// @Tags vehicles
+// @Accept json
+// @Produce json
+// @Param vehicleId path string true "Vehicle ID"
+// @Param input body vehicleModel.RejectVehicleDTO true "Rejection reason"
+// @Success 200 {object} vehicleModel.Vehicle
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /vehicles/{vehicleId}/reject [post]
+func (h *VehicleHandler) RejectVehicleHandler(c *gin.Context) {
+	var payload m.RejectVehicleDTO
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Fields: utils.BindingFieldErrors(err, &payload)})
+		return
+	}
+	h.reviewVehicle(c, false, *payload.Reason)
+}
+
+func (h *VehicleHandler) reviewVehicle(c *gin.Context, approve bool, reason string) {
+	vehicleId, err := uuid.Parse(c.Param("vehicleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid vehicle ID"})
+		return
+	}
+	var reviewerId *uuid.UUID
+	if principal, ok := requestctx.PrincipalFrom(c.Request.Context()); ok {
+		reviewerId = &principal.UserId
+	}
+
+	result, err := h.VehicleService.ReviewVehicle(vehicleService.ReviewVehicleFuncParams{
+		Ctx:        c.Request.Context(),
+		VehicleId:  vehicleId,
+		Approve:    approve,
+		Reason:     reason,
+		ReviewerId: reviewerId,
+	})
+	if err != nil {
+		c.JSON(errors.StatusCode(err, http.StatusInternalServerError), ErrorResponse{Error: err.Error(), Fields: errors.FieldErrors(err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseVehiclePath lee los IDs de usuario y vehículo de la ruta; responde 400 si no son válidos.
+func parseVehiclePath(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	vehicleId, err := uuid.Parse(c.Param("vehicleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid vehicle ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userId, vehicleId, true
+}
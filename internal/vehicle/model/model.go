@@ -0,0 +1,76 @@
+package vehicleModel
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/model"
+	"github.com/google/uuid"
+)
+
+const (
+	VerificationPending  = "pending"
+	VerificationVerified = "verified"
+	VerificationRejected = "rejected"
+)
+
+const (
+	// MinYear es el año de fabricación más antiguo aceptado.
+	MinYear = 1980
+	// MaxCapacity es la cantidad máxima de pasajeros de un vehículo.
+	MaxCapacity = 60
+)
+
+// PlatePattern valida la patente ya normalizada (mayúsculas, sin espacios ni guiones).
+var PlatePattern = regexp.MustCompile(`^[A-Z0-9]{5,10}$`)
+
+// Vehicle es un vehículo que opera un conductor. La patente es única entre los vehículos
+// no borrados y un admin verifica los datos antes de que se pueda usar.
+type Vehicle struct {
+	baseModel.BaseModel
+	UserId             uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Plate              string     `gorm:"not null;uniqueIndex:idx_vehicles_plate,where:deleted_at IS NULL" json:"plate"`
+	Make               string     `gorm:"not null" json:"make"`
+	Model              string     `gorm:"not null" json:"model"`
+	Year               int        `gorm:"not null" json:"year"`
+	Color              string     `json:"color"`
+	Capacity           int        `gorm:"not null" json:"capacity"`
+	InsuranceExpiresAt time.Time  `gorm:"not null;index" json:"insurance_expires_at"`
+	VerificationStatus string     `gorm:"index;not null;default:pending" json:"verification_status"`
+	VerificationReason string     `json:"verification_reason,omitempty"`
+	VerifiedBy         *uuid.UUID `gorm:"type:uuid" json:"verified_by,omitempty"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+	// Selected indica si es el vehículo que el conductor está usando (Driver.ActiveVehicleId).
+	Selected bool `gorm:"-" json:"selected"`
+}
+
+// InsuranceExpired indica si el seguro está vencido en now.
+func (v *Vehicle) InsuranceExpired(now time.Time) bool {
+	return !now.Before(v.InsuranceExpiresAt)
+}
+
+// CreateVehicleDTO son los datos de un vehículo; el vencimiento del seguro es "YYYY-MM-DD".
+type CreateVehicleDTO struct {
+	Plate              *string `json:"plate" binding:"required"`
+	Make               *string `json:"make" binding:"required,max=40"`
+	Model              *string `json:"model" binding:"required,max=40"`
+	Year               *int    `json:"year" binding:"required"`
+	Color              *string `json:"color" binding:"omitempty,max=30"`
+	Capacity           *int    `json:"capacity" binding:"required"`
+	InsuranceExpiresAt *string `json:"insurance_expires_at" binding:"required"`
+}
+
+// UpdateVehicleDTO actualiza solo los campos enviados.
+type UpdateVehicleDTO struct {
+	Plate              *string `json:"plate"`
+	Make               *string `json:"make" binding:"omitempty,max=40"`
+	Model              *string `json:"model" binding:"omitempty,max=40"`
+	Year               *int    `json:"year"`
+	Color              *string `json:"color" binding:"omitempty,max=30"`
+	Capacity           *int    `json:"capacity"`
+	InsuranceExpiresAt *string `json:"insurance_expires_at"`
+}
+
+type RejectVehicleDTO struct {
+	Reason *string `json:"reason" binding:"required,min=3,max=500"`
+}
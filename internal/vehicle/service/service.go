@@ -0,0 +1,650 @@
+package vehicleService
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	driverModel "github.com/aragornz325/piloto-api/internal/driver/model"
+	vehicleModel "github.com/aragornz325/piloto-api/internal/vehicle/model"
+	db "github.com/aragornz325/piloto-api/pkg/database"
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/aragornz325/piloto-api/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Package vehicleService maneja los vehículos de los conductores, su verificación por parte
+// de los admins y la elección del vehículo con el que opera cada conductor.
+
+type VehicleService interface {
+	ListVehicles(ListVehiclesFuncParams) ([]*vehicleModel.Vehicle, error)
+	GetVehicle(GetVehicleFuncParams) (*vehicleModel.Vehicle, error)
+	CreateVehicle(CreateVehicleFuncParams) (*vehicleModel.Vehicle, error)
+	UpdateVehicle(UpdateVehicleFuncParams) (*vehicleModel.Vehicle, error)
+	DeleteVehicle(DeleteVehicleFuncParams) (*vehicleModel.Vehicle, error)
+	SelectVehicle(GetVehicleFuncParams) (*vehicleModel.Vehicle, error)
+	ListVehiclesForReview(ListVehiclesForReviewFuncParams) ([]*vehicleModel.Vehicle, error)
+	ReviewVehicle(ReviewVehicleFuncParams) (*vehicleModel.Vehicle, error)
+}
+
+type vehicleService struct{}
+
+func NewVehicleService() VehicleService {
+	return &vehicleService{}
+}
+
+// ListVehicles returns the vehicles of a user, oldest first, marking the selected one.
+//
+// Parameters:
+//   - opts: ListVehiclesFuncParams containing the context and the user ID.
+//
+// Returns:
+//   - []*vehicleModel.Vehicle: The user's vehicles.
+//   - error: An error if the query fails.
+func (s *vehicleService) ListVehicles(opts ListVehiclesFuncParams) ([]*vehicleModel.Vehicle, error) {
+	vehicles := []*vehicleModel.Vehicle{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListVehicles",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("user_id = ?", opts.UserId).
+				Order("created_at ASC").
+				Find(&vehicles).Error; err != nil {
+				return fmt.Errorf("error listing vehicles: %w", err)
+			}
+			activeId, err := activeVehicleId(db.DB.WithContext(opts.Ctx), opts.UserId)
+			if err != nil {
+				return err
+			}
+			for _, vehicle := range vehicles {
+				vehicle.Selected = activeId != nil && *activeId == vehicle.ID
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vehicles, nil
+}
+
+// GetVehicle returns one vehicle of a user.
+//
+// Parameters:
+//   - opts: GetVehicleFuncParams containing the context, the user ID and the vehicle ID.
+//
+// Returns:
+//   - *vehicleModel.Vehicle: The vehicle.
+//   - error: A 404 if the user has no vehicle with that ID.
+func (s *vehicleService) GetVehicle(opts GetVehicleFuncParams) (*vehicleModel.Vehicle, error) {
+	var vehicle vehicleModel.Vehicle
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "GetVehicle",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("id = ? AND user_id = ?", opts.VehicleId, opts.UserId).
+				First(&vehicle).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "vehicle not found",
+					})
+				}
+				return fmt.Errorf("error getting vehicle: %w", err)
+			}
+			activeId, err := activeVehicleId(db.DB.WithContext(opts.Ctx), opts.UserId)
+			if err != nil {
+				return err
+			}
+			vehicle.Selected = activeId != nil && *activeId == vehicle.ID
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// CreateVehicle adds a vehicle to a user registered as a driver. The vehicle starts pending
+// verification by an admin.
+//
+// Parameters:
+//   - opts: CreateVehicleFuncParams containing the context, the user ID and the vehicle data.
+//
+// Returns:
+//   - *vehicleModel.Vehicle: The created vehicle.
+//   - error: A 400 with the invalid fields, 403 if the user is not registered as a driver or 409 if the plate is in use.
+func (s *vehicleService) CreateVehicle(opts CreateVehicleFuncParams) (*vehicleModel.Vehicle, error) {
+	vehicle := vehicleModel.Vehicle{UserId: opts.UserId}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "CreateVehicle",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			var count int64
+			if err := db.DB.WithContext(opts.Ctx).Model(&driverModel.Driver{}).
+				Where("user_id = ?", opts.UserId).
+				Count(&count).Error; err != nil {
+				return fmt.Errorf("error getting driver: %w", err)
+			}
+			if count == 0 {
+				return errors.NewForbidden(errors.ErrorFuncOptions{
+					Message: "user must register as a driver before adding vehicles",
+				})
+			}
+
+			if err := applyChanges(&vehicle, vehicleModel.UpdateVehicleDTO(opts.Vehicle), true); err != nil {
+				return err
+			}
+			vehicle.VerificationStatus = vehicleModel.VerificationPending
+			if err := db.DB.WithContext(opts.Ctx).Create(&vehicle).Error; err != nil {
+				if db.IsUniqueViolation(err) {
+					return plateInUse()
+				}
+				return fmt.Errorf("error creating vehicle: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// UpdateVehicle changes the fields sent in opts.Changes. Changing the plate, make, model or
+// year sends the vehicle back to pending verification and deselects it.
+// The write only succeeds if the stored version matches opts.ExpectedVersion.
+//
+// Parameters:
+//   - opts: UpdateVehicleFuncParams containing the context, the user and vehicle IDs, the changes and the expected version.
+//
+// Returns:
+//   - *vehicleModel.Vehicle: The updated vehicle.
+//   - error: A 400 with the invalid fields, 404 if not found, 409 if the plate is in use or 412 on a version mismatch.
+func (s *vehicleService) UpdateVehicle(opts UpdateVehicleFuncParams) (*vehicleModel.Vehicle, error) {
+	var vehicle *vehicleModel.Vehicle
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "UpdateVehicle",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			current, err := s.GetVehicle(GetVehicleFuncParams{
+				Ctx:       opts.Ctx,
+				UserId:    opts.UserId,
+				VehicleId: opts.VehicleId,
+			})
+			if err != nil {
+				return err
+			}
+			if current.Version != opts.ExpectedVersion {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "vehicle was modified by another request",
+				})
+			}
+
+			updated := *current
+			if err := applyChanges(&updated, opts.Changes, false); err != nil {
+				return err
+			}
+			reverify := updated.Plate != current.Plate || updated.Make != current.Make ||
+				updated.Model != current.Model || updated.Year != current.Year
+			if reverify {
+				updated.VerificationStatus = vehicleModel.VerificationPending
+				updated.VerificationReason = ""
+				updated.VerifiedBy = nil
+				updated.VerifiedAt = nil
+			}
+
+			now := time.Now().UTC()
+			updated.Version = opts.ExpectedVersion + 1
+			updated.UpdatedAt = now
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(current).
+					Where("version = ?", opts.ExpectedVersion).
+					Updates(map[string]interface{}{
+						"plate":                updated.Plate,
+						"make":                 updated.Make,
+						"model":                updated.Model,
+						"year":                 updated.Year,
+						"color":                updated.Color,
+						"capacity":             updated.Capacity,
+						"insurance_expires_at": updated.InsuranceExpiresAt,
+						"verification_status":  updated.VerificationStatus,
+						"verification_reason":  updated.VerificationReason,
+						"verified_by":          updated.VerifiedBy,
+						"verified_at":          updated.VerifiedAt,
+						"version":              updated.Version,
+						"updated_at":           now,
+					})
+				if result.Error != nil {
+					if db.IsUniqueViolation(result.Error) {
+						return plateInUse()
+					}
+					return fmt.Errorf("error updating vehicle: %w", result.Error)
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "vehicle was modified by another request",
+					})
+				}
+				if reverify && current.Selected {
+					updated.Selected = false
+					return deselectVehicle(tx, current.UserId, current.ID)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			vehicle = &updated
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+// DeleteVehicle soft deletes a vehicle, releasing its plate. If it was the selected
+// vehicle, the driver is left without a selected vehicle.
+//
+// Parameters:
+//   - opts: DeleteVehicleFuncParams containing the context, the user and vehicle IDs and the expected version.
+//
+// Returns:
+//   - *vehicleModel.Vehicle: The deleted vehicle.
+//   - error: A 404 if not found or 412 on a version mismatch.
+func (s *vehicleService) DeleteVehicle(opts DeleteVehicleFuncParams) (*vehicleModel.Vehicle, error) {
+	var vehicle *vehicleModel.Vehicle
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "DeleteVehicle",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			current, err := s.GetVehicle(GetVehicleFuncParams{
+				Ctx:       opts.Ctx,
+				UserId:    opts.UserId,
+				VehicleId: opts.VehicleId,
+			})
+			if err != nil {
+				return err
+			}
+			if current.Version != opts.ExpectedVersion {
+				return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+					Message: "vehicle was modified by another request",
+				})
+			}
+
+			now := time.Now().UTC()
+			err = db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(current).
+					Where("version = ?", opts.ExpectedVersion).
+					Updates(map[string]interface{}{
+						"is_active":  false,
+						"deleted_at": now,
+						"version":    opts.ExpectedVersion + 1,
+						"updated_at": now,
+					})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+						Message: "vehicle was modified by another request",
+					})
+				}
+				return deselectVehicle(tx, current.UserId, current.ID)
+			})
+			if err != nil {
+				return err
+			}
+			current.IsActive = false
+			current.Selected = false
+			current.DeletedAt.Scan(now)
+			current.Version = opts.ExpectedVersion + 1
+			current.UpdatedAt = now
+			vehicle = current
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+// SelectVehicle sets the vehicle the driver operates with. Only verified vehicles with
+// valid insurance can be selected.
+//
+// Parameters:
+//   - opts: GetVehicleFuncParams containing the context, the user ID and the vehicle ID.
+//
+// Returns:
+//   - *vehicleModel.Vehicle: The selected vehicle.
+//   - error: A 404 if not found, or 409 if the vehicle is not verified or its insurance expired.
+func (s *vehicleService) SelectVehicle(opts GetVehicleFuncParams) (*vehicleModel.Vehicle, error) {
+	var vehicle vehicleModel.Vehicle
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "SelectVehicle",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			return db.DB.WithContext(opts.Ctx).Transaction(func(tx *gorm.DB) error {
+				var driver driverModel.Driver
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("user_id = ?", opts.UserId).
+					First(&driver).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "driver not found",
+						})
+					}
+					return fmt.Errorf("error getting driver: %w", err)
+				}
+				if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).
+					Where("id = ? AND user_id = ?", opts.VehicleId, opts.UserId).
+					First(&vehicle).Error; err != nil {
+					if stderrors.Is(err, gorm.ErrRecordNotFound) {
+						return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+							Message: "vehicle not found",
+						})
+					}
+					return fmt.Errorf("error getting vehicle: %w", err)
+				}
+				if vehicle.VerificationStatus != vehicleModel.VerificationVerified {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "only verified vehicles can be selected",
+					})
+				}
+				if vehicle.InsuranceExpired(time.Now().UTC()) {
+					return errors.NewConflict(errors.SimpleErrorFuncOptions{
+						Message: "vehicle insurance is expired",
+					})
+				}
+
+				vehicle.Selected = true
+				if driver.ActiveVehicleId != nil && *driver.ActiveVehicleId == vehicle.ID {
+					return nil
+				}
+				if err := tx.Model(&driver).Updates(map[string]interface{}{
+					"active_vehicle_id": vehicle.ID,
+					"version":           driver.Version + 1,
+					"updated_at":        time.Now().UTC(),
+				}).Error; err != nil {
+					return fmt.Errorf("error selecting vehicle: %w", err)
+				}
+				return nil
+			})
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// ListVehiclesForReview returns the vehicles with the given verification status (pending by
+// default), oldest first, which is the verification queue for admins.
+//
+// Parameters:
+//   - opts: ListVehiclesForReviewFuncParams containing the context and the optional status.
+//
+// Returns:
+//   - []*vehicleModel.Vehicle: The vehicles.
+//   - error: An error if the query fails.
+func (s *vehicleService) ListVehiclesForReview(opts ListVehiclesForReviewFuncParams) ([]*vehicleModel.Vehicle, error) {
+	vehicles := []*vehicleModel.Vehicle{}
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ListVehiclesForReview",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			status := opts.Status
+			if status == "" {
+				status = vehicleModel.VerificationPending
+			}
+			if err := db.DB.WithContext(opts.Ctx).
+				Where("verification_status = ?", status).
+				Order("created_at ASC").
+				Find(&vehicles).Error; err != nil {
+				return fmt.Errorf("error listing vehicles: %w", err)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vehicles, nil
+}
+
+// ReviewVehicle verifies or rejects a vehicle pending verification. Rejections require a
+// reason, which is shown to the driver.
+//
+// Parameters:
+//   - opts: ReviewVehicleFuncParams containing the context, the vehicle ID, the decision, the reason and the reviewer.
+//
+// Returns:
+//   - *vehicleModel.Vehicle: The reviewed vehicle.
+//   - error: A 400 if a rejection has no reason, 404 if not found or 409 if the vehicle is not pending verification.
+func (s *vehicleService) ReviewVehicle(opts ReviewVehicleFuncParams) (*vehicleModel.Vehicle, error) {
+	var vehicle vehicleModel.Vehicle
+	err := utils.PerformServiceOperation(utils.PerformServiceOperationFunc{
+		Ctx:         opts.Ctx,
+		Name:        "ReviewVehicle",
+		ServiceName: "vehicle",
+		Operation: func() error {
+			reason := strings.TrimSpace(opts.Reason)
+			if !opts.Approve && reason == "" {
+				return errors.NewValidation(errors.ValidationErrorFuncOptions{
+					Message: "invalid review",
+					Fields:  map[string]string{"reason": "is required to reject a vehicle"},
+				})
+			}
+			if err := db.DB.WithContext(opts.Ctx).Where("id = ?", opts.VehicleId).First(&vehicle).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.NewNotFound(errors.SimpleErrorFuncOptions{
+						Message: "vehicle not found",
+					})
+				}
+				return fmt.Errorf("error getting vehicle: %w", err)
+			}
+			if vehicle.VerificationStatus != vehicleModel.VerificationPending {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: fmt.Sprintf("vehicle is %s, only pending vehicles can be reviewed", vehicle.VerificationStatus),
+				})
+			}
+
+			now := time.Now().UTC()
+			status := vehicleModel.VerificationRejected
+			if opts.Approve {
+				status = vehicleModel.VerificationVerified
+				reason = ""
+			}
+			result := db.DB.WithContext(opts.Ctx).Model(&vehicle).
+				Where("version = ?", vehicle.Version).
+				Updates(map[string]interface{}{
+					"verification_status": status,
+					"verification_reason": reason,
+					"verified_by":         opts.ReviewerId,
+					"verified_at":         now,
+					"version":             vehicle.Version + 1,
+					"updated_at":          now,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("error reviewing vehicle: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return errors.NewConflict(errors.SimpleErrorFuncOptions{
+					Message: "vehicle was modified while it was being reviewed",
+				})
+			}
+			vehicle.VerificationStatus = status
+			vehicle.VerificationReason = reason
+			vehicle.VerifiedBy = opts.ReviewerId
+			vehicle.VerifiedAt = &now
+			vehicle.Version++
+			vehicle.UpdatedAt = now
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// applyChanges copia los campos enviados al vehículo y valida el resultado. En el alta
+// (creating) el vencimiento del seguro siempre se valida; en una edición solo si cambia.
+func applyChanges(vehicle *vehicleModel.Vehicle, changes vehicleModel.UpdateVehicleDTO, creating bool) error {
+	fields := map[string]string{}
+
+	if changes.Plate != nil {
+		vehicle.Plate = normalizePlate(*changes.Plate)
+	}
+	if !vehicleModel.PlatePattern.MatchString(vehicle.Plate) {
+		fields["plate"] = "must be 5 to 10 letters or digits"
+	}
+	if changes.Make != nil {
+		vehicle.Make = strings.TrimSpace(*changes.Make)
+	}
+	if vehicle.Make == "" {
+		fields["make"] = "is required"
+	}
+	if changes.Model != nil {
+		vehicle.Model = strings.TrimSpace(*changes.Model)
+	}
+	if vehicle.Model == "" {
+		fields["model"] = "is required"
+	}
+	if changes.Year != nil {
+		vehicle.Year = *changes.Year
+	}
+	if maxYear := time.Now().UTC().Year() + 1; vehicle.Year < vehicleModel.MinYear || vehicle.Year > maxYear {
+		fields["year"] = fmt.Sprintf("must be between %d and %d", vehicleModel.MinYear, maxYear)
+	}
+	if changes.Color != nil {
+		vehicle.Color = strings.TrimSpace(*changes.Color)
+	}
+	if changes.Capacity != nil {
+		vehicle.Capacity = *changes.Capacity
+	}
+	if vehicle.Capacity < 1 || vehicle.Capacity > vehicleModel.MaxCapacity {
+		fields["capacity"] = fmt.Sprintf("must be between 1 and %d", vehicleModel.MaxCapacity)
+	}
+	if changes.InsuranceExpiresAt != nil {
+		expiresAt, err := time.Parse("2006-01-02", strings.TrimSpace(*changes.InsuranceExpiresAt))
+		if err != nil {
+			fields["insurance_expires_at"] = "must be a date in YYYY-MM-DD format"
+		} else if !expiresAt.After(time.Now().UTC()) {
+			fields["insurance_expires_at"] = "insurance is expired"
+		}
+		vehicle.InsuranceExpiresAt = expiresAt
+	} else if creating {
+		fields["insurance_expires_at"] = "is required"
+	}
+
+	if len(fields) > 0 {
+		return errors.NewValidation(errors.ValidationErrorFuncOptions{
+			Message: "invalid vehicle",
+			Fields:  fields,
+		})
+	}
+	return nil
+}
+
+// normalizePlate pasa la patente a mayúsculas y quita espacios, guiones y puntos.
+func normalizePlate(value string) string {
+	return strings.ToUpper(strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '.'
+	}), ""))
+}
+
+// activeVehicleId devuelve el vehículo elegido por el conductor, o nil si no tiene.
+func activeVehicleId(tx *gorm.DB, userId uuid.UUID) (*uuid.UUID, error) {
+	var driver driverModel.Driver
+	err := tx.Select("id", "active_vehicle_id").Where("user_id = ?", userId).First(&driver).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting driver: %w", err)
+	}
+	return driver.ActiveVehicleId, nil
+}
+
+// deselectVehicle deja al conductor sin vehículo elegido si el elegido es vehicleId.
+func deselectVehicle(tx *gorm.DB, userId uuid.UUID, vehicleId uuid.UUID) error {
+	if err := tx.Model(&driverModel.Driver{}).
+		Where("user_id = ? AND active_vehicle_id = ?", userId, vehicleId).
+		Updates(map[string]interface{}{
+			"active_vehicle_id": nil,
+			"version":           gorm.Expr("version + 1"),
+			"updated_at":        time.Now().UTC(),
+		}).Error; err != nil {
+		return fmt.Errorf("error deselecting vehicle: %w", err)
+	}
+	return nil
+}
+
+func plateInUse() error {
+	return errors.NewConflict(errors.SimpleErrorFuncOptions{
+		Message: "a vehicle with this plate is already registered",
+	})
+}
+
+// /------ structs ------///
+type ListVehiclesFuncParams struct {
+	Ctx    context.Context
+	UserId uuid.UUID
+}
+
+type GetVehicleFuncParams struct {
+	Ctx       context.Context
+	UserId    uuid.UUID
+	VehicleId uuid.UUID
+}
+
+type CreateVehicleFuncParams struct {
+	Ctx     context.Context
+	UserId  uuid.UUID
+	Vehicle vehicleModel.CreateVehicleDTO
+}
+
+type UpdateVehicleFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	VehicleId       uuid.UUID
+	Changes         vehicleModel.UpdateVehicleDTO
+	ExpectedVersion int64
+}
+
+type DeleteVehicleFuncParams struct {
+	Ctx             context.Context
+	UserId          uuid.UUID
+	VehicleId       uuid.UUID
+	ExpectedVersion int64
+}
+
+type ListVehiclesForReviewFuncParams struct {
+	Ctx    context.Context
+	Status string
+}
+
+type ReviewVehicleFuncParams struct {
+	Ctx        context.Context
+	VehicleId  uuid.UUID
+	Approve    bool
+	Reason     string
+	ReviewerId *uuid.UUID
+}
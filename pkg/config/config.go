@@ -0,0 +1,385 @@
+package config
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Package config carga la configuración de la API una sola vez al iniciar. Cada valor se
+// toma, en orden de prioridad, de un flag (-db-host), de la variable de entorno (DB_HOST),
+// del archivo de entorno o del valor por defecto. Load valida todo junto y devuelve todos
+// los problemas encontrados, para que el servidor no arranque con una configuración rota.
+
+const (
+	EnvDev  = "dev"
+	EnvTest = "test"
+	EnvProd = "prod"
+)
+
+// MinProdJWTSecretLength es el largo mínimo del secreto de JWT en producción.
+const MinProdJWTSecretLength = 32
+
+// Config es la configuración tipada de la API.
+type Config struct {
+	Env     string       `json:"env"`
+	Port    int          `json:"port"`
+	BaseURL string       `json:"base_url"`
+	Server  ServerConfig `json:"server"`
+	// EnvFile es el archivo de entorno que se leyó; vacío si no había ninguno.
+	EnvFile  string         `json:"env_file,omitempty"`
+	Database DatabaseConfig `json:"database"`
+	JWT      JWTConfig      `json:"jwt"`
+	Storage  StorageConfig  `json:"storage"`
+	// ProfileCompletenessWeights se interpreta con profileModel.ParseCompletenessWeights.
+	ProfileCompletenessWeights string `json:"profile_completeness_weights,omitempty"`
+}
+
+// ServerConfig son los tiempos límite del servidor HTTP.
+type ServerConfig struct {
+	ReadHeaderTimeout time.Duration `json:"read_header_timeout"`
+	ReadTimeout       time.Duration `json:"read_timeout"`
+	WriteTimeout      time.Duration `json:"write_timeout"`
+	IdleTimeout       time.Duration `json:"idle_timeout"`
+	// ShutdownTimeout es cuánto se espera a que terminen los requests en curso y, aparte,
+	// a que se detengan los procesos de fondo al apagar el servidor.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	// DrainDelay es cuánto se sigue atendiendo con /readyz en 503 antes de dejar de aceptar
+	// conexiones, para que el balanceador saque la instancia sin cortar requests.
+	DrainDelay time.Duration `json:"drain_delay"`
+}
+
+type DatabaseConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password Secret `json:"password"`
+	Name     string `json:"name"`
+	SSLMode  string `json:"ssl_mode"`
+}
+
+type JWTConfig struct {
+	Secret Secret `json:"secret"`
+}
+
+type StorageConfig struct {
+	// Driver es "local" o "s3".
+	Driver string `json:"driver"`
+	// LocalDir y PublicURL configuran el storage local.
+	LocalDir  string `json:"local_dir"`
+	PublicURL string `json:"public_url"`
+	// PrivateDir es el directorio de los archivos que no se sirven públicamente (documentos
+	// de conductores) cuando el storage es local.
+	PrivateDir string          `json:"private_dir"`
+	S3         S3StorageConfig `json:"s3"`
+}
+
+type S3StorageConfig struct {
+	Endpoint string `json:"endpoint,omitempty"`
+	Region   string `json:"region"`
+	Bucket   string `json:"bucket,omitempty"`
+	// PrivateBucket es un bucket sin acceso público para los documentos de conductores.
+	PrivateBucket string `json:"private_bucket,omitempty"`
+	AccessKey     Secret `json:"access_key"`
+	SecretKey     Secret `json:"secret_key"`
+	PublicURL     string `json:"public_url,omitempty"`
+}
+
+// Secret es un valor sensible: al imprimirlo o serializarlo (por ejemplo al loguear la
+// configuración) se muestra "[REDACTED]". Reveal devuelve el valor real.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Reveal devuelve el valor real del secreto.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// setting es un valor configurable: su variable de entorno, el valor por defecto y la ayuda
+// del flag. El flag se llama como la variable en minúsculas y con guiones.
+type setting struct {
+	key      string
+	fallback string
+	usage    string
+}
+
+var settings = []setting{
+	{key: "APP_ENV", fallback: EnvDev, usage: "environment: dev, test or prod"},
+	{key: "PORT", fallback: "3800", usage: "HTTP port"},
+	{key: "APP_BASE_URL", usage: "public URL of the API used in emailed links (default http://localhost:<port>)"},
+	{key: "HTTP_READ_HEADER_TIMEOUT", fallback: "10s", usage: "maximum time to read the request headers"},
+	{key: "HTTP_READ_TIMEOUT", fallback: "60s", usage: "maximum time to read a whole request, body included"},
+	{key: "HTTP_WRITE_TIMEOUT", fallback: "60s", usage: "maximum time to write a response"},
+	{key: "HTTP_IDLE_TIMEOUT", fallback: "120s", usage: "maximum time an idle keep-alive connection stays open"},
+	{key: "SHUTDOWN_TIMEOUT", fallback: "20s", usage: "maximum time to drain in-flight requests on shutdown"},
+	{key: "SHUTDOWN_DRAIN_DELAY", fallback: "5s", usage: "time /readyz fails before the server stops accepting connections on shutdown (0 to skip)"},
+	{key: "DB_HOST", fallback: "localhost", usage: "Postgres host"},
+	{key: "DB_PORT", fallback: "5432", usage: "Postgres port"},
+	{key: "DB_USER", usage: "Postgres user"},
+	{key: "DB_PASSWORD", usage: "Postgres password"},
+	{key: "DB_NAME", usage: "Postgres database"},
+	{key: "DB_SSLMODE", fallback: "disable", usage: "Postgres sslmode: disable, allow, prefer, require, verify-ca or verify-full"},
+	{key: "JWT_SECRET", usage: "secret used to sign the JWTs"},
+	{key: "STORAGE_DRIVER", fallback: "local", usage: "file storage: local or s3"},
+	{key: "STORAGE_LOCAL_DIR", fallback: "./uploads", usage: "directory of the local storage"},
+	{key: "STORAGE_PUBLIC_URL", usage: "URL the local storage files are served from (default http://localhost:<port>/media)"},
+	{key: "STORAGE_PRIVATE_DIR", fallback: "./private-uploads", usage: "directory of the local storage for private files (driver documents), never served"},
+	{key: "S3_ENDPOINT", usage: "S3 compatible endpoint"},
+	{key: "S3_REGION", fallback: "us-east-1", usage: "S3 region"},
+	{key: "S3_BUCKET", usage: "S3 bucket"},
+	{key: "S3_PRIVATE_BUCKET", usage: "S3 bucket without public access for private files (driver documents)"},
+	{key: "S3_ACCESS_KEY", usage: "S3 access key"},
+	{key: "S3_SECRET_KEY", usage: "S3 secret key"},
+	{key: "S3_PUBLIC_URL", usage: "URL the S3 objects are served from (default <endpoint>/<bucket>)"},
+	{key: "PROFILE_COMPLETENESS_WEIGHTS", usage: "weights of the profile completeness items, e.g. \"avatar=20,bio=10\""},
+}
+
+// envFileKey es la variable (y el flag -env-file) con la ruta del archivo de entorno. Si no
+// se indica se usa ".<env>.env" cuando existe.
+const envFileKey = "ENV_FILE"
+
+// Load arma la configuración a partir de los flags en args (normalmente os.Args[1:]), las
+// variables de entorno, el archivo de entorno y los valores por defecto, y la valida.
+// Con -h devuelve flag.ErrHelp después de imprimir la ayuda.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("piloto-api", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flags := map[string]*string{}
+	for _, s := range settings {
+		// El default solo se usa para la ayuda: la prioridad se resuelve en lookup
+		flags[s.key] = fs.String(flagName(s.key), s.fallback, s.usage)
+	}
+	envFileFlag := fs.String(flagName(envFileKey), "", "env file to read (default .<env>.env if it exists)")
+	if err := fs.Parse(args); err != nil {
+		if stderrors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+		return nil, err
+	}
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	// El archivo depende del entorno, que a su vez puede venir de flag o variable
+	lookup := func(key string, file map[string]string) (string, bool) {
+		if explicit[flagName(key)] {
+			return *flags[key], true
+		}
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := file[key]
+		return value, ok
+	}
+
+	envFile, required := "", false
+	if explicit[flagName(envFileKey)] {
+		envFile, required = *envFileFlag, true
+	} else if value, ok := os.LookupEnv(envFileKey); ok && value != "" {
+		envFile, required = value, true
+	} else {
+		env, ok := lookup("APP_ENV", nil)
+		if !ok || env == "" {
+			env = EnvDev
+		}
+		envFile = "." + env + ".env"
+	}
+	file, err := godotenv.Read(envFile)
+	if err != nil {
+		if required || !stderrors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error reading env file %s: %w", envFile, err)
+		}
+		envFile, file = "", nil
+	}
+
+	values := map[string]string{}
+	for _, s := range settings {
+		value, ok := lookup(s.key, file)
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			value = s.fallback
+		}
+		values[s.key] = value
+	}
+	config, problems := build(values)
+	config.EnvFile = envFile
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return config, nil
+}
+
+// build convierte los valores en la configuración tipada y devuelve los problemas encontrados.
+func build(values map[string]string) (*Config, []string) {
+	var problems []string
+	port := func(key string) int {
+		value, err := strconv.Atoi(values[key])
+		if err != nil || value < 1 || value > 65535 {
+			problems = append(problems, fmt.Sprintf("%s must be a port between 1 and 65535, got %q", key, values[key]))
+		}
+		return value
+	}
+	duration := func(key string) time.Duration {
+		value, err := time.ParseDuration(values[key])
+		if err != nil || value <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be a positive duration like 30s or 2m, got %q", key, values[key]))
+		}
+		return value
+	}
+	// Igual que duration pero acepta 0, para los plazos que se pueden desactivar
+	optionalDuration := func(key string) time.Duration {
+		value, err := time.ParseDuration(values[key])
+		if err != nil || value < 0 {
+			problems = append(problems, fmt.Sprintf("%s must be a duration like 5s, or 0, got %q", key, values[key]))
+		}
+		return value
+	}
+	required := func(key string) {
+		if values[key] == "" {
+			problems = append(problems, key+" is required")
+		}
+	}
+	absoluteURL := func(key string) {
+		if values[key] == "" {
+			return
+		}
+		parsed, err := url.Parse(values[key])
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s must be an absolute http(s) URL, got %q", key, values[key]))
+		}
+	}
+	oneOf := func(key string, allowed ...string) {
+		for _, value := range allowed {
+			if values[key] == value {
+				return
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), values[key]))
+	}
+
+	config := &Config{
+		Env:  values["APP_ENV"],
+		Port: port("PORT"),
+		Server: ServerConfig{
+			ReadHeaderTimeout: duration("HTTP_READ_HEADER_TIMEOUT"),
+			ReadTimeout:       duration("HTTP_READ_TIMEOUT"),
+			WriteTimeout:      duration("HTTP_WRITE_TIMEOUT"),
+			IdleTimeout:       duration("HTTP_IDLE_TIMEOUT"),
+			ShutdownTimeout:   duration("SHUTDOWN_TIMEOUT"),
+			DrainDelay:        optionalDuration("SHUTDOWN_DRAIN_DELAY"),
+		},
+		Database: DatabaseConfig{
+			Host:     values["DB_HOST"],
+			Port:     port("DB_PORT"),
+			User:     values["DB_USER"],
+			Password: Secret(values["DB_PASSWORD"]),
+			Name:     values["DB_NAME"],
+			SSLMode:  values["DB_SSLMODE"],
+		},
+		JWT: JWTConfig{
+			Secret: Secret(values["JWT_SECRET"]),
+		},
+		Storage: StorageConfig{
+			Driver:     values["STORAGE_DRIVER"],
+			LocalDir:   values["STORAGE_LOCAL_DIR"],
+			PublicURL:  values["STORAGE_PUBLIC_URL"],
+			PrivateDir: values["STORAGE_PRIVATE_DIR"],
+			S3: S3StorageConfig{
+				Endpoint:      values["S3_ENDPOINT"],
+				Region:        values["S3_REGION"],
+				Bucket:        values["S3_BUCKET"],
+				PrivateBucket: values["S3_PRIVATE_BUCKET"],
+				AccessKey:     Secret(values["S3_ACCESS_KEY"]),
+				SecretKey:     Secret(values["S3_SECRET_KEY"]),
+				PublicURL:     values["S3_PUBLIC_URL"],
+			},
+		},
+		ProfileCompletenessWeights: values["PROFILE_COMPLETENESS_WEIGHTS"],
+	}
+	config.BaseURL = strings.TrimRight(values["APP_BASE_URL"], "/")
+	if config.BaseURL == "" {
+		config.BaseURL = fmt.Sprintf("http://localhost:%d", config.Port)
+	}
+	if config.Storage.PublicURL == "" {
+		config.Storage.PublicURL = fmt.Sprintf("http://localhost:%d/media", config.Port)
+	}
+
+	oneOf("APP_ENV", EnvDev, EnvTest, EnvProd)
+	absoluteURL("APP_BASE_URL")
+	required("DB_HOST")
+	required("DB_USER")
+	required("DB_NAME")
+	oneOf("DB_SSLMODE", "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	required("JWT_SECRET")
+	if config.Env == EnvProd {
+		required("DB_PASSWORD")
+		if secret := values["JWT_SECRET"]; secret != "" && len(secret) < MinProdJWTSecretLength {
+			problems = append(problems, fmt.Sprintf("JWT_SECRET must have at least %d characters in prod", MinProdJWTSecretLength))
+		}
+	}
+	oneOf("STORAGE_DRIVER", "local", "s3")
+	if config.Storage.Driver == "s3" {
+		required("S3_ENDPOINT")
+		required("S3_BUCKET")
+		required("S3_PRIVATE_BUCKET")
+		if values["S3_PRIVATE_BUCKET"] != "" && values["S3_PRIVATE_BUCKET"] == values["S3_BUCKET"] {
+			problems = append(problems, "S3_PRIVATE_BUCKET must be different from S3_BUCKET")
+		}
+		required("S3_ACCESS_KEY")
+		required("S3_SECRET_KEY")
+		absoluteURL("S3_ENDPOINT")
+		absoluteURL("S3_PUBLIC_URL")
+	} else {
+		absoluteURL("STORAGE_PUBLIC_URL")
+		required("STORAGE_PRIVATE_DIR")
+		if within(values["STORAGE_PRIVATE_DIR"], values["STORAGE_LOCAL_DIR"]) {
+			problems = append(problems, "STORAGE_PRIVATE_DIR must not be inside STORAGE_LOCAL_DIR, which is served publicly")
+		}
+	}
+	return config, problems
+}
+
+// within indica si el directorio dir está dentro de parent (o es el mismo).
+func within(dir, parent string) bool {
+	if dir == "" || parent == "" {
+		return false
+	}
+	dirAbs, errDir := filepath.Abs(dir)
+	parentAbs, errParent := filepath.Abs(parent)
+	if errDir != nil || errParent != nil {
+		return false
+	}
+	rel, err := filepath.Rel(parentAbs, dirAbs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// flagName convierte el nombre de una variable en el de su flag: DB_HOST -> db-host.
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
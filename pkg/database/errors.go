@@ -0,0 +1,17 @@
+package database
+
+import (
+	stderrors "errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation es el SQLSTATE de Postgres para una violación de índice único.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation indica si err es una violación de un índice único, por ejemplo dos
+// requests concurrentes que reclaman el mismo valor.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return stderrors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -1,30 +1,67 @@
 package database
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
-	"os"
+
+	"github.com/aragornz325/piloto-api/pkg/config"
+	"github.com/aragornz325/piloto-api/pkg/history"
 	"github.com/aragornz325/piloto-api/pkg/logger"
-	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 var DB *gorm.DB
 
-func Init() {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
+// Init abre la conexión con la DB. Devuelve un error si no se puede conectar, para que el
+// servidor no arranque con DB en nil.
+func Init(cfg config.DatabaseConfig) error {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password.Reveal(),
+		cfg.Name,
+		cfg.SSLMode,
 	)
 
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
-		logger.Log.Error("💥 Error al conectar con la DB", zap.Error(err))
+		return fmt.Errorf("error connecting to the database: %w", err)
+	}
+	if err := history.RegisterCallbacks(conn); err != nil {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+		return fmt.Errorf("error registering history callbacks: %w", err)
 	}
+	DB = conn
 
 	logger.Log.Info("✅ Conexión con la DB establecida")
+	return nil
+}
+
+// Ping chequea que la DB responda.
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return stderrors.New("database not initialized")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close cierra el pool de conexiones de la DB.
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
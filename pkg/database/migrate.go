@@ -1,19 +1,237 @@
 package database
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/aragornz325/piloto-api/internal/address/model"
+	"github.com/aragornz325/piloto-api/internal/availability/model"
+	"github.com/aragornz325/piloto-api/internal/compliance/model"
+	"github.com/aragornz325/piloto-api/internal/driver/model"
+	"github.com/aragornz325/piloto-api/internal/invitation/model"
+	"github.com/aragornz325/piloto-api/internal/location/model"
 	"github.com/aragornz325/piloto-api/internal/profile/model"
+	"github.com/aragornz325/piloto-api/internal/rating/model"
+	"github.com/aragornz325/piloto-api/internal/trip/model"
 	"github.com/aragornz325/piloto-api/internal/user/model"
+	"github.com/aragornz325/piloto-api/internal/vehicle/model"
+	"github.com/aragornz325/piloto-api/pkg/history"
 	"github.com/aragornz325/piloto-api/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// models son las tablas que crea AutoMigrate; CheckMigrations verifica que existan.
+var models = []any{
+	&userModel.User{},
+	&userModel.EmailChangeRequest{},
+	&userModel.UserStatusTransition{},
+	&profileModel.Profile{},
+	&profileModel.PhoneVerification{},
+	&profileModel.HandleRedirect{},
+	&history.EntityHistory{},
+	&invitationModel.Invitation{},
+	&addressModel.Address{},
+	&driverModel.Driver{},
+	&driverModel.DriverDocument{},
+	&vehicleModel.Vehicle{},
+	&availabilityModel.AvailabilityWindow{},
+	&availabilityModel.AvailabilityException{},
+	&locationModel.DriverLocation{},
+	&tripModel.Trip{},
+	&tripModel.TripOffer{},
+	&tripModel.TripTransition{},
+	&ratingModel.Rating{},
+	&complianceModel.ExpiryReminder{},
+	&schemaMigration{},
+}
+
+// schemaMigration registra las migraciones de datos que corren una sola vez (ver runOnce).
+type schemaMigration struct {
+	Name      string    `gorm:"primaryKey"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrated se marca cuando ExecuteMigrations terminó bien.
+var migrated atomic.Bool
+
 func ExecuteMigrations() {
 	// Migrate the schema
 	logger.Log.Info("Migrating database...")
-	if err := DB.AutoMigrate(
-		&userModel.User{},
-		&profileModel.Profile{},
-	); err != nil {
+	if err := DB.AutoMigrate(models...); err != nil {
 		panic("failed to migrate database: " + err.Error())
 	}
+	if err := runOnce("profile_addresses", migrateProfileAddresses); err != nil {
+		panic("failed to migrate profile addresses: " + err.Error())
+	}
+	if err := migrateDriverFlags(); err != nil {
+		panic("failed to migrate driver flags: " + err.Error())
+	}
+	if err := migrateTripIndexes(); err != nil {
+		panic("failed to migrate trip indexes: " + err.Error())
+	}
+	if err := migrateInvitationIndexes(); err != nil {
+		panic("failed to migrate invitation indexes: " + err.Error())
+	}
+	if err := migrateEntityHistory(); err != nil {
+		panic("failed to migrate entity history: " + err.Error())
+	}
+	migrated.Store(true)
 	logger.Log.Info("Database migrated successfully")
-}
\ No newline at end of file
+}
+
+// CheckMigrations chequea que las migraciones hayan corrido en este proceso y que las tablas
+// de todos los modelos sigan existiendo.
+func CheckMigrations(ctx context.Context) error {
+	if !migrated.Load() {
+		return fmt.Errorf("migrations not applied")
+	}
+	tables := make([]string, len(models))
+	for i, model := range models {
+		stmt := &gorm.Statement{DB: DB}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("error parsing model: %w", err)
+		}
+		tables[i] = stmt.Schema.Table
+	}
+	var existing []string
+	if err := DB.WithContext(ctx).
+		Table("information_schema.tables").
+		Where("table_schema = CURRENT_SCHEMA() AND table_name IN ?", tables).
+		Pluck("table_name", &existing).Error; err != nil {
+		return fmt.Errorf("error listing tables: %w", err)
+	}
+	for _, table := range tables {
+		if !slices.Contains(existing, table) {
+			return fmt.Errorf("table %s is missing", table)
+		}
+	}
+	return nil
+}
+
+// runOnce corre migrate en una transacción si todavía no hay un registro con name en
+// schema_migrations, y lo registra al terminar. Es para migraciones de datos que no se
+// pueden repetir en cada arranque.
+func runOnce(name string, migrate func(tx *gorm.DB) error) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var applied int64
+		if err := tx.Model(&schemaMigration{}).Where("name = ?", name).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			return nil
+		}
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Name: name, AppliedAt: time.Now().UTC()}).Error
+	})
+}
+
+// migrateProfileAddresses copia la dirección de cada perfil como dirección "home" por
+// defecto de los usuarios que todavía no tienen direcciones. Corre una sola vez: después
+// la ubicación del perfil es su dirección por defecto y las columnas de dirección de
+// profiles ya no se leen ni se escriben. En las bases creadas sin esas columnas no hace nada.
+func migrateProfileAddresses(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("profiles", "city") {
+		return nil
+	}
+	filled := func(column string) string {
+		return "LOWER(TRIM(COALESCE(p." + column + ", ''))) NOT IN ('', 'no information')"
+	}
+	value := func(column string) string {
+		return "CASE WHEN " + filled(column) + " THEN TRIM(p." + column + ") ELSE '' END"
+	}
+	result := tx.Exec(`
+		INSERT INTO addresses (id, created_at, updated_at, is_active, version, user_id, type, label,
+			street, city, state, zip_code, country, latitude, longitude, geocoded_at, is_default)
+		SELECT gen_random_uuid(), NOW(), NOW(), true, 1, p.user_id, 'home', 'home',
+			` + value("street") + `, ` + value("city") + `, ` + value("state") + `,
+			` + value("zip_code") + `, ` + value("country") + `,
+			p.latitude, p.longitude, p.geocoded_at, true
+		FROM profiles p
+		WHERE p.deleted_at IS NULL
+			AND ` + filled("city") + `
+			AND NOT EXISTS (SELECT 1 FROM addresses a WHERE a.user_id = p.user_id)`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.Log.Info("Profile addresses migrated", zap.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
+
+// migrateDriverFlags quita User.Driver a los usuarios que lo marcaron al registrarse pero no
+// tienen un alta de conductor aprobada. Es idempotente.
+func migrateDriverFlags() error {
+	result := DB.Exec(`
+		UPDATE users SET driver = false, version = version + 1, updated_at = NOW()
+		WHERE driver = true
+			AND NOT EXISTS (
+				SELECT 1 FROM drivers d
+				WHERE d.user_id = users.id AND d.status = 'approved' AND d.deleted_at IS NULL
+			)`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.Log.Info("Unapproved driver flags cleared", zap.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
+
+// migrateTripIndexes crea los índices únicos parciales de viajes que no se pueden declarar
+// con tags de gorm: un conductor y un pasajero tienen a lo sumo un viaje activo. Son los
+// que hacen segura la asignación cuando dos conductores aceptan a la vez.
+func migrateTripIndexes() error {
+	if err := DB.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_trips_driver_active ON trips (driver_id)
+		WHERE status IN ('accepted', 'en_route', 'in_progress') AND deleted_at IS NULL`).Error; err != nil {
+		return err
+	}
+	return DB.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_trips_rider_active ON trips (rider_id)
+		WHERE status NOT IN ('completed', 'cancelled') AND deleted_at IS NULL`).Error
+}
+
+// migrateInvitationIndexes crea el índice único de invitaciones pendientes por email. Antes
+// marca como vencidas las pendientes que ya vencieron y, si un email tiene varias vigentes,
+// todas menos la más nueva, para que el índice se pueda crear. Es idempotente.
+func migrateInvitationIndexes() error {
+	if err := DB.Exec(`
+		UPDATE invitations SET status = 'expired'
+		WHERE status = 'pending' AND deleted_at IS NULL AND (
+			expires_at <= now() OR id NOT IN (
+				SELECT DISTINCT ON (lower(email)) id FROM invitations
+				WHERE status = 'pending' AND deleted_at IS NULL
+				ORDER BY lower(email), created_at DESC
+			)
+		)`).Error; err != nil {
+		return err
+	}
+	return DB.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_pending_email ON invitations (lower(email))
+		WHERE status = 'pending' AND deleted_at IS NULL`).Error
+}
+
+// migrateEntityHistory borra el historial de las entidades que ya no se auditan: cuando se
+// auditaban todas quedaron copiados hashes de tokens y de códigos de verificación. Es
+// idempotente.
+func migrateEntityHistory() error {
+	result := DB.Exec(`DELETE FROM entity_history WHERE entity_type NOT IN ('User', 'Profile')`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.Log.Info("Untracked entity history removed", zap.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"github.com/aragornz325/piloto-api/pkg/logger"
@@ -26,6 +27,13 @@ func NewBadRequest(opts ErrorFuncOptions ) *HttpError {
 	return &HttpError{Code: http.StatusBadRequest, Message: opts.Message, Err: opts.Err}
 }
 
+// NewValidation returns a 400 error that carries the validation message of each invalid
+// field, keyed by the field name used in the request body.
+func NewValidation(opts ValidationErrorFuncOptions) *HttpError {
+	logger.Log.Error("Validation failed", zap.String("message", opts.Message), zap.Any("fields", opts.Fields))
+	return &HttpError{Code: http.StatusBadRequest, Message: opts.Message, Fields: opts.Fields}
+}
+
 func NewNotFound(opts SimpleErrorFuncOptions) *HttpError {
 	logger.Log.Error("Not found", zap.String("message", opts.Message))
 	return &HttpError{Code: http.StatusNotFound, Message: opts.Message}
@@ -46,6 +54,50 @@ func NewForbidden(opts ErrorFuncOptions) *HttpError {
 	return &HttpError{Code: http.StatusForbidden, Message: opts.Message, Err: opts.Err}
 }
 
+func NewPreconditionFailed(opts SimpleErrorFuncOptions) *HttpError {
+	logger.Log.Error("Precondition failed", zap.String("message", opts.Message))
+	return &HttpError{Code: http.StatusPreconditionFailed, Message: opts.Message}
+}
+
+func NewPreconditionRequired(opts SimpleErrorFuncOptions) *HttpError {
+	logger.Log.Error("Precondition required", zap.String("message", opts.Message))
+	return &HttpError{Code: http.StatusPreconditionRequired, Message: opts.Message}
+}
+
+func NewConflict(opts SimpleErrorFuncOptions) *HttpError {
+	logger.Log.Error("Conflict", zap.String("message", opts.Message))
+	return &HttpError{Code: http.StatusConflict, Message: opts.Message}
+}
+
+func NewGone(opts SimpleErrorFuncOptions) *HttpError {
+	logger.Log.Error("Gone", zap.String("message", opts.Message))
+	return &HttpError{Code: http.StatusGone, Message: opts.Message}
+}
+
+func NewTooManyRequests(opts SimpleErrorFuncOptions) *HttpError {
+	logger.Log.Error("Too many requests", zap.String("message", opts.Message))
+	return &HttpError{Code: http.StatusTooManyRequests, Message: opts.Message}
+}
+
+// StatusCode returns the HTTP status carried by the first HttpError found in the
+// error chain, or fallback when err does not wrap an HttpError.
+func StatusCode(err error, fallback int) int {
+	var httpErr *HttpError
+	if stderrors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return fallback
+}
+
+// FieldErrors returns the field-level validation messages carried by the first HttpError
+// found in the error chain, or nil when there are none.
+func FieldErrors(err error) map[string]string {
+	var httpErr *HttpError
+	if stderrors.As(err, &httpErr) && len(httpErr.Fields) > 0 {
+		return httpErr.Fields
+	}
+	return nil
+}
 
 //-------structs--------///
 
@@ -58,8 +110,14 @@ type SimpleErrorFuncOptions struct {
 	Message string
 }
 
+type ValidationErrorFuncOptions struct {
+	Message string
+	Fields  map[string]string
+}
+
 type HttpError struct {
-	Code    int    // Código HTTP
-	Message string // Mensaje amigable
-	Err     error  // Error original (opcional)
+	Code    int               // Código HTTP
+	Message string            // Mensaje amigable
+	Err     error             // Error original (opcional)
+	Fields  map[string]string // Errores de validación por campo (opcional)
 }
\ No newline at end of file
@@ -0,0 +1,175 @@
+package geo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Address es una dirección postal normalizada. Country siempre es un código ISO 3166-1 alpha-2.
+type Address struct {
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	ZipCode string `json:"zip_code"`
+	Country string `json:"country"`
+}
+
+// IsEmpty indica si la dirección no tiene ningún dato.
+func (a Address) IsEmpty() bool {
+	return a.Street == "" && a.City == "" && a.State == "" && a.ZipCode == "" && a.Country == ""
+}
+
+// emptyPlaceholder es el valor que CopyNonNilFields asigna a los strings ausentes.
+const emptyPlaceholder = "no information"
+
+const maxAddressFieldLength = 120
+
+var zipCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`)
+
+// countries mapea códigos ISO 3166-1 alpha-2 a los nombres (en español e inglés) que se
+// aceptan como entrada. Los nombres se comparan sin acentos ni mayúsculas.
+var countries = map[string][]string{
+	"AR": {"argentina", "republica argentina"},
+	"BO": {"bolivia"},
+	"BR": {"brasil", "brazil"},
+	"CL": {"chile"},
+	"CO": {"colombia"},
+	"CR": {"costa rica"},
+	"CU": {"cuba"},
+	"DE": {"alemania", "germany"},
+	"DO": {"republica dominicana", "dominican republic"},
+	"EC": {"ecuador"},
+	"ES": {"espana", "spain"},
+	"FR": {"francia", "france"},
+	"GB": {"reino unido", "united kingdom", "uk", "inglaterra", "england"},
+	"GT": {"guatemala"},
+	"HN": {"honduras"},
+	"IT": {"italia", "italy"},
+	"MX": {"mexico"},
+	"NI": {"nicaragua"},
+	"PA": {"panama"},
+	"PE": {"peru"},
+	"PT": {"portugal"},
+	"PY": {"paraguay"},
+	"SV": {"el salvador"},
+	"US": {"estados unidos", "united states", "usa", "eeuu", "ee uu"},
+	"UY": {"uruguay"},
+	"VE": {"venezuela"},
+}
+
+var countryByName = func() map[string]string {
+	index := make(map[string]string)
+	for code, names := range countries {
+		index[strings.ToLower(code)] = code
+		for _, name := range names {
+			index[name] = code
+		}
+	}
+	return index
+}()
+
+// NormalizeCountry devuelve el código ISO alpha-2 de un país dado por código o nombre.
+func NormalizeCountry(value string) (string, bool) {
+	code, ok := countryByName[foldKey(value)]
+	return code, ok
+}
+
+// NormalizeAddress limpia los campos de la dirección (espacios, placeholders, mayúsculas
+// del código postal), resuelve el país a su código ISO y valida el resultado.
+// Devuelve un *ValidationError con el detalle por campo si algo no es válido.
+func NormalizeAddress(address Address) (Address, error) {
+	normalized := Address{
+		Street:  cleanField(address.Street),
+		City:    cleanField(address.City),
+		State:   cleanField(address.State),
+		ZipCode: strings.ToUpper(cleanField(address.ZipCode)),
+		Country: cleanField(address.Country),
+	}
+
+	fields := map[string]string{}
+	for name, value := range map[string]string{
+		"street": normalized.Street,
+		"city":   normalized.City,
+		"state":  normalized.State,
+	} {
+		if len(value) > maxAddressFieldLength {
+			fields[name] = fmt.Sprintf("must be at most %d characters", maxAddressFieldLength)
+		}
+	}
+	if normalized.ZipCode != "" && !zipCodePattern.MatchString(normalized.ZipCode) {
+		fields["zip_code"] = "must be 2 to 10 letters, digits, spaces or dashes"
+	}
+	if normalized.Country != "" {
+		code, ok := NormalizeCountry(normalized.Country)
+		if !ok {
+			fields["country"] = "unknown country, use an ISO 3166-1 alpha-2 code"
+		} else {
+			normalized.Country = code
+		}
+	}
+	if normalized.City != "" && normalized.Country == "" {
+		fields["country"] = "is required when city is set"
+	}
+
+	if len(fields) > 0 {
+		return normalized, &ValidationError{Fields: fields}
+	}
+	return normalized, nil
+}
+
+// ValidationError describe los campos inválidos de una dirección.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, name := range []string{"street", "city", "state", "zip_code", "country"} {
+		if msg, ok := e.Fields[name]; ok {
+			parts = append(parts, name+" "+msg)
+		}
+	}
+	return "invalid address: " + strings.Join(parts, "; ")
+}
+
+// IsBlank indica si un campo de dirección está vacío o tiene el placeholder de campo ausente.
+func IsBlank(value string) bool {
+	return cleanField(value) == ""
+}
+
+// cleanField recorta y colapsa espacios, y descarta el placeholder de campos ausentes.
+func cleanField(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if strings.EqualFold(value, emptyPlaceholder) {
+		return ""
+	}
+	return value
+}
+
+// foldKey normaliza un texto para compararlo: minúsculas, sin acentos y sin puntuación.
+func foldKey(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if folded, ok := accentFolding[r]; ok {
+			r = folded
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '.' || r == ',':
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+var accentFolding = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
@@ -0,0 +1,73 @@
+package geo
+
+import (
+	"context"
+	"math"
+)
+
+// EarthRadiusKm es el radio medio de la Tierra usado en los cálculos de distancia.
+const EarthRadiusKm = 6371.0
+
+// Geocoder resuelve una dirección normalizada a coordenadas. Devuelve (nil, nil) si la
+// dirección no se pudo ubicar; un error solo indica una falla del proveedor.
+// Las implementaciones son intercambiables (dataset local, servicio externo...).
+type Geocoder interface {
+	Geocode(GeocodeFuncParams) (*Coordinates, error)
+}
+
+// Coordinates es un punto en grados decimales (WGS84).
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Valid indica si las coordenadas están dentro de los rangos permitidos.
+func (c Coordinates) Valid() bool {
+	return c.Latitude >= -90 && c.Latitude <= 90 && c.Longitude >= -180 && c.Longitude <= 180
+}
+
+// DistanceKm calcula la distancia sobre la superficie terrestre entre dos puntos (haversine).
+func DistanceKm(a, b Coordinates) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLng := radians(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BoundingBox devuelve el rectángulo de latitud/longitud que contiene el círculo de
+// radiusKm alrededor de center. Sirve para prefiltrar en la base antes de medir la distancia.
+func BoundingBox(center Coordinates, radiusKm float64) (min Coordinates, max Coordinates) {
+	dLat := degrees(radiusKm / EarthRadiusKm)
+	min.Latitude = math.Max(-90, center.Latitude-dLat)
+	max.Latitude = math.Min(90, center.Latitude+dLat)
+
+	// Cerca de los polos el círculo cubre todas las longitudes
+	cosLat := math.Cos(radians(center.Latitude))
+	if max.Latitude >= 90 || min.Latitude <= -90 || cosLat < 1e-9 {
+		min.Longitude, max.Longitude = -180, 180
+		return min, max
+	}
+	dLng := degrees(radiusKm / (EarthRadiusKm * cosLat))
+	min.Longitude = center.Longitude - dLng
+	max.Longitude = center.Longitude + dLng
+	// Si cruza el antimeridiano no se filtra por longitud; la distancia exacta descarta el resto
+	if min.Longitude < -180 || max.Longitude > 180 {
+		min.Longitude, max.Longitude = -180, 180
+	}
+	return min, max
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// /-------------structs------------------///
+type GeocodeFuncParams struct {
+	Ctx     context.Context
+	Address Address
+}
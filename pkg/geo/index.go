@@ -0,0 +1,260 @@
+package geo
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// indexShards es la cantidad de particiones con lock propio; reparte la contención entre
+// muchos escritores concurrentes.
+const indexShards = 64
+
+// Position es la última posición conocida de una entidad en un Index.
+type Position struct {
+	Id          uuid.UUID   `json:"id"`
+	Coordinates Coordinates `json:"coordinates"`
+	// Heading es el rumbo en grados (0 = norte) y Speed la velocidad en km/h; nil si el
+	// dispositivo no los informa.
+	Heading    *float64  `json:"heading,omitempty"`
+	Speed      *float64  `json:"speed,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// NearbyPosition es una posición encontrada por Index.Nearby con su distancia al centro.
+type NearbyPosition struct {
+	Position
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// Index es un índice espacial en memoria de la última posición de cada entidad, seguro para
+// escrituras y lecturas concurrentes. Divide el mapa en celdas de cellSize grados: las
+// búsquedas solo recorren las celdas del bounding box del radio pedido.
+//
+// Hay dos juegos de particiones: por ID (dueño de la posición, serializa las escrituras de
+// una misma entidad) y por celda (qué IDs hay en cada celda). Una escritura toma el lock
+// de su ID y, de a uno, los de las celdas que toca, así que nunca espera dos locks a la vez.
+type Index struct {
+	cellSize float64
+	owners   [indexShards]ownerShard
+	cells    [indexShards]cellShard
+}
+
+type cellKey struct {
+	lat, lng int
+}
+
+type ownerEntry struct {
+	position Position
+	cell     cellKey
+	dirty    bool
+}
+
+type ownerShard struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]*ownerEntry
+}
+
+type cellShard struct {
+	mu    sync.RWMutex
+	cells map[cellKey]map[uuid.UUID]Position
+}
+
+// NewIndex crea un índice con celdas de cellSizeDeg grados (por ejemplo 0.05, unos 5 km).
+func NewIndex(cellSizeDeg float64) *Index {
+	index := &Index{cellSize: cellSizeDeg}
+	for i := range index.owners {
+		index.owners[i].entries = map[uuid.UUID]*ownerEntry{}
+		index.cells[i].cells = map[cellKey]map[uuid.UUID]Position{}
+	}
+	return index
+}
+
+// Update guarda la posición si es más nueva que la conocida. Devuelve false si se descartó
+// por ser anterior a la guardada (por ejemplo, mensajes que llegan desordenados).
+func (x *Index) Update(position Position) bool {
+	owner := &x.owners[shardOf(position.Id)]
+	owner.mu.Lock()
+	defer owner.mu.Unlock()
+
+	current, ok := owner.entries[position.Id]
+	if ok && position.RecordedAt.Before(current.position.RecordedAt) {
+		return false
+	}
+	cell := x.cellOf(position.Coordinates)
+	if ok && current.cell != cell {
+		x.removeFromCell(current.cell, position.Id)
+	}
+	x.addToCell(cell, position)
+	owner.entries[position.Id] = &ownerEntry{position: position, cell: cell, dirty: true}
+	return true
+}
+
+// Restore carga una posición ya persistida: no la marca como pendiente de guardar y no pisa
+// una posición más nueva.
+func (x *Index) Restore(position Position) {
+	owner := &x.owners[shardOf(position.Id)]
+	owner.mu.Lock()
+	defer owner.mu.Unlock()
+
+	if _, ok := owner.entries[position.Id]; ok {
+		return
+	}
+	cell := x.cellOf(position.Coordinates)
+	x.addToCell(cell, position)
+	owner.entries[position.Id] = &ownerEntry{position: position, cell: cell}
+}
+
+// Remove saca una entidad del índice.
+func (x *Index) Remove(id uuid.UUID) {
+	owner := &x.owners[shardOf(id)]
+	owner.mu.Lock()
+	defer owner.mu.Unlock()
+
+	if current, ok := owner.entries[id]; ok {
+		x.removeFromCell(current.cell, id)
+		delete(owner.entries, id)
+	}
+}
+
+// Get devuelve la última posición conocida de una entidad.
+func (x *Index) Get(id uuid.UUID) (Position, bool) {
+	owner := &x.owners[shardOf(id)]
+	owner.mu.Lock()
+	defer owner.mu.Unlock()
+
+	if current, ok := owner.entries[id]; ok {
+		return current.position, true
+	}
+	return Position{}, false
+}
+
+// Nearby devuelve las posiciones a menos de radiusKm de center registradas después de
+// since, de la más cercana a la más lejana. keep, si no es nil, filtra las entidades.
+// Una entidad que se mueve de celda durante la búsqueda puede verse en las dos: queda la
+// posición más nueva.
+func (x *Index) Nearby(center Coordinates, radiusKm float64, since time.Time, keep func(uuid.UUID) bool) []NearbyPosition {
+	low, high := BoundingBox(center, radiusKm)
+	minCell, maxCell := x.cellOf(low), x.cellOf(high)
+
+	found := []NearbyPosition{}
+	seen := map[uuid.UUID]int{}
+	for lat := minCell.lat; lat <= maxCell.lat; lat++ {
+		for lng := minCell.lng; lng <= maxCell.lng; lng++ {
+			key := cellKey{lat: lat, lng: lng}
+			shard := &x.cells[cellShardOf(key)]
+			shard.mu.RLock()
+			for id, position := range shard.cells[key] {
+				if position.RecordedAt.Before(since) || (keep != nil && !keep(id)) {
+					continue
+				}
+				distance := DistanceKm(center, position.Coordinates)
+				if distance > radiusKm {
+					continue
+				}
+				if i, ok := seen[id]; ok {
+					if position.RecordedAt.After(found[i].RecordedAt) {
+						found[i] = NearbyPosition{Position: position, DistanceKm: distance}
+					}
+					continue
+				}
+				seen[id] = len(found)
+				found = append(found, NearbyPosition{Position: position, DistanceKm: distance})
+			}
+			shard.mu.RUnlock()
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].DistanceKm < found[j].DistanceKm
+	})
+	return found
+}
+
+// Drain devuelve las posiciones que cambiaron desde el último Drain y las marca como
+// guardadas. Si la persistencia falla, se pueden volver a marcar con MarkDirty.
+func (x *Index) Drain() []Position {
+	var changed []Position
+	for i := range x.owners {
+		owner := &x.owners[i]
+		owner.mu.Lock()
+		for _, entry := range owner.entries {
+			if entry.dirty {
+				changed = append(changed, entry.position)
+				entry.dirty = false
+			}
+		}
+		owner.mu.Unlock()
+	}
+	return changed
+}
+
+// MarkDirty vuelve a marcar como pendientes de guardar las posiciones indicadas, salvo que
+// ya hayan sido reemplazadas por otras más nuevas (que ya están pendientes).
+func (x *Index) MarkDirty(positions []Position) {
+	for _, position := range positions {
+		owner := &x.owners[shardOf(position.Id)]
+		owner.mu.Lock()
+		if entry, ok := owner.entries[position.Id]; ok && entry.position.RecordedAt.Equal(position.RecordedAt) {
+			entry.dirty = true
+		}
+		owner.mu.Unlock()
+	}
+}
+
+// Prune saca del índice las posiciones registradas antes de before y devuelve cuántas quitó.
+func (x *Index) Prune(before time.Time) int {
+	removed := 0
+	for i := range x.owners {
+		owner := &x.owners[i]
+		owner.mu.Lock()
+		for id, entry := range owner.entries {
+			if entry.position.RecordedAt.Before(before) && !entry.dirty {
+				x.removeFromCell(entry.cell, id)
+				delete(owner.entries, id)
+				removed++
+			}
+		}
+		owner.mu.Unlock()
+	}
+	return removed
+}
+
+func (x *Index) addToCell(key cellKey, position Position) {
+	shard := &x.cells[cellShardOf(key)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if shard.cells[key] == nil {
+		shard.cells[key] = map[uuid.UUID]Position{}
+	}
+	shard.cells[key][position.Id] = position
+}
+
+func (x *Index) removeFromCell(key cellKey, id uuid.UUID) {
+	shard := &x.cells[cellShardOf(key)]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	delete(shard.cells[key], id)
+	if len(shard.cells[key]) == 0 {
+		delete(shard.cells, key)
+	}
+}
+
+func (x *Index) cellOf(c Coordinates) cellKey {
+	return cellKey{
+		lat: int(math.Floor(c.Latitude / x.cellSize)),
+		lng: int(math.Floor(c.Longitude / x.cellSize)),
+	}
+}
+
+func shardOf(id uuid.UUID) int {
+	// Los UUID v4 son aleatorios: el último byte alcanza para repartir
+	return int(id[15]) % indexShards
+}
+
+func cellShardOf(key cellKey) int {
+	h := uint32(key.lat)*73856093 ^ uint32(key.lng)*19349663
+	return int(h % indexShards)
+}
@@ -0,0 +1,118 @@
+package geo
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var center = Coordinates{Latitude: -34.6037, Longitude: -58.3816}
+
+func TestIndexNearby(t *testing.T) {
+	index := NewIndex(0.05)
+	now := time.Now()
+	near, far, stale := uuid.New(), uuid.New(), uuid.New()
+	index.Update(Position{Id: near, Coordinates: Coordinates{Latitude: -34.61, Longitude: -58.38}, RecordedAt: now})
+	index.Update(Position{Id: far, Coordinates: Coordinates{Latitude: -34.9, Longitude: -57.95}, RecordedAt: now})
+	index.Update(Position{Id: stale, Coordinates: center, RecordedAt: now.Add(-time.Hour)})
+
+	found := index.Nearby(center, 10, now.Add(-time.Minute), nil)
+	if len(found) != 1 || found[0].Id != near {
+		t.Fatalf("Nearby() = %+v, want only %s", found, near)
+	}
+	if found := index.Nearby(center, 10, now.Add(-time.Minute), func(uuid.UUID) bool { return false }); len(found) != 0 {
+		t.Errorf("Nearby() with keep = %+v, want none", found)
+	}
+}
+
+func TestIndexUpdateMovesBetweenCells(t *testing.T) {
+	index := NewIndex(0.05)
+	id := uuid.New()
+	now := time.Now()
+	index.Update(Position{Id: id, Coordinates: center, RecordedAt: now})
+	index.Update(Position{Id: id, Coordinates: Coordinates{Latitude: -31.4201, Longitude: -64.1888}, RecordedAt: now.Add(time.Second)})
+
+	if found := index.Nearby(center, 10, time.Time{}, nil); len(found) != 0 {
+		t.Errorf("Nearby() at the old cell = %+v, want none", found)
+	}
+	if index.Update(Position{Id: id, Coordinates: center, RecordedAt: now}) {
+		t.Error("Update() with an older position = true, want false")
+	}
+	if position, _ := index.Get(id); position.Coordinates == center {
+		t.Error("an older position replaced the newer one")
+	}
+}
+
+// Escrituras, búsquedas, drenados y bajas en paralelo; se corre con -race. Al final cada
+// entidad tiene que estar en una sola celda, con su última posición.
+func TestIndexConcurrentUpdateAndNearby(t *testing.T) {
+	index := NewIndex(0.01)
+	ids := make([]uuid.UUID, 50)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	start := time.Now()
+	const steps = 200
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for step := range steps {
+				// Cada entidad se mueve cruzando celdas alrededor del centro
+				offset := float64((i+step)%20-10) * 0.005
+				index.Update(Position{
+					Id:          id,
+					Coordinates: Coordinates{Latitude: center.Latitude + offset, Longitude: center.Longitude - offset},
+					RecordedAt:  start.Add(time.Duration(step) * time.Millisecond),
+				})
+			}
+		}()
+	}
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range steps {
+				seen := map[uuid.UUID]bool{}
+				for _, position := range index.Nearby(center, 20, time.Time{}, nil) {
+					if position.DistanceKm > 20 {
+						t.Errorf("Nearby() returned %s at %.2f km", position.Id, position.DistanceKm)
+					}
+					if seen[position.Id] {
+						t.Errorf("Nearby() returned %s twice", position.Id)
+					}
+					seen[position.Id] = true
+				}
+				index.MarkDirty(index.Drain())
+			}
+		}()
+	}
+	removed := uuid.New()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for step := range steps {
+			index.Update(Position{Id: removed, Coordinates: center, RecordedAt: start.Add(time.Duration(step) * time.Millisecond)})
+			index.Remove(removed)
+		}
+	}()
+	wg.Wait()
+
+	found := index.Nearby(center, 20, time.Time{}, nil)
+	if len(found) != len(ids) {
+		t.Fatalf("Nearby() found %d positions, want %d", len(found), len(ids))
+	}
+	last := start.Add((steps - 1) * time.Millisecond)
+	for _, position := range found {
+		if position.Id == removed {
+			t.Errorf("removed entity %s is still indexed", removed)
+		}
+		if !position.RecordedAt.Equal(last) {
+			t.Errorf("position of %s is from %s, want the last one", position.Id, position.RecordedAt)
+		}
+	}
+}
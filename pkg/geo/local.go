@@ -0,0 +1,79 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:embed data/cities.csv
+var citiesCSV string
+
+// localGeocoder resuelve direcciones a nivel ciudad usando un dataset embebido en el binario.
+// No hace llamadas de red, por lo que es apto para desarrollo, tests y como fallback.
+type localGeocoder struct {
+	// cities indexa por "país|ciudad" todas las ciudades con ese nombre (pueden repetirse entre provincias).
+	cities map[string][]cityEntry
+}
+
+type cityEntry struct {
+	state       string
+	coordinates Coordinates
+}
+
+// NewLocalGeocoder carga el dataset de ciudades embebido (data/cities.csv).
+func NewLocalGeocoder() (Geocoder, error) {
+	records, err := csv.NewReader(strings.NewReader(citiesCSV)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading cities dataset: %w", err)
+	}
+	geocoder := &localGeocoder{cities: make(map[string][]cityEntry, len(records))}
+	for i, record := range records {
+		if i == 0 {
+			continue // header
+		}
+		if len(record) != 5 {
+			return nil, fmt.Errorf("cities dataset line %d: expected 5 columns, got %d", i+1, len(record))
+		}
+		lat, errLat := strconv.ParseFloat(record[3], 64)
+		lng, errLng := strconv.ParseFloat(record[4], 64)
+		coordinates := Coordinates{Latitude: lat, Longitude: lng}
+		if errLat != nil || errLng != nil || !coordinates.Valid() {
+			return nil, fmt.Errorf("cities dataset line %d: invalid coordinates", i+1)
+		}
+		key := cityKey(record[2], record[0])
+		geocoder.cities[key] = append(geocoder.cities[key], cityEntry{
+			state:       foldKey(record[1]),
+			coordinates: coordinates,
+		})
+	}
+	return geocoder, nil
+}
+
+// Geocode busca la ciudad de la dirección dentro de su país. Si hay varias ciudades con el
+// mismo nombre se usa la provincia/estado para desambiguar; si no coincide ninguna se toma la primera.
+func (g *localGeocoder) Geocode(opts GeocodeFuncParams) (*Coordinates, error) {
+	address := opts.Address
+	if address.City == "" || address.Country == "" {
+		return nil, nil
+	}
+	entries := g.cities[cityKey(address.Country, address.City)]
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	state := foldKey(address.State)
+	for _, entry := range entries {
+		if state != "" && entry.state == state {
+			coordinates := entry.coordinates
+			return &coordinates, nil
+		}
+	}
+	coordinates := entries[0].coordinates
+	return &coordinates, nil
+}
+
+func cityKey(country, city string) string {
+	return strings.ToUpper(strings.TrimSpace(country)) + "|" + foldKey(city)
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// CheckTimeout es cuánto puede tardar cada chequeo antes de darlo por fallido.
+const CheckTimeout = 2 * time.Second
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Checker lo implementan las dependencias que saben chequearse solas (el storage, un
+// proveedor de email). Se registran solo si lo implementan.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapta una función a Checker.
+type CheckFunc func(ctx context.Context) error
+
+func (f CheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Registry tiene los chequeos de readiness. Los corre en paralelo, cada uno con su
+// CheckTimeout, y deja de estar listo al empezar el apagado.
+type Registry struct {
+	mu       sync.Mutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+type namedCheck struct {
+	name    string
+	checker Checker
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register agrega un chequeo con el nombre con el que aparece en el reporte.
+func (r *Registry) Register(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, namedCheck{name: name, checker: checker})
+}
+
+// Drain marca que el servidor se está apagando: desde ahí el reporte falla para que el
+// balanceador deje de mandar tráfico mientras terminan los requests en curso.
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Run corre todos los chequeos y arma el reporte.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := append([]namedCheck(nil), r.checks...)
+	r.mu.Unlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, check.checker)
+			mu.Lock()
+			report.Checks[check.name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for name, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+			logger.Log.Warn("⚠️ Chequeo de readiness fallido",
+				zap.String("check", name),
+				zap.Int64("duration_ms", result.DurationMs),
+				zap.String("error", result.Error),
+			)
+		}
+	}
+	if r.draining.Load() {
+		report.Status = StatusFail
+		report.Draining = true
+	}
+	return report
+}
+
+var errCheckPanicked = stderrors.New("check panicked")
+
+// run corre un chequeo con su timeout; un panic cuenta como falla.
+func run(ctx context.Context, checker Checker) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+	start := time.Now()
+	defer func() {
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	// El chequeo corre aparte para no esperar más que el timeout aunque ignore el contexto
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- errCheckPanicked
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return CheckResult{Status: StatusFail, Error: err.Error()}
+		}
+		return CheckResult{Status: StatusOK}
+	case <-ctx.Done():
+		return CheckResult{Status: StatusFail, Error: "timed out: " + ctx.Err().Error()}
+	}
+}
+
+// /-------------structs------------------///
+
+// Report es la respuesta de readiness: Status es "fail" si falló algún chequeo o el
+// servidor se está apagando.
+type Report struct {
+	Status   string                 `json:"status"`
+	Draining bool                   `json:"draining,omitempty"`
+	Checks   map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult es el resultado de un chequeo. Error no se serializa porque /readyz es
+// público y el texto puede revelar hosts o credenciales; Run lo loguea.
+type CheckResult struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"-"`
+}
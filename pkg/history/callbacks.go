@@ -0,0 +1,313 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tracked lo implementan las entidades cuyo historial se registra (User y Profile). Es
+// opt-in: las entidades con tokens o códigos (invitaciones, verificaciones) y las de mucho
+// tráfico (viajes, ofertas) no se auditan.
+type Tracked interface {
+	TrackHistory() bool
+}
+
+const (
+	beforeSnapshotKey = "history:before"
+	beforeBatchKey    = "history:before_batch"
+)
+
+// Columnas que cambian en cada escritura y no aportan al diff.
+var ignoredColumns = map[string]bool{
+	"updated_at": true,
+	"version":    true,
+}
+
+// Columnas cuyo valor nunca se guarda en el historial, además de las que cumplen redacted.
+var redactedColumns = map[string]bool{
+	"password": true,
+}
+
+// redacted indica si el valor de la columna no se guarda: las de redactedColumns y
+// cualquier hash, token o secreto.
+func redacted(column string) bool {
+	if redactedColumns[column] {
+		return true
+	}
+	for _, suffix := range []string{"_hash", "_token", "_secret"} {
+		if strings.HasSuffix(column, suffix) {
+			return true
+		}
+	}
+	return column == "token" || column == "secret"
+}
+
+// RegisterCallbacks engancha los callbacks de historial en create y update.
+// Los borrados del proyecto son soft deletes (update de deleted_at), así que se
+// registran como update y se clasifican como "delete" al calcular el diff.
+// Los updates en lote (Model(&X{}).Where(...).Updates(...)) se registran como un
+// update por cada fila alcanzada. No se registra lo que se escribe sin modelo
+// (Table("x") o SQL crudo).
+func RegisterCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("history:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("history:before_update", beforeUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Register("history:after_update", afterUpdate)
+}
+
+func afterCreate(db *gorm.DB) {
+	entityId, ok := trackedEntityId(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+	after, err := loadSnapshot(db, entityId)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	changes := diff(nil, after)
+	db.AddError(record(db, entityId, ActionCreate, changes))
+}
+
+func beforeUpdate(db *gorm.DB) {
+	entityId, ok := trackedEntityId(db)
+	if !ok {
+		beforeBatchUpdate(db)
+		return
+	}
+	before, err := loadSnapshot(db, entityId)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(beforeSnapshotKey, before)
+}
+
+// beforeBatchUpdate guarda las filas que va a alcanzar un update en lote, buscándolas con
+// el mismo WHERE.
+func beforeBatchUpdate(db *gorm.DB) {
+	where, ok := batchWhere(db)
+	if !ok {
+		return
+	}
+	var rows []map[string]interface{}
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Table(db.Statement.Schema.Table).
+		Clauses(where).
+		Find(&rows).Error; err != nil {
+		db.AddError(fmt.Errorf("error loading %s snapshots for history: %w", db.Statement.Schema.Table, err))
+		return
+	}
+	db.InstanceSet(beforeBatchKey, rows)
+}
+
+func afterUpdate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 {
+		return
+	}
+	entityId, ok := trackedEntityId(db)
+	if !ok {
+		afterBatchUpdate(db)
+		return
+	}
+	value, ok := db.InstanceGet(beforeSnapshotKey)
+	if !ok {
+		return
+	}
+	before, _ := value.(map[string]interface{})
+	after, err := loadSnapshot(db, entityId)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	recordUpdate(db, entityId, before, after)
+}
+
+// afterBatchUpdate vuelve a leer por ID las filas guardadas antes del update en lote (el
+// WHERE puede dejar de alcanzarlas) y registra las que cambiaron.
+func afterBatchUpdate(db *gorm.DB) {
+	value, ok := db.InstanceGet(beforeBatchKey)
+	if !ok {
+		return
+	}
+	rows, _ := value.([]map[string]interface{})
+	before := make(map[uuid.UUID]map[string]interface{}, len(rows))
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		if id, ok := rowId(row["id"]); ok {
+			before[id] = row
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	var afterRows []map[string]interface{}
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Table(db.Statement.Schema.Table).
+		Where("id IN ?", ids).
+		Find(&afterRows).Error; err != nil {
+		db.AddError(fmt.Errorf("error loading %s snapshots for history: %w", db.Statement.Schema.Table, err))
+		return
+	}
+	for _, after := range afterRows {
+		if id, ok := rowId(after["id"]); ok {
+			recordUpdate(db, id, before[id], after)
+		}
+	}
+}
+
+// recordUpdate registra el diff entre dos snapshots de una fila, si hubo cambios.
+func recordUpdate(db *gorm.DB, entityId uuid.UUID, before, after map[string]interface{}) {
+	changes := diff(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	action := ActionUpdate
+	if before["deleted_at"] == nil && after["deleted_at"] != nil {
+		action = ActionDelete
+	}
+	db.AddError(record(db, entityId, action, changes))
+}
+
+// tracked indica si el modelo de la operación pidió que se registre su historial.
+func tracked(db *gorm.DB) bool {
+	model, ok := db.Statement.Model.(Tracked)
+	return ok && model.TrackHistory()
+}
+
+// trackedEntityId devuelve el ID de la entidad afectada si el modelo está auditado y
+// la operación apunta a un único registro.
+func trackedEntityId(db *gorm.DB) (uuid.UUID, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return uuid.Nil, false
+	}
+	if !tracked(db) {
+		return uuid.Nil, false
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil || db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return uuid.Nil, false
+	}
+	value, zero := field.ValueOf(db.Statement.Context, db.Statement.ReflectValue)
+	if zero {
+		return uuid.Nil, false
+	}
+	entityId, ok := value.(uuid.UUID)
+	return entityId, ok
+}
+
+// batchWhere devuelve el WHERE de un update en lote sobre un modelo auditado: el modelo no
+// trae clave primaria y la condición sale de Where.
+func batchWhere(db *gorm.DB) (clause.Where, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return clause.Where{}, false
+	}
+	if !tracked(db) {
+		return clause.Where{}, false
+	}
+	c, ok := db.Statement.Clauses["WHERE"]
+	if !ok {
+		return clause.Where{}, false
+	}
+	where, ok := c.Expression.(clause.Where)
+	return where, ok && len(where.Exprs) > 0
+}
+
+// rowId lee el ID de una fila leída como mapa; según el driver llega como texto o bytes.
+func rowId(value interface{}) (uuid.UUID, bool) {
+	switch v := value.(type) {
+	case string:
+		id, err := uuid.Parse(v)
+		return id, err == nil
+	case []byte:
+		if len(v) == 16 {
+			id, err := uuid.FromBytes(v)
+			return id, err == nil
+		}
+		id, err := uuid.ParseBytes(v)
+		return id, err == nil
+	case [16]byte:
+		return uuid.UUID(v), true
+	case uuid.UUID:
+		return v, true
+	}
+	return uuid.Nil, false
+}
+
+func loadSnapshot(db *gorm.DB, entityId uuid.UUID) (map[string]interface{}, error) {
+	row := map[string]interface{}{}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table(db.Statement.Schema.Table).
+		Where("id = ?", entityId).
+		Take(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s snapshot for history: %w", db.Statement.Schema.Table, err)
+	}
+	return row, nil
+}
+
+// diff compara dos snapshots columna por columna y devuelve solo lo que cambió.
+func diff(before, after map[string]interface{}) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	for column, newValue := range after {
+		if ignoredColumns[column] {
+			continue
+		}
+		oldValue, existed := before[column]
+		oldValue, newValue = normalize(oldValue), normalize(newValue)
+		if existed && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if !existed && newValue == nil {
+			continue
+		}
+		if redacted(column) {
+			oldValue, newValue = "[REDACTED]", "[REDACTED]"
+		}
+		changes[column] = FieldChange{From: oldValue, To: newValue}
+	}
+	return changes
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC()
+	default:
+		return v
+	}
+}
+
+func record(db *gorm.DB, entityId uuid.UUID, action string, changes map[string]FieldChange) error {
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	entry := EntityHistory{
+		ID:         uuid.New(),
+		EntityType: db.Statement.Schema.Name,
+		EntityId:   entityId,
+		Action:     action,
+		Changes:    payload,
+		RequestId:  requestctx.RequestID(db.Statement.Context),
+		CreatedAt:  time.Now().UTC(),
+	}
+	if principal, ok := requestctx.PrincipalFrom(db.Statement.Context); ok {
+		entry.ActorId = &principal.UserId
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Create(&entry).Error
+}
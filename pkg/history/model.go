@@ -0,0 +1,37 @@
+package history
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// EntityHistory es una entrada del historial de cambios de una entidad.
+// No embebe BaseModel para no disparar los callbacks de historial sobre sí misma.
+type EntityHistory struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	EntityType string          `gorm:"index:idx_entity_history_entity;not null" json:"entity_type"`
+	EntityId   uuid.UUID       `gorm:"type:uuid;index:idx_entity_history_entity;not null" json:"entity_id"`
+	Action     string          `gorm:"not null" json:"action"`
+	Changes    json.RawMessage `gorm:"type:jsonb" json:"changes" swaggertype:"object"`
+	ActorId    *uuid.UUID      `gorm:"type:uuid" json:"actor_id,omitempty"`
+	RequestId  string          `json:"request_id,omitempty"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+}
+
+func (EntityHistory) TableName() string {
+	return "entity_history"
+}
+
+// FieldChange es el valor anterior y el nuevo de una columna.
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
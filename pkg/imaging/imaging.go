@@ -0,0 +1,182 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// Package imaging valida y normaliza imágenes subidas por los usuarios: re-codifica la
+// imagen (lo que descarta EXIF y cualquier otro metadato), respeta la orientación EXIF
+// original y genera versiones redimensionadas.
+
+const (
+	// MaxPixels limita el tamaño de la imagen decodificada para evitar bombas de descompresión.
+	MaxPixels   = 40_000_000
+	jpegQuality = 85
+)
+
+// AllowedContentTypes son los tipos MIME aceptados, detectados a partir del contenido.
+var AllowedContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// ProcessedImage es una imagen ya re-codificada y sin metadatos.
+type ProcessedImage struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// DetectContentType devuelve el tipo MIME real del archivo a partir de sus primeros bytes,
+// ignorando el Content-Type declarado por el cliente.
+func DetectContentType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// Process decodifica la imagen, aplica la orientación EXIF, y devuelve la imagen principal
+// limitada a opts.MaxSize de lado más una versión por cada tamaño de opts.Thumbnails.
+// Las imágenes JPEG se re-codifican como JPEG y el resto como PNG (para conservar transparencia).
+func Process(data []byte, opts ProcessFuncParams) (*ProcessedImage, map[int]*ProcessedImage, error) {
+	contentType := DetectContentType(data)
+	if !AllowedContentTypes[contentType] {
+		return nil, nil, fmt.Errorf("unsupported image type %q", contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, nil, fmt.Errorf("image dimensions %dx%d are not allowed", config.Width, config.Height)
+	}
+
+	var img image.Image
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err == nil {
+			img = applyOrientation(img, jpegOrientation(data))
+		}
+	case "image/png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		img, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid image: %w", err)
+	}
+
+	// Se convierte una sola vez y cada tamaño se reduce desde ese buffer
+	rgba := toRGBA(img)
+	main, err := encode(fit(rgba, opts.MaxSize), contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thumbnails := make(map[int]*ProcessedImage, len(opts.Thumbnails))
+	for _, size := range opts.Thumbnails {
+		thumbnail, err := encode(fit(rgba, size), contentType)
+		if err != nil {
+			return nil, nil, err
+		}
+		thumbnails[size] = thumbnail
+	}
+
+	return main, thumbnails, nil
+}
+
+func encode(img image.Image, sourceType string) (*ProcessedImage, error) {
+	var buf bytes.Buffer
+	processed := &ProcessedImage{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+	if sourceType == "image/jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("error encoding image: %w", err)
+		}
+		processed.ContentType = "image/jpeg"
+		processed.Extension = "jpg"
+	} else {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("error encoding image: %w", err)
+		}
+		processed.ContentType = "image/png"
+		processed.Extension = "png"
+	}
+	processed.Data = buf.Bytes()
+	return processed, nil
+}
+
+// fit reduce la imagen para que su lado mayor no supere maxSize, manteniendo la proporción.
+// Nunca agranda la imagen (devuelve rgba tal cual). Usa un promedio por área, que da buen
+// resultado al achicar.
+func fit(rgba *image.RGBA, maxSize int) *image.RGBA {
+	bounds := rgba.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxSize <= 0 || (width <= maxSize && height <= maxSize) {
+		return rgba
+	}
+
+	dstWidth, dstHeight := maxSize, maxSize
+	if width > height {
+		dstHeight = max(1, height*maxSize/width)
+	} else {
+		dstWidth = max(1, width*maxSize/height)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0 := y * height / dstHeight
+		y1 := max(y0+1, (y+1)*height/dstHeight)
+		for x := 0; x < dstWidth; x++ {
+			x0 := x * width / dstWidth
+			x1 := max(x0+1, (x+1)*width/dstWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(rgba.Pix[offset])
+					g += uint64(rgba.Pix[offset+1])
+					b += uint64(rgba.Pix[offset+2])
+					a += uint64(rgba.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
+
+// toRGBA convierte la imagen a RGBA con origen en (0, 0), que es lo que espera fit.
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+	return dst
+}
+
+// /-------------structs------------------///
+type ProcessFuncParams struct {
+	// MaxSize es el lado máximo de la imagen principal.
+	MaxSize int
+	// Thumbnails son los lados máximos de cada miniatura a generar.
+	Thumbnails []int
+}
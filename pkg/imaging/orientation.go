@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation lee el tag Orientation (0x0112) del bloque EXIF de un JPEG.
+// Devuelve 1 (sin rotación) si no hay EXIF o no se puede interpretar.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan / end of image: no hay más metadatos
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		if size < 2 || offset+2+size > len(data) {
+			return 1
+		}
+		segment := data[offset+4 : offset+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		offset += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// applyOrientation rota y/o espeja la imagen según el valor EXIF Orientation, para que
+// la imagen se vea igual una vez descartados los metadatos.
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 {
+		return src
+	}
+	rgba := toRGBA(src)
+	width, height := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // espejo horizontal
+				dx, dy = width-1-x, y
+			case 3: // 180°
+				dx, dy = width-1-x, height-1-y
+			case 4: // espejo vertical
+				dx, dy = x, height-1-y
+			case 5: // transpuesta
+				dx, dy = y, x
+			case 6: // 90° horario
+				dx, dy = height-1-y, x
+			case 7: // transversa
+				dx, dy = height-1-y, width-1-x
+			case 8: // 90° antihorario
+				dx, dy = y, width-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.SetRGBA(dx, dy, rgba.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,85 @@
+package lifecycle
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Lifecycle lleva los procesos de fondo y los pasos de cierre de la API para apagarla en
+// orden: primero se cancelan los procesos y se espera a que terminen (por ejemplo el último
+// guardado de posiciones) y después se ejecutan los hooks, del último registrado al primero,
+// como un defer. Así lo que se inicia primero (el logger, la base) se cierra último.
+type Lifecycle struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+	mu      sync.Mutex
+	hooks   []hook
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func New() *Lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Lifecycle{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go inicia un proceso de fondo con un contexto que se cancela al apagar. Shutdown espera
+// a que fn termine.
+func (l *Lifecycle) Go(name string, fn func(ctx context.Context)) {
+	l.workers.Add(1)
+	go func() {
+		defer l.workers.Done()
+		fn(l.ctx)
+		logger.Log.Info("🛑 Proceso de fondo detenido", zap.String("worker", name))
+	}()
+}
+
+// OnShutdown registra un paso de cierre. Los pasos corren en orden inverso al de registro,
+// después de que terminaron los procesos de fondo.
+func (l *Lifecycle) OnShutdown(name string, fn func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook{name: name, fn: fn})
+}
+
+// Shutdown cancela los procesos de fondo, espera a que terminen mientras ctx lo permita y
+// ejecuta los pasos de cierre. Un paso que falla no impide los siguientes; devuelve todos
+// los errores juntos.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	l.cancel()
+	done := make(chan struct{})
+	go func() {
+		l.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("background workers did not stop in time: %w", ctx.Err()))
+	}
+
+	l.mu.Lock()
+	hooks := l.hooks
+	l.hooks = nil
+	l.mu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+		}
+	}
+	return stderrors.Join(errs...)
+}
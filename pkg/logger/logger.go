@@ -1,15 +1,16 @@
 package logger
 
 import (
-	"os"
+	stderrors "errors"
+	"syscall"
+
 	"go.uber.org/zap"
 )
 
 var Log *zap.Logger
 
-func Init() {
-	env := os.Getenv("APP_ENV")
-
+// Init crea el logger: en el entorno "dev" uno legible para desarrollo, en el resto uno JSON.
+func Init(env string) {
 	var err error
 	if env == "dev" {
 		Log, err = zap.NewDevelopment()
@@ -21,3 +22,15 @@ func Init() {
 		panic("💥 No se pudo iniciar el logger: " + err.Error())
 	}
 }
+
+// Sync vacía los logs pendientes. Ignora el error que devuelven stdout y stderr cuando son
+// una terminal o un pipe, que no se pueden sincronizar.
+func Sync() error {
+	if Log == nil {
+		return nil
+	}
+	if err := Log.Sync(); err != nil && !stderrors.Is(err, syscall.EINVAL) && !stderrors.Is(err, syscall.ENOTTY) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Mailer envía emails transaccionales (invitaciones, confirmaciones, avisos).
+// Permite cambiar el proveedor sin tocar los servicios que lo usan.
+type Mailer interface {
+	Send(SendMailFuncParams) error
+}
+
+type logMailer struct{}
+
+// NewLogMailer devuelve un Mailer que solo loguea los mensajes.
+// Se usa en desarrollo y mientras no haya un proveedor configurado.
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(opts SendMailFuncParams) error {
+	logger.Log.Info("📧 email enviado (log mailer)",
+		zap.String("to", opts.To),
+		zap.String("subject", opts.Subject),
+		zap.String("body", opts.Body),
+	)
+	return nil
+}
+
+// /-------------structs------------------///
+type SendMailFuncParams struct {
+	Ctx     context.Context
+	To      string
+	Subject string
+	Body    string
+}
@@ -12,11 +12,18 @@ type BaseModel struct {
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 	IsActive  bool           `gorm:"default:true" json:"isActive"`
+	// Version se incrementa en cada escritura y se expone como ETag
+	// para el control de concurrencia optimista.
+	Version   int64          `gorm:"not null;default:1" json:"version"`
 }
 
 func (b *BaseModel) BeforeCreate(tx *gorm.DB) (err error) {
 	if b.ID == uuid.Nil {
 		b.ID = uuid.New()
 	}
+	if b.Version == 0 {
+		b.Version = 1
+	}
 	return
-}
\ No newline at end of file
+}
+
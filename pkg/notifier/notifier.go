@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/aragornz325/piloto-api/pkg/mailer"
+	"github.com/google/uuid"
+)
+
+// Notifier avisa a un usuario (vencimientos, cambios de estado) por el canal que
+// corresponda. Permite sumar push o SMS sin tocar los servicios que lo usan.
+type Notifier interface {
+	Notify(NotifyFuncParams) error
+}
+
+type mailNotifier struct {
+	Mailer mailer.Mailer
+}
+
+// NewMailNotifier devuelve un Notifier que avisa por email.
+func NewMailNotifier(mailer mailer.Mailer) Notifier {
+	return &mailNotifier{
+		Mailer: mailer,
+	}
+}
+
+func (n *mailNotifier) Notify(opts NotifyFuncParams) error {
+	return n.Mailer.Send(mailer.SendMailFuncParams{
+		Ctx:     opts.Ctx,
+		To:      opts.Email,
+		Subject: opts.Subject,
+		Body:    opts.Body,
+	})
+}
+
+// /-------------structs------------------///
+type NotifyFuncParams struct {
+	Ctx     context.Context
+	UserId  uuid.UUID
+	Email   string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,169 @@
+package phone
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Package phone normaliza números de teléfono al formato E.164 (+<código de país><número>)
+// a partir de lo que escribe el usuario, usando el país del perfil para los números nacionales.
+
+// country describe las reglas de numeración de un país que necesitamos para normalizar.
+type country struct {
+	callingCode string
+	// trunkPrefix es el prefijo que se marca dentro del país y se descarta en E.164 ("0" en AR).
+	trunkPrefix string
+	// minLength y maxLength acotan la cantidad de dígitos del número nacional (sin código de país ni prefijo).
+	minLength int
+	maxLength int
+}
+
+// countries está indexado por código ISO 3166-1 alpha-2, igual que Profile.Country.
+var countries = map[string]country{
+	"AR": {callingCode: "54", trunkPrefix: "0", minLength: 10, maxLength: 11},
+	"BO": {callingCode: "591", trunkPrefix: "0", minLength: 8, maxLength: 8},
+	"BR": {callingCode: "55", trunkPrefix: "0", minLength: 10, maxLength: 11},
+	"CL": {callingCode: "56", minLength: 9, maxLength: 9},
+	"CO": {callingCode: "57", minLength: 10, maxLength: 10},
+	"CR": {callingCode: "506", minLength: 8, maxLength: 8},
+	"CU": {callingCode: "53", trunkPrefix: "0", minLength: 8, maxLength: 8},
+	"DE": {callingCode: "49", trunkPrefix: "0", minLength: 6, maxLength: 11},
+	"DO": {callingCode: "1", minLength: 10, maxLength: 10},
+	"EC": {callingCode: "593", trunkPrefix: "0", minLength: 8, maxLength: 9},
+	"ES": {callingCode: "34", minLength: 9, maxLength: 9},
+	"FR": {callingCode: "33", trunkPrefix: "0", minLength: 9, maxLength: 9},
+	"GB": {callingCode: "44", trunkPrefix: "0", minLength: 9, maxLength: 10},
+	"GT": {callingCode: "502", minLength: 8, maxLength: 8},
+	"HN": {callingCode: "504", minLength: 8, maxLength: 8},
+	"IT": {callingCode: "39", minLength: 6, maxLength: 11},
+	"MX": {callingCode: "52", minLength: 10, maxLength: 10},
+	"NI": {callingCode: "505", minLength: 8, maxLength: 8},
+	"PA": {callingCode: "507", minLength: 7, maxLength: 8},
+	"PE": {callingCode: "51", trunkPrefix: "0", minLength: 8, maxLength: 9},
+	"PT": {callingCode: "351", minLength: 9, maxLength: 9},
+	"PY": {callingCode: "595", trunkPrefix: "0", minLength: 9, maxLength: 9},
+	"SV": {callingCode: "503", minLength: 8, maxLength: 8},
+	"US": {callingCode: "1", trunkPrefix: "1", minLength: 10, maxLength: 10},
+	"UY": {callingCode: "598", trunkPrefix: "0", minLength: 8, maxLength: 8},
+	"VE": {callingCode: "58", trunkPrefix: "0", minLength: 10, maxLength: 10},
+}
+
+const (
+	// E.164 admite como máximo 15 dígitos incluyendo el código de país.
+	maxE164Digits = 15
+	minE164Digits = 8
+)
+
+// Normalize convierte un número escrito por el usuario a E.164. Los números que empiezan
+// con "+" o con el prefijo internacional "00" se toman como internacionales; el resto se
+// interpreta como número nacional de defaultCountry (código ISO alpha-2).
+// Se ignoran espacios, guiones, puntos y paréntesis.
+func Normalize(raw string, defaultCountry string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("phone number is empty")
+	}
+
+	international := strings.HasPrefix(raw, "+")
+	var digits strings.Builder
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("phone number contains invalid character %q", r)
+		}
+	}
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		international = true
+		number = number[2:]
+	}
+
+	if international {
+		if code, rules, ok := countryForNumber(number); ok {
+			national := strings.TrimPrefix(number, rules.callingCode)
+			if code == "AR" {
+				national = argentineMobile(national)
+			}
+			return build(rules, national)
+		}
+		if len(number) < minE164Digits || len(number) > maxE164Digits {
+			return "", fmt.Errorf("phone number must have between %d and %d digits", minE164Digits, maxE164Digits)
+		}
+		return "+" + number, nil
+	}
+
+	rules, ok := countries[strings.ToUpper(strings.TrimSpace(defaultCountry))]
+	if !ok {
+		return "", fmt.Errorf("phone number must include the country code (e.g. +54...) when the profile has no supported country")
+	}
+	// Los números nacionales significativos nunca empiezan con el prefijo troncal
+	national := number
+	if rules.trunkPrefix != "" {
+		national = strings.TrimPrefix(national, rules.trunkPrefix)
+	}
+	if strings.EqualFold(defaultCountry, "AR") {
+		national = argentineMobile(national)
+	}
+	return build(rules, national)
+}
+
+func build(rules country, national string) (string, error) {
+	if len(national) < rules.minLength || len(national) > rules.maxLength {
+		return "", fmt.Errorf("phone number must have between %d and %d digits after the country code +%s",
+			rules.minLength, rules.maxLength, rules.callingCode)
+	}
+	return "+" + rules.callingCode + national, nil
+}
+
+// countryForNumber busca el país cuyo código de llamada es prefijo del número internacional.
+// Prueba primero los códigos más largos para no confundir, por ejemplo, +598 con +59x.
+func countryForNumber(number string) (string, country, bool) {
+	for length := 3; length >= 1; length-- {
+		if len(number) <= length {
+			continue
+		}
+		prefix := number[:length]
+		var match string
+		for code, rules := range countries {
+			if rules.callingCode != prefix {
+				continue
+			}
+			// +1 es compartido (US, DO...): las reglas son las mismas, se toma el primero en orden
+			if match == "" || code < match {
+				match = code
+			}
+		}
+		if match != "" {
+			return match, countries[match], true
+		}
+	}
+	return "", country{}, false
+}
+
+// argentineMobile convierte un celular argentino en formato nacional ("11 15 1234-5678",
+// área + 15 + abonado) al formato internacional, que reemplaza el "15" por un "9" delante
+// del código de área. Los números ya internacionales (9 + área + abonado) no cambian.
+func argentineMobile(national string) string {
+	if len(national) != 12 {
+		return national
+	}
+	// Los códigos de área argentinos tienen de 2 a 4 dígitos y el número total (área + abonado) 10
+	for areaLength := 2; areaLength <= 4; areaLength++ {
+		if national[areaLength:areaLength+2] == "15" {
+			return "9" + national[:areaLength] + national[areaLength+2:]
+		}
+	}
+	return national
+}
+
+// Mask oculta los dígitos centrales de un número E.164 para mostrarlo en logs o respuestas.
+func Mask(e164 string) string {
+	if len(e164) <= 6 {
+		return e164
+	}
+	return e164[:4] + strings.Repeat("*", len(e164)-6) + e164[len(e164)-2:]
+}
@@ -0,0 +1,45 @@
+package presenter
+
+import (
+	"context"
+
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/google/uuid"
+)
+
+// Audience indica para quién se serializa una entidad; cada presenter decide
+// qué campos expone a cada audiencia.
+type Audience string
+
+const (
+	AudiencePublic        Audience = "public"
+	AudienceAuthenticated Audience = "authenticated"
+	AudienceSelf          Audience = "self"
+	AudienceAdmin         Audience = "admin"
+)
+
+// AudienceFor resuelve la audiencia del usuario autenticado frente a una entidad cuyo
+// dueño es opts.OwnerId: admin si tiene alguno de opts.AdminRoles, self si es el dueño,
+// authenticated para cualquier otro usuario logueado y public para las requests anónimas.
+func AudienceFor(opts AudienceForFuncParams) Audience {
+	principal, ok := requestctx.PrincipalFrom(opts.Ctx)
+	if !ok {
+		return AudiencePublic
+	}
+	for _, role := range opts.AdminRoles {
+		if principal.Role == role {
+			return AudienceAdmin
+		}
+	}
+	if principal.UserId == opts.OwnerId {
+		return AudienceSelf
+	}
+	return AudienceAuthenticated
+}
+
+// /-------------structs------------------///
+type AudienceForFuncParams struct {
+	Ctx        context.Context
+	OwnerId    uuid.UUID
+	AdminRoles []string
+}
@@ -0,0 +1,51 @@
+package presenter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aragornz325/piloto-api/pkg/requestctx"
+	"github.com/google/uuid"
+)
+
+func TestAudienceFor(t *testing.T) {
+	ownerId := uuid.New()
+	otherId := uuid.New()
+
+	tests := []struct {
+		name      string
+		principal *requestctx.Principal
+		want      Audience
+	}{
+		{name: "anonymous", want: AudiencePublic},
+		{name: "other user", principal: &requestctx.Principal{UserId: otherId, Role: "user"}, want: AudienceAuthenticated},
+		{name: "owner", principal: &requestctx.Principal{UserId: ownerId, Role: "user"}, want: AudienceSelf},
+		{name: "admin", principal: &requestctx.Principal{UserId: otherId, Role: "admin"}, want: AudienceAdmin},
+		{name: "admin owner", principal: &requestctx.Principal{UserId: ownerId, Role: "admin"}, want: AudienceAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = requestctx.WithPrincipal(ctx, tt.principal)
+			}
+			got := AudienceFor(AudienceForFuncParams{
+				Ctx:        ctx,
+				OwnerId:    ownerId,
+				AdminRoles: []string{"admin"},
+			})
+			if got != tt.want {
+				t.Errorf("AudienceFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudienceForWithoutAdminRoles(t *testing.T) {
+	// Sin roles de admin configurados un admin es un usuario más
+	ctx := requestctx.WithPrincipal(context.Background(), &requestctx.Principal{UserId: uuid.New(), Role: "admin"})
+	got := AudienceFor(AudienceForFuncParams{Ctx: ctx, OwnerId: uuid.New()})
+	if got != AudienceAuthenticated {
+		t.Errorf("AudienceFor() = %q, want %q", got, AudienceAuthenticated)
+	}
+}
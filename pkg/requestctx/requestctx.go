@@ -0,0 +1,55 @@
+package requestctx
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Package requestctx guarda en el context.Context los datos propios de cada request
+// (request ID y usuario autenticado) para que servicios y hooks de GORM puedan leerlos.
+
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id"
+	principalKey contextKey = "principal"
+)
+
+// Principal es el usuario autenticado que ejecuta la request.
+type Principal struct {
+	UserId uuid.UUID
+	Email  string
+	Role   string
+	// ExpiresAt es el vencimiento del token con el que se autenticó; cero si no vence.
+	ExpiresAt time.Time
+}
+
+// WithRequestID devuelve un context hijo con el request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID devuelve el request ID guardado en ctx, o "" si no hay.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithPrincipal devuelve un context hijo con el usuario autenticado.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFrom devuelve el usuario autenticado guardado en ctx, si existe.
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	principal, ok := ctx.Value(principalKey).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,37 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/aragornz325/piloto-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// SmsSender envía mensajes de texto (códigos de verificación, avisos).
+// Permite cambiar el proveedor sin tocar los servicios que lo usan.
+type SmsSender interface {
+	Send(SendSmsFuncParams) error
+}
+
+type logSender struct{}
+
+// NewLogSender devuelve un SmsSender que solo loguea los mensajes.
+// Se usa en desarrollo y mientras no haya un proveedor configurado.
+func NewLogSender() SmsSender {
+	return &logSender{}
+}
+
+func (s *logSender) Send(opts SendSmsFuncParams) error {
+	logger.Log.Info("📱 SMS enviado (log sender)",
+		zap.String("to", opts.To),
+		zap.String("body", opts.Body),
+	)
+	return nil
+}
+
+// /-------------structs------------------///
+type SendSmsFuncParams struct {
+	Ctx  context.Context
+	To   string
+	Body string
+}
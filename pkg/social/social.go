@@ -0,0 +1,197 @@
+package social
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Package social valida y canonicaliza los links a redes sociales y sitios web del perfil.
+// Cada red acepta un handle ("@usuario", "usuario") o una URL de su dominio y devuelve el
+// handle canónico y la URL canónica que se guardan.
+
+// Link es el resultado de canonicalizar un link: el handle (vacío para sitios web) y la URL.
+type Link struct {
+	Handle string
+	URL    string
+}
+
+// Network describe cómo reconocer y construir los links de una red social.
+type Network struct {
+	Name    string
+	domains []string
+	handle  *regexp.Regexp
+	// reserved son rutas del sitio que no son perfiles (login, explore...).
+	reserved map[string]bool
+	build    func(handle string) string
+}
+
+var (
+	Instagram = Network{
+		Name:     "instagram",
+		domains:  []string{"instagram.com", "instagr.am"},
+		handle:   regexp.MustCompile(`^[a-z0-9._]{1,30}$`),
+		reserved: set("accounts", "explore", "p", "reel", "reels", "stories", "direct", "about", "developer", "legal"),
+		build:    func(handle string) string { return "https://www.instagram.com/" + handle + "/" },
+	}
+	Facebook = Network{
+		Name:     "facebook",
+		domains:  []string{"facebook.com", "fb.com", "fb.me"},
+		handle:   regexp.MustCompile(`^([a-z0-9.]{5,50}|[0-9]{5,20})$`),
+		reserved: set("login", "home.php", "groups", "pages", "events", "watch", "marketplace", "sharer", "share", "help", "policies"),
+		build: func(handle string) string {
+			if isNumeric(handle) {
+				return "https://www.facebook.com/profile.php?id=" + handle
+			}
+			return "https://www.facebook.com/" + handle
+		},
+	}
+	Twitter = Network{
+		Name:     "twitter",
+		domains:  []string{"twitter.com", "x.com"},
+		handle:   regexp.MustCompile(`^[a-z0-9_]{1,15}$`),
+		reserved: set("home", "explore", "search", "i", "intent", "share", "login", "signup", "settings", "messages", "notifications", "hashtag"),
+		build:    func(handle string) string { return "https://x.com/" + handle },
+	}
+)
+
+// Normalize acepta un handle o una URL de la red y devuelve el handle y la URL canónicos.
+// Los handles se guardan en minúsculas porque las tres redes no distinguen mayúsculas.
+func (n Network) Normalize(input string) (Link, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return Link{}, fmt.Errorf("is empty")
+	}
+
+	var handle string
+	if n.looksLikeURL(input) {
+		parsed, err := parseURL(input)
+		if err != nil {
+			return Link{}, err
+		}
+		if !n.ownsHost(parsed.Hostname()) {
+			return Link{}, fmt.Errorf("must be a %s handle or link, got a link to %s", n.Name, parsed.Hostname())
+		}
+		handle, err = n.handleFromURL(parsed)
+		if err != nil {
+			return Link{}, err
+		}
+	} else {
+		handle = strings.TrimPrefix(input, "@")
+	}
+
+	handle = strings.ToLower(handle)
+	if !n.handle.MatchString(handle) || n.reserved[handle] {
+		return Link{}, fmt.Errorf("is not a valid %s username", n.Name)
+	}
+	return Link{Handle: handle, URL: n.build(handle)}, nil
+}
+
+func (n Network) handleFromURL(parsed *url.URL) (string, error) {
+	segments := strings.FieldsFunc(parsed.Path, func(r rune) bool { return r == '/' })
+	// facebook.com/profile.php?id=123 identifica el perfil por ID numérico
+	if n.Name == Facebook.Name && len(segments) == 1 && segments[0] == "profile.php" {
+		if id := parsed.Query().Get("id"); id != "" {
+			return id, nil
+		}
+	}
+	if len(segments) != 1 {
+		return "", fmt.Errorf("must link to a %s profile", n.Name)
+	}
+	return strings.TrimPrefix(segments[0], "@"), nil
+}
+
+func (n Network) ownsHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range n.domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// socialDomains son los dominios que no se aceptan como sitio web propio.
+var socialDomains = []Network{Instagram, Facebook, Twitter}
+
+// NormalizeWebsite valida una URL de sitio web y la devuelve canónica: https por defecto,
+// host en minúsculas, sin credenciales ni fragmento. Rechaza links a redes sociales, que
+// tienen su propio campo.
+func NormalizeWebsite(input string) (Link, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return Link{}, fmt.Errorf("is empty")
+	}
+	parsed, err := parseURL(input)
+	if err != nil {
+		return Link{}, err
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if !strings.Contains(host, ".") || strings.HasPrefix(host, ".") || strings.HasSuffix(host, ".") {
+		return Link{}, fmt.Errorf("must be a valid website address")
+	}
+	for _, network := range socialDomains {
+		if network.ownsHost(host) {
+			return Link{}, fmt.Errorf("links to %s, use the %s field instead", network.Name, network.Name)
+		}
+	}
+
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.User = nil
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	if parsed.Path == "/" {
+		parsed.Path = ""
+		parsed.RawPath = ""
+	}
+	return Link{URL: parsed.String()}, nil
+}
+
+// parseURL interpreta la entrada como URL http(s), agregando https:// si no trae esquema.
+func parseURL(input string) (*url.URL, error) {
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+	parsed, err := url.Parse(input)
+	if err != nil || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("is not a valid URL")
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("must be an http or https URL")
+	}
+	return parsed, nil
+}
+
+// looksLikeURL distingue una URL ("instagram.com/user", "https://...") de un handle, que
+// también puede tener puntos. Un dominio de cualquier red social se trata como URL para
+// poder rechazarlo si no es el de esta red.
+func (n Network) looksLikeURL(input string) bool {
+	if strings.Contains(input, "://") || strings.Contains(input, "/") {
+		return true
+	}
+	for _, network := range socialDomains {
+		if network.ownsHost(input) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumeric(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+func set(values ...string) map[string]bool {
+	result := make(map[string]bool, len(values))
+	for _, value := range values {
+		result[value] = true
+	}
+	return result
+}
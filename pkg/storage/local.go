@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localStorage struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStorage guarda los archivos en un directorio del filesystem. El router los sirve
+// como estáticos bajo la ruta de PublicURL (por defecto /media).
+func NewLocalStorage(config LocalStorageConfig) (BlobStorage, error) {
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating storage dir: %w", err)
+	}
+	return &localStorage{
+		dir:       config.Dir,
+		publicURL: strings.TrimRight(config.PublicURL, "/"),
+	}, nil
+}
+
+func (s *localStorage) Put(opts PutObjectFuncParams) (string, error) {
+	path, err := s.path(opts.Key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("error creating storage dir: %w", err)
+	}
+	// Escribimos a un temporal y renombramos para no servir archivos a medio escribir
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, opts.Data, 0o644); err != nil {
+		return "", fmt.Errorf("error writing object: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("error writing object: %w", err)
+	}
+	return s.URL(opts.Key), nil
+}
+
+func (s *localStorage) Get(opts GetObjectFuncParams) ([]byte, error) {
+	path, err := s.path(opts.Key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("error reading object: %w", err)
+	}
+	return data, nil
+}
+
+func (s *localStorage) Delete(opts DeleteObjectFuncParams) error {
+	path, err := s.path(opts.Key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting object: %w", err)
+	}
+	return nil
+}
+
+func (s *localStorage) URL(key string) string {
+	return s.publicURL + "/" + strings.TrimLeft(key, "/")
+}
+
+// Dir devuelve el directorio raíz, para que el router lo sirva como estático.
+func (s *localStorage) Dir() string {
+	return s.dir
+}
+
+// RoutePrefix devuelve el path de PublicURL, que es donde el router monta los estáticos.
+func (s *localStorage) RoutePrefix() string {
+	parsed, err := url.Parse(s.publicURL)
+	if err != nil || parsed.Path == "" {
+		return "/media"
+	}
+	return parsed.Path
+}
+
+// Check chequea que se pueda escribir en el directorio.
+func (s *localStorage) Check(ctx context.Context) error {
+	file, err := os.CreateTemp(s.dir, ".healthcheck-*")
+	if err != nil {
+		return fmt.Errorf("storage dir is not writable: %w", err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// path resuelve la ruta del objeto evitando que una key con ".." salga del directorio.
+func (s *localStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// /-------------structs------------------///
+type LocalStorageConfig struct {
+	Dir       string
+	PublicURL string
+}
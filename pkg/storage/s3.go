@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// s3Storage habla con cualquier servicio compatible con S3 (AWS, MinIO, R2...) usando
+// URLs path-style y firma AWS Signature Version 4, sin depender del SDK.
+type s3Storage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+}
+
+func NewS3Storage(config S3StorageConfig) (BlobStorage, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("s3 storage requires endpoint, bucket, access key and secret key")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+	publicURL := strings.TrimRight(config.PublicURL, "/")
+	if publicURL == "" {
+		publicURL = endpoint.String() + "/" + config.Bucket
+	}
+	return &s3Storage{
+		endpoint:  endpoint,
+		region:    config.Region,
+		bucket:    config.Bucket,
+		accessKey: config.AccessKey,
+		secretKey: config.SecretKey,
+		publicURL: publicURL,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *s3Storage) Put(opts PutObjectFuncParams) (string, error) {
+	req, err := s.newRequest(opts.Ctx, http.MethodPut, opts.Key, opts.Data)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", opts.ContentType)
+	if err := s.do(req, opts.Data); err != nil {
+		return "", err
+	}
+	return s.URL(opts.Key), nil
+}
+
+func (s *s3Storage) Get(opts GetObjectFuncParams) ([]byte, error) {
+	req, err := s.newRequest(opts.Ctx, http.MethodGet, opts.Key, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil, time.Now().UTC())
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 GET request failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if res.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("s3 GET request failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading s3 object: %w", err)
+	}
+	return data, nil
+}
+
+func (s *s3Storage) Delete(opts DeleteObjectFuncParams) error {
+	req, err := s.newRequest(opts.Ctx, http.MethodDelete, opts.Key, nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, nil)
+}
+
+func (s *s3Storage) URL(key string) string {
+	return s.publicURL + "/" + escapePath(strings.TrimLeft(key, "/"))
+}
+
+// Check chequea que el bucket exista y las credenciales sirvan (HEAD del bucket).
+func (s *s3Storage) Check(ctx context.Context) error {
+	req, err := s.newRequest(ctx, http.MethodHead, "", nil)
+	if err != nil {
+		return err
+	}
+	return s.do(req, nil)
+}
+
+func (s *s3Storage) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	objectURL := *s.endpoint
+	objectURL.Path = strings.TrimRight(objectURL.Path, "/") + "/" + s.bucket + "/" + strings.TrimLeft(key, "/")
+	objectURL.RawPath = strings.TrimRight(s.endpoint.EscapedPath(), "/") + "/" + escapePath(s.bucket+"/"+strings.TrimLeft(key, "/"))
+	return http.NewRequestWithContext(ctx, method, objectURL.String(), bytes.NewReader(body))
+}
+
+func (s *s3Storage) do(req *http.Request, body []byte) error {
+	s.sign(req, body, time.Now().UTC())
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 %s request failed: %w", req.Method, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("s3 %s request failed with status %d: %s", req.Method, res.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// sign agrega los headers de AWS Signature Version 4 a la request.
+func (s *s3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaders = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, strings.Join(signedHeaders, ";"), signature,
+	))
+}
+
+// escapePath codifica cada segmento de la key según las reglas de S3 (RFC 3986).
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// /-------------structs------------------///
+type S3StorageConfig struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicURL es la base desde la que se sirven los objetos (CDN o bucket público).
+	// Por defecto se usa <endpoint>/<bucket>.
+	PublicURL string
+}
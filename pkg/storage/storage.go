@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/aragornz325/piloto-api/pkg/config"
+)
+
+// BlobStorage guarda archivos subidos por los usuarios (avatares, documentos) y devuelve
+// la URL pública desde la que se sirven. Las implementaciones son intercambiables.
+type BlobStorage interface {
+	Put(PutObjectFuncParams) (string, error)
+	Get(GetObjectFuncParams) ([]byte, error)
+	Delete(DeleteObjectFuncParams) error
+	URL(key string) string
+}
+
+// ErrNotFound lo devuelve Get cuando el objeto no existe.
+var ErrNotFound = stderrors.New("object not found")
+
+// StaticServer lo implementan los storages cuyos archivos debe servir la propia API.
+type StaticServer interface {
+	Dir() string
+	RoutePrefix() string
+}
+
+// New crea el BlobStorage del driver configurado ("local" o "s3").
+func New(cfg config.StorageConfig) (BlobStorage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(LocalStorageConfig{
+			Dir:       cfg.LocalDir,
+			PublicURL: cfg.PublicURL,
+		})
+	case "s3":
+		return NewS3Storage(S3StorageConfig{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey.Reveal(),
+			SecretKey: cfg.S3.SecretKey.Reveal(),
+			PublicURL: cfg.S3.PublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// NewPrivate crea el BlobStorage de los archivos que no se sirven públicamente (documentos
+// de conductores): un directorio que el router no expone o un bucket sin acceso público.
+// Las URLs que devuelve no sirven para descargar; los archivos se leen con Get.
+func NewPrivate(cfg config.StorageConfig) (BlobStorage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(LocalStorageConfig{
+			Dir: cfg.PrivateDir,
+		})
+	case "s3":
+		return NewS3Storage(S3StorageConfig{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.PrivateBucket,
+			AccessKey: cfg.S3.AccessKey.Reveal(),
+			SecretKey: cfg.S3.SecretKey.Reveal(),
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// /-------------structs------------------///
+type PutObjectFuncParams struct {
+	Ctx         context.Context
+	Key         string
+	ContentType string
+	Data        []byte
+}
+
+type GetObjectFuncParams struct {
+	Ctx context.Context
+	Key string
+}
+
+type DeleteObjectFuncParams struct {
+	Ctx context.Context
+	Key string
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aragornz325/piloto-api/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// BuildETag arma el valor del header ETag a partir de la versión de la entidad.
+func BuildETag(version int64) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// SetETag escribe el header ETag en la respuesta.
+func SetETag(c *gin.Context, version int64) {
+	c.Header("ETag", BuildETag(version))
+}
+
+// ParseIfMatch extrae la versión esperada desde el valor de un header If-Match.
+// Acepta etags fuertes ("3") y débiles (W/"3").
+func ParseIfMatch(header string) (int64, error) {
+	value := strings.TrimSpace(header)
+	value = strings.TrimPrefix(value, "W/")
+	value = strings.Trim(value, "\"")
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid If-Match header: %q", header)
+	}
+	return version, nil
+}
+
+// RequireIfMatch obtiene la versión esperada desde el header If-Match de la request.
+// Devuelve un 428 si el header no fue enviado y un 412 si no se puede interpretar.
+func RequireIfMatch(c *gin.Context) (int64, error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return 0, errors.NewPreconditionRequired(errors.SimpleErrorFuncOptions{
+			Message: "If-Match header is required",
+		})
+	}
+	version, err := ParseIfMatch(header)
+	if err != nil {
+		return 0, errors.NewPreconditionFailed(errors.SimpleErrorFuncOptions{
+			Message: err.Error(),
+		})
+	}
+	return version, nil
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// QueryOptions representa los parámetros ?fields= e ?include= de un endpoint de lectura
+// ya validados contra las listas permitidas del recurso.
+type QueryOptions struct {
+	// Fields son los nombres JSON pedidos por el cliente (vacío = todos).
+	Fields []string
+	// Columns son las columnas de la DB correspondientes a Fields.
+	Columns []string
+	// Includes son los nombres JSON de las relaciones a expandir.
+	Includes []string
+	// Preloads son los nombres de las asociaciones GORM correspondientes a Includes.
+	Preloads []string
+}
+
+// ParseQueryOptions lee ?fields=a,b y ?include=x de la request y los valida contra
+// las listas permitidas. Devuelve un error si el cliente pide un campo o relación desconocidos.
+func ParseQueryOptions(c *gin.Context, opts ParseQueryOptionsFuncParams) (*QueryOptions, error) {
+	query := &QueryOptions{}
+
+	for _, field := range splitQueryList(c.Query("fields")) {
+		column, ok := opts.AllowedFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		query.Fields = append(query.Fields, field)
+		query.Columns = append(query.Columns, column)
+	}
+
+	for _, include := range splitQueryList(c.Query("include")) {
+		association, ok := opts.AllowedIncludes[include]
+		if !ok {
+			return nil, fmt.Errorf("unknown include %q", include)
+		}
+		query.Includes = append(query.Includes, include)
+		query.Preloads = append(query.Preloads, association)
+	}
+
+	return query, nil
+}
+
+// Apply agrega al query el SELECT de las columnas pedidas y los Preload de las relaciones.
+// Las columnas en opts.RequiredColumns (PK, versión, FKs de las relaciones) se seleccionan siempre.
+// Los Preload de GORM se resuelven con un único IN por relación, sin N+1.
+func (q *QueryOptions) Apply(tx *gorm.DB, requiredColumns ...string) *gorm.DB {
+	if q == nil {
+		return tx
+	}
+	if len(q.Columns) > 0 {
+		columns := append([]string{}, requiredColumns...)
+		for _, column := range q.Columns {
+			if !containsString(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+		tx = tx.Select(columns)
+	}
+	for _, preload := range q.Preloads {
+		tx = tx.Preload(preload)
+	}
+	return tx
+}
+
+// Project reduce la representación JSON de value (struct o slice de structs) a los campos
+// pedidos, el id y las relaciones incluidas. Si no se pidieron campos devuelve value sin cambios.
+func (q *QueryOptions) Project(value interface{}) (interface{}, error) {
+	if q == nil || len(q.Fields) == 0 {
+		return value, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := append([]string{"id"}, q.Fields...)
+	keep = append(keep, q.Includes...)
+
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i] = pickKeys(items[i], keep)
+		}
+		return items, nil
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, err
+	}
+	return pickKeys(item, keep), nil
+}
+
+func pickKeys(item map[string]json.RawMessage, keys []string) map[string]json.RawMessage {
+	picked := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		if v, ok := item[key]; ok {
+			picked[key] = v
+		}
+	}
+	return picked
+}
+
+func splitQueryList(raw string) []string {
+	var values []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" && !containsString(values, part) {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// /-------------structs------------------///
+type ParseQueryOptionsFuncParams struct {
+	// AllowedFields mapea nombre JSON -> columna de la DB.
+	AllowedFields map[string]string
+	// AllowedIncludes mapea nombre JSON -> asociación GORM.
+	AllowedIncludes map[string]string
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	stderrors "errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// BindingFieldErrors convierte los errores del validator (binding o validate.Struct) en
+// mensajes por campo, usando como clave el nombre JSON del campo en dto.
+// Devuelve nil si err no es un error de validación.
+func BindingFieldErrors(err error, dto interface{}) map[string]string {
+	var validationErrors validator.ValidationErrors
+	if !stderrors.As(err, &validationErrors) {
+		return nil
+	}
+	dtoType := reflect.TypeOf(dto)
+	for dtoType != nil && dtoType.Kind() == reflect.Ptr {
+		dtoType = dtoType.Elem()
+	}
+
+	fields := make(map[string]string, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		name := fieldErr.Field()
+		if dtoType != nil && dtoType.Kind() == reflect.Struct {
+			if field, ok := dtoType.FieldByName(fieldErr.StructField()); ok {
+				if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+					name = tag
+				}
+			}
+		}
+		fields[name] = validationMessage(fieldErr)
+	}
+	return fields
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "len":
+		return "must have exactly " + fieldErr.Param() + " characters"
+	case "min":
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	case "oneof":
+		return "must be one of: " + fieldErr.Param()
+	case "numeric":
+		return "must be numeric"
+	default:
+		return "failed the " + fieldErr.Tag() + " validation"
+	}
+}